@@ -0,0 +1,52 @@
+package analytics
+
+// Beta is the sensitivity of returns to benchmark returns, Cov(r, b) / Var(b);
+// ok is false when the benchmark doesn't move or there are too few observations
+func Beta(returns, benchmark []float64) (float64, bool) {
+	if len(returns) != len(benchmark) || len(returns) < 3 {
+		return 0, false
+	}
+	sd := StdDev(benchmark)
+	if sd == 0 {
+		return 0, false
+	}
+	return Covariance(returns, benchmark) / (sd * sd), true
+}
+
+// Active returns the per-period difference between returns and benchmark
+func Active(returns, benchmark []float64) []float64 {
+	if len(returns) != len(benchmark) {
+		return nil
+	}
+	out := make([]float64, len(returns))
+	for i := range returns {
+		out[i] = returns[i] - benchmark[i]
+	}
+	return out
+}
+
+// TrackingError is the annualized standard deviation of active returns
+func TrackingError(returns, benchmark []float64, periodsPerYear float64) float64 {
+	return AnnualizedVolatility(Active(returns, benchmark), periodsPerYear)
+}
+
+// Capture compares the mean return in periods where the benchmark rose (up) or
+// fell (!up) with the benchmark's mean in those periods, as a ratio (1 = 100%).
+// ok is false when the benchmark had no such periods.
+func Capture(returns, benchmark []float64, up bool) (float64, bool) {
+	var rs, bs []float64
+	for i := range benchmark {
+		if i >= len(returns) {
+			break
+		}
+		if (up && benchmark[i] > 0) || (!up && benchmark[i] < 0) {
+			rs = append(rs, returns[i])
+			bs = append(bs, benchmark[i])
+		}
+	}
+	mb := Mean(bs)
+	if len(bs) == 0 || mb == 0 {
+		return 0, false
+	}
+	return Mean(rs) / mb, true
+}
@@ -0,0 +1,137 @@
+package analytics
+
+import (
+	"math"
+	"time"
+)
+
+// FlowReturns converts a value series with external flows into period returns.
+// flows[i] is money added (+) or removed (-) during period i at that period's
+// prices and is already included in values[i], so the return for period i is
+// (values[i] - flows[i]) / values[i-1] - 1. Periods that open with no capital
+// return 0. The result is one shorter than values.
+func FlowReturns(values, flows []float64) []float64 {
+	if len(values) < 2 || len(flows) != len(values) {
+		return nil
+	}
+	out := make([]float64, 0, len(values)-1)
+	for i := 1; i < len(values); i++ {
+		if values[i-1] <= 1e-9 {
+			out = append(out, 0)
+			continue
+		}
+		out = append(out, (values[i]-flows[i])/values[i-1]-1)
+	}
+	return out
+}
+
+// Compound links period returns into a cumulative return
+func Compound(returns []float64) float64 {
+	growth := 1.0
+	for _, r := range returns {
+		growth *= 1 + r
+	}
+	return growth - 1
+}
+
+// TWR is the time-weighted return of a value series with external flows (see
+// FlowReturns): the growth of one unit invested throughout, unaffected by the
+// timing or size of deposits and withdrawals
+func TWR(values, flows []float64) float64 {
+	return Compound(FlowReturns(values, flows))
+}
+
+// Annualize converts a cumulative return over days calendar days into a yearly rate
+func Annualize(total, days float64) float64 {
+	if days <= 0 || total <= -1 {
+		return total
+	}
+	return math.Pow(1+total, 365.25/days) - 1
+}
+
+// CashFlow is a dated amount from the investor's side: negative when money goes
+// in, positive when it comes out (including the final value)
+type CashFlow struct {
+	Date   string
+	Amount float64
+}
+
+// XIRR returns the annual rate that discounts flows to a net present value of
+// zero, i.e. the money-weighted return. ok is false when flows lack both an
+// inflow and an outflow or no rate can be found.
+func XIRR(flows []CashFlow) (float64, bool) {
+	if len(flows) < 2 {
+		return 0, false
+	}
+	first, err := time.Parse("2006-01-02", flows[0].Date)
+	if err != nil {
+		return 0, false
+	}
+	years := make([]float64, len(flows))
+	hasIn, hasOut := false, false
+	for i, f := range flows {
+		t, err := time.Parse("2006-01-02", f.Date)
+		if err != nil {
+			return 0, false
+		}
+		years[i] = t.Sub(first).Hours() / 24 / 365.25
+		hasIn = hasIn || f.Amount < 0
+		hasOut = hasOut || f.Amount > 0
+	}
+	if !hasIn || !hasOut {
+		return 0, false
+	}
+
+	npv := func(rate float64) float64 {
+		sum := 0.0
+		for i, f := range flows {
+			sum += f.Amount / math.Pow(1+rate, years[i])
+		}
+		return sum
+	}
+	dnpv := func(rate float64) float64 {
+		sum := 0.0
+		for i, f := range flows {
+			sum -= years[i] * f.Amount / math.Pow(1+rate, years[i]+1)
+		}
+		return sum
+	}
+
+	// Newton's method converges in a few steps for ordinary portfolios
+	rate := 0.1
+	for i := 0; i < 50; i++ {
+		v, d := npv(rate), dnpv(rate)
+		if d == 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+			break
+		}
+		next := rate - v/d
+		if next <= -1 {
+			break
+		}
+		if math.Abs(next-rate) < 1e-10 {
+			return next, true
+		}
+		rate = next
+	}
+
+	// Otherwise bisect over a bracket with a sign change
+	lo, hi := -1+1e-12, 1.0
+	for npv(lo)*npv(hi) > 0 {
+		hi *= 2
+		if hi > 1e6 {
+			return 0, false
+		}
+	}
+	for i := 0; i < 200; i++ {
+		mid := (lo + hi) / 2
+		if npv(lo)*npv(mid) <= 0 {
+			hi = mid
+		} else {
+			lo = mid
+		}
+		if hi-lo < 1e-10 {
+			break
+		}
+	}
+	return (lo + hi) / 2, true
+}
@@ -0,0 +1,104 @@
+package analytics
+
+import (
+	"math"
+	"sort"
+)
+
+// Sharpe is the annualized mean excess return over riskFree (an annual rate)
+// per unit of volatility; ok is false when returns don't vary
+func Sharpe(returns []float64, riskFree, periodsPerYear float64) (float64, bool) {
+	sd := StdDev(returns)
+	if sd == 0 {
+		return 0, false
+	}
+	excess := Mean(returns) - riskFree/periodsPerYear
+	return excess / sd * math.Sqrt(periodsPerYear), true
+}
+
+// DownsideDeviation is the root mean square of returns below target, per period
+func DownsideDeviation(returns []float64, target float64) float64 {
+	if len(returns) == 0 {
+		return 0
+	}
+	ss := 0.0
+	for _, r := range returns {
+		if r < target {
+			ss += (r - target) * (r - target)
+		}
+	}
+	return math.Sqrt(ss / float64(len(returns)))
+}
+
+// Sortino is like Sharpe but penalizes only returns below the risk-free rate;
+// ok is false when there are none
+func Sortino(returns []float64, riskFree, periodsPerYear float64) (float64, bool) {
+	target := riskFree / periodsPerYear
+	dd := DownsideDeviation(returns, target)
+	if dd == 0 {
+		return 0, false
+	}
+	return (Mean(returns) - target) / dd * math.Sqrt(periodsPerYear), true
+}
+
+// Drawdown is the largest peak-to-trough fall of a series
+type Drawdown struct {
+	Depth    float64 // fraction of the peak lost, e.g. 0.25
+	Peak     int     // index of the peak
+	Trough   int     // index of the trough
+	Recovery int     // index where the peak was regained, or -1
+}
+
+// MaxDrawdown finds the deepest drawdown in values; Depth is 0 for a series that
+// never falls
+func MaxDrawdown(values []float64) Drawdown {
+	dd := Drawdown{Recovery: -1}
+	peak := 0
+	for i, v := range values {
+		if v > values[peak] {
+			peak = i
+		}
+		if values[peak] <= 0 {
+			continue
+		}
+		if depth := 1 - v/values[peak]; depth > dd.Depth {
+			dd = Drawdown{Depth: depth, Peak: peak, Trough: i, Recovery: -1}
+		}
+	}
+	if dd.Depth > 0 {
+		for i := dd.Trough + 1; i < len(values); i++ {
+			if values[i] >= values[dd.Peak] {
+				dd.Recovery = i
+				break
+			}
+		}
+	}
+	return dd
+}
+
+// Quantile returns the q-th quantile (0..1) of xs with linear interpolation
+func Quantile(xs []float64, q float64) float64 {
+	if len(xs) == 0 {
+		return 0
+	}
+	sorted := make([]float64, len(xs))
+	copy(sorted, xs)
+	sort.Float64s(sorted)
+	pos := q * float64(len(sorted)-1)
+	lo := int(math.Floor(pos))
+	hi := int(math.Ceil(pos))
+	return sorted[lo] + (sorted[hi]-sorted[lo])*(pos-float64(lo))
+}
+
+// HistoricalVaR is the one-period loss (as a positive fraction) not exceeded
+// with the given confidence (e.g. 0.95), read from the empirical distribution
+func HistoricalVaR(returns []float64, confidence float64) float64 {
+	return math.Max(0, -Quantile(returns, 1-confidence))
+}
+
+// ParametricVaR is the one-period loss (as a positive fraction) not exceeded with
+// the given confidence, assuming normally distributed returns
+func ParametricVaR(returns []float64, confidence float64) float64 {
+	z := math.Sqrt2 * math.Erfinv(2*confidence-1)
+	return math.Max(0, -(Mean(returns) - z*StdDev(returns)))
+}
@@ -0,0 +1,146 @@
+// Package analytics provides return, correlation and risk statistics over
+// dated price or value series. It is pure computation with no I/O.
+package analytics
+
+import (
+	"sort"
+	"time"
+
+	"tinystock/backend/models"
+)
+
+// Series is a dated sequence of values in ascending date order (dates are YYYY-MM-DD)
+type Series struct {
+	Dates  []string
+	Values []float64
+}
+
+// Len returns the number of observations
+func (s Series) Len() int { return len(s.Values) }
+
+// FromHistory builds a close-price series, dropping points without a close and
+// keeping the last point for each date
+func FromHistory(points []models.HistoryPoint) Series {
+	var s Series
+	for _, p := range points {
+		if p.Close <= 0 {
+			continue
+		}
+		if n := len(s.Dates); n > 0 && s.Dates[n-1] == p.Date {
+			s.Values[n-1] = p.Close
+			continue
+		}
+		s.Dates = append(s.Dates, p.Date)
+		s.Values = append(s.Values, p.Close)
+	}
+	return s
+}
+
+// ValueOn returns the last value on or before date
+func (s Series) ValueOn(date string) (float64, bool) {
+	i := sort.SearchStrings(s.Dates, date)
+	if i < len(s.Dates) && s.Dates[i] == date {
+		return s.Values[i], true
+	}
+	if i == 0 {
+		return 0, false
+	}
+	return s.Values[i-1], true
+}
+
+// Since returns the part of the series on or after date
+func (s Series) Since(date string) Series {
+	i := sort.SearchStrings(s.Dates, date)
+	return Series{Dates: s.Dates[i:], Values: s.Values[i:]}
+}
+
+// Align puts several series on one date axis for instruments that trade on different
+// calendars. The axis is the union of all dates from the first date on which every
+// series has a value; gaps are forward-filled with the last known value.
+func Align(series map[string]Series) ([]string, map[string][]float64) {
+	start := ""
+	dateSet := make(map[string]bool)
+	for _, s := range series {
+		if s.Len() == 0 {
+			return nil, map[string][]float64{}
+		}
+		if s.Dates[0] > start {
+			start = s.Dates[0]
+		}
+		for _, d := range s.Dates {
+			dateSet[d] = true
+		}
+	}
+	var dates []string
+	for d := range dateSet {
+		if d >= start {
+			dates = append(dates, d)
+		}
+	}
+	sort.Strings(dates)
+
+	aligned := make(map[string][]float64, len(series))
+	for name, s := range series {
+		values := make([]float64, len(dates))
+		j := 0
+		last := 0.0
+		for i, d := range dates {
+			for j < len(s.Dates) && s.Dates[j] <= d {
+				last = s.Values[j]
+				j++
+			}
+			values[i] = last
+		}
+		aligned[name] = values
+	}
+	return dates, aligned
+}
+
+// Intersect returns the values of a and b on the dates both series share
+func Intersect(a, b Series) ([]float64, []float64, []string) {
+	var xs, ys []float64
+	var dates []string
+	i, j := 0, 0
+	for i < len(a.Dates) && j < len(b.Dates) {
+		switch {
+		case a.Dates[i] < b.Dates[j]:
+			i++
+		case a.Dates[i] > b.Dates[j]:
+			j++
+		default:
+			xs = append(xs, a.Values[i])
+			ys = append(ys, b.Values[j])
+			dates = append(dates, a.Dates[i])
+			i++
+			j++
+		}
+	}
+	return xs, ys, dates
+}
+
+// Rebase scales values so the first observation equals base (e.g. 100)
+func Rebase(values []float64, base float64) []float64 {
+	out := make([]float64, len(values))
+	if len(values) == 0 || values[0] == 0 {
+		return out
+	}
+	for i, v := range values {
+		out[i] = v / values[0] * base
+	}
+	return out
+}
+
+// PeriodsPerYear estimates how many observations a series has per year from its
+// date span, so daily equity data annualizes with ~252 and crypto with ~365
+func PeriodsPerYear(dates []string) float64 {
+	if len(dates) < 2 {
+		return 252
+	}
+	first, err1 := time.Parse("2006-01-02", dates[0])
+	last, err2 := time.Parse("2006-01-02", dates[len(dates)-1])
+	if err1 != nil || err2 != nil || !last.After(first) {
+		return 252
+	}
+	years := last.Sub(first).Hours() / 24 / 365.25
+	return float64(len(dates)-1) / years
+}
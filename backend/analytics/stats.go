@@ -0,0 +1,71 @@
+package analytics
+
+import "math"
+
+// Returns converts values to simple period returns; the result is one shorter than values
+func Returns(values []float64) []float64 {
+	if len(values) < 2 {
+		return nil
+	}
+	out := make([]float64, 0, len(values)-1)
+	for i := 1; i < len(values); i++ {
+		if values[i-1] == 0 {
+			out = append(out, 0)
+			continue
+		}
+		out = append(out, values[i]/values[i-1]-1)
+	}
+	return out
+}
+
+// Mean returns the arithmetic mean, or 0 for an empty slice
+func Mean(xs []float64) float64 {
+	if len(xs) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, x := range xs {
+		sum += x
+	}
+	return sum / float64(len(xs))
+}
+
+// StdDev returns the sample standard deviation, or 0 with fewer than two values
+func StdDev(xs []float64) float64 {
+	if len(xs) < 2 {
+		return 0
+	}
+	m := Mean(xs)
+	ss := 0.0
+	for _, x := range xs {
+		ss += (x - m) * (x - m)
+	}
+	return math.Sqrt(ss / float64(len(xs)-1))
+}
+
+// Covariance returns the sample covariance of two equal-length slices
+func Covariance(xs, ys []float64) float64 {
+	if len(xs) != len(ys) || len(xs) < 2 {
+		return 0
+	}
+	mx, my := Mean(xs), Mean(ys)
+	s := 0.0
+	for i := range xs {
+		s += (xs[i] - mx) * (ys[i] - my)
+	}
+	return s / float64(len(xs)-1)
+}
+
+// Correlation returns the Pearson correlation; ok is false when it is undefined
+func Correlation(xs, ys []float64) (float64, bool) {
+	sx, sy := StdDev(xs), StdDev(ys)
+	if len(xs) != len(ys) || len(xs) < 3 || sx == 0 || sy == 0 {
+		return 0, false
+	}
+	return Covariance(xs, ys) / (sx * sy), true
+}
+
+// AnnualizedVolatility is the standard deviation of period returns scaled to a year
+func AnnualizedVolatility(returns []float64, periodsPerYear float64) float64 {
+	return StdDev(returns) * math.Sqrt(periodsPerYear)
+}
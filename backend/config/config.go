@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -15,6 +16,16 @@ type Config struct {
 	JWTExpiry   time.Duration
 	RateLimit   int
 	CORSOrigins string
+	// BaseCurrency is the default currency portfolio totals are reported in
+	BaseCurrency string
+	// MoversUniverse lists extra symbols ranked alongside watched symbols
+	MoversUniverse []string
+	// CostBasisMethod is the default lot matching method (fifo, lifo, hifo, average)
+	CostBasisMethod string
+	// RiskFreeRate is the annual rate (e.g. 0.04) used for Sharpe and Sortino ratios
+	RiskFreeRate float64
+	// SnapshotHour is the UTC hour of the nightly portfolio snapshot job; negative disables it
+	SnapshotHour int
 }
 
 // Load reads configuration from environment variables
@@ -57,13 +68,49 @@ func Load() (*Config, error) {
 		corsOrigins = "*"
 	}
 
+	baseCurrency := strings.ToUpper(strings.TrimSpace(os.Getenv("BASE_CURRENCY")))
+	if baseCurrency == "" {
+		baseCurrency = "USD"
+	}
+
+	var moversUniverse []string
+	for _, s := range strings.Split(os.Getenv("MOVERS_UNIVERSE"), ",") {
+		if s = strings.ToUpper(strings.TrimSpace(s)); s != "" {
+			moversUniverse = append(moversUniverse, s)
+		}
+	}
+
+	costBasisMethod := strings.ToLower(strings.TrimSpace(os.Getenv("COST_BASIS_METHOD")))
+	if costBasisMethod == "" {
+		costBasisMethod = "fifo"
+	}
+
+	riskFreeRate := 0.0
+	if rf := strings.TrimSpace(os.Getenv("RISK_FREE_RATE")); rf != "" {
+		if f, err := strconv.ParseFloat(rf, 64); err == nil {
+			riskFreeRate = f
+		}
+	}
+
+	snapshotHour := 22
+	if h := strings.TrimSpace(os.Getenv("SNAPSHOT_HOUR")); h != "" {
+		if n, err := strconv.Atoi(h); err == nil && n < 24 {
+			snapshotHour = n
+		}
+	}
+
 	return &Config{
-		Port:        port,
-		DBDriver:    dbDriver,
-		DatabaseURL: databaseURL,
-		JWTSecret:   jwtSecret,
-		JWTExpiry:   jwtExpiry,
-		RateLimit:   rateLimit,
-		CORSOrigins: corsOrigins,
+		Port:            port,
+		DBDriver:        dbDriver,
+		DatabaseURL:     databaseURL,
+		JWTSecret:       jwtSecret,
+		JWTExpiry:       jwtExpiry,
+		RateLimit:       rateLimit,
+		CORSOrigins:     corsOrigins,
+		BaseCurrency:    baseCurrency,
+		MoversUniverse:  moversUniverse,
+		CostBasisMethod: costBasisMethod,
+		RiskFreeRate:    riskFreeRate,
+		SnapshotHour:    snapshotHour,
 	}, nil
 }
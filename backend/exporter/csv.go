@@ -0,0 +1,67 @@
+package exporter
+
+import (
+	"encoding/csv"
+	"io"
+
+	"tinystock/backend/models"
+)
+
+// csvColumns are the headers per dataset. Transaction columns match the generic
+// import profile, so a transactions CSV imports back as is.
+var csvColumns = map[string][]string{
+	models.ExportHoldings: {"symbol", "quantity", "buyPrice", "currency", "currentPrice",
+		"costBasis", "marketValue", "pnl", "pnlPercent", "marketValueBase", "costBasisBase"},
+	models.ExportTransactions: {"date", "type", "symbol", "quantity", "price", "amount",
+		"currency", "fee", "commission", "tax", "note"},
+	models.ExportWatchlist: {"symbol"},
+}
+
+type csvWriter struct {
+	w      *csv.Writer
+	what   string
+	header bool
+}
+
+func newCSVWriter(w io.Writer, what string) *csvWriter {
+	return &csvWriter{w: csv.NewWriter(w), what: what}
+}
+
+func (c *csvWriter) writeHeader() error {
+	if c.header {
+		return nil
+	}
+	c.header = true
+	return c.w.Write(csvColumns[c.what])
+}
+
+func (c *csvWriter) Write(record interface{}) error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	if err := check(c.what, record); err != nil {
+		return err
+	}
+	var row []string
+	switch r := record.(type) {
+	case models.HoldingWithQuote:
+		row = []string{r.Symbol, r.Quantity.String(), r.BuyPrice.String(), r.Currency, r.CurrentPrice.String(),
+			r.CostBasis.String(), r.MarketValue.String(), r.PnL.String(), number(r.PnLPercent),
+			r.MarketValueBase.String(), r.CostBasisBase.String()}
+	case models.Transaction:
+		row = []string{r.TradeDate, r.Type, r.Symbol, r.Quantity.String(), r.Price.String(), r.Amount.String(),
+			r.Currency, r.Fee.String(), r.Commission.String(), r.Tax.String(), r.Note}
+	case models.WatchlistItem:
+		row = []string{r.Symbol}
+	}
+	return c.w.Write(row)
+}
+
+// Close writes the header of an empty export and flushes
+func (c *csvWriter) Close() error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
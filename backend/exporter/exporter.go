@@ -0,0 +1,99 @@
+// Package exporter encodes holdings, transactions and watchlists as CSV, versioned
+// JSON or OFX, one record at a time so exports stream, and renders tax reports.
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"tinystock/backend/models"
+)
+
+// Formats
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+	FormatOFX  = "ofx"
+)
+
+// Writer encodes the records of one dataset. Write takes a
+// models.HoldingWithQuote, models.Transaction or models.WatchlistItem matching the
+// header's What; Close finishes the document.
+type Writer interface {
+	Write(record interface{}) error
+	Close() error
+}
+
+// RateFunc returns the rate that converts one unit of currency into the export's
+// base currency on date (YYYY-MM-DD, or empty for the latest rate)
+type RateFunc func(currency, date string) (float64, error)
+
+// New returns a Writer for format. rate is used by OFX for records in other
+// currencies than the base.
+func New(w io.Writer, format string, header models.ExportHeader, rate RateFunc) (Writer, error) {
+	if err := Supported(format, header.What); err != nil {
+		return nil, err
+	}
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, header.What), nil
+	case FormatJSON:
+		return newJSONWriter(w, header)
+	default:
+		return newOFXWriter(w, header, rate), nil
+	}
+}
+
+// Supported reports whether format can encode the dataset what
+func Supported(format, what string) error {
+	switch what {
+	case models.ExportHoldings, models.ExportTransactions, models.ExportWatchlist:
+	default:
+		return fmt.Errorf("what must be holdings, transactions or watchlist")
+	}
+	switch format {
+	case FormatCSV, FormatJSON:
+	case FormatOFX:
+		if what == models.ExportWatchlist {
+			return fmt.Errorf("ofx exports holdings or transactions")
+		}
+	default:
+		return fmt.Errorf("format must be csv, json or ofx")
+	}
+	return nil
+}
+
+// ContentType returns the MIME type and file extension of format
+func ContentType(format string) (string, string) {
+	switch format {
+	case FormatJSON:
+		return "application/json; charset=utf-8", "json"
+	case FormatOFX:
+		return "application/x-ofx", "ofx"
+	default:
+		return "text/csv; charset=utf-8", "csv"
+	}
+}
+
+// number formats a float with the fewest digits that read back exactly
+func number(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// check verifies that record is of the type the dataset what holds
+func check(what string, record interface{}) error {
+	var ok bool
+	switch what {
+	case models.ExportHoldings:
+		_, ok = record.(models.HoldingWithQuote)
+	case models.ExportTransactions:
+		_, ok = record.(models.Transaction)
+	case models.ExportWatchlist:
+		_, ok = record.(models.WatchlistItem)
+	}
+	if !ok {
+		return fmt.Errorf("cannot write %T as %s", record, what)
+	}
+	return nil
+}
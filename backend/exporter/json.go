@@ -0,0 +1,52 @@
+package exporter
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+
+	"tinystock/backend/models"
+)
+
+// jsonWriter streams {header fields..., "<what>": [records...]}
+type jsonWriter struct {
+	w     *bufio.Writer
+	what  string
+	count int
+}
+
+func newJSONWriter(w io.Writer, header models.ExportHeader) (*jsonWriter, error) {
+	header.Format = models.ExportFormat
+	header.Version = models.ExportVersion
+	head, err := json.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+	j := &jsonWriter{w: bufio.NewWriter(w), what: header.What}
+	// Reopen the header object to append the record array
+	j.w.Write(head[:len(head)-1])
+	j.w.WriteString(`,"` + header.What + `":[`)
+	return j, nil
+}
+
+func (j *jsonWriter) Write(record interface{}) error {
+	if err := check(j.what, record); err != nil {
+		return err
+	}
+	b, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if j.count > 0 {
+		j.w.WriteByte(',')
+	}
+	j.count++
+	j.w.WriteString("\n")
+	_, err = j.w.Write(b)
+	return err
+}
+
+func (j *jsonWriter) Close() error {
+	j.w.WriteString("]}\n")
+	return j.w.Flush()
+}
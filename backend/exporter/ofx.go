@@ -0,0 +1,289 @@
+package exporter
+
+import (
+	"bufio"
+	"encoding/xml"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"tinystock/backend/internal/decimal"
+	"tinystock/backend/ledger"
+	"tinystock/backend/models"
+)
+
+// ofxWriter writes an OFX 2.2 investment statement. Securities are identified by
+// ticker; amounts stay in each record's currency with a CURRENCY rate to the base.
+type ofxWriter struct {
+	w      *bufio.Writer
+	header models.ExportHeader
+	rate   RateFunc
+	open   bool
+	// symbols collects the securities for the closing SECLIST
+	symbols map[string]bool
+	// shares tracks positions so splits can report old and new units
+	shares map[string]decimal.Decimal
+}
+
+func newOFXWriter(w io.Writer, header models.ExportHeader, rate RateFunc) *ofxWriter {
+	o := &ofxWriter{w: bufio.NewWriter(w), header: header, rate: rate, symbols: make(map[string]bool), shares: make(map[string]decimal.Decimal)}
+	now := header.ExportedAt.UTC().Format("20060102150405")
+	account := ""
+	if header.Portfolio != nil {
+		account = strconv.FormatInt(header.Portfolio.ID, 10)
+	}
+	o.w.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="no"?>` + "\n")
+	o.w.WriteString(`<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>` + "\n")
+	o.w.WriteString("<OFX>\n<SIGNONMSGSRSV1>\n<SONRS>\n")
+	o.status()
+	o.field("DTSERVER", now)
+	o.field("LANGUAGE", "ENG")
+	o.w.WriteString("</SONRS>\n</SIGNONMSGSRSV1>\n<INVSTMTMSGSRSV1>\n<INVSTMTTRNRS>\n")
+	o.field("TRNUID", "0")
+	o.status()
+	o.w.WriteString("<INVSTMTRS>\n")
+	o.field("DTASOF", now)
+	o.field("CURDEF", header.BaseCurrency)
+	o.w.WriteString("<INVACCTFROM>\n")
+	o.field("BROKERID", "tinystock")
+	o.field("ACCTID", account)
+	o.w.WriteString("</INVACCTFROM>\n")
+	return o
+}
+
+func (o *ofxWriter) status() {
+	o.w.WriteString("<STATUS>\n")
+	o.field("CODE", "0")
+	o.field("SEVERITY", "INFO")
+	o.w.WriteString("</STATUS>\n")
+}
+
+func (o *ofxWriter) field(tag, value string) {
+	o.w.WriteString("<" + tag + ">")
+	xml.EscapeText(o.w, []byte(value))
+	o.w.WriteString("</" + tag + ">\n")
+}
+
+// openList starts the transaction or position list; transactions arrive in date
+// order, so the first one dates the list
+func (o *ofxWriter) openList(first string) {
+	if o.open {
+		return
+	}
+	o.open = true
+	if o.header.What == models.ExportHoldings {
+		o.w.WriteString("<INVPOSLIST>\n")
+		return
+	}
+	o.w.WriteString("<INVTRANLIST>\n")
+	o.field("DTSTART", first)
+	o.field("DTEND", o.header.ExportedAt.UTC().Format("20060102"))
+}
+
+func (o *ofxWriter) secID(symbol string) {
+	o.symbols[symbol] = true
+	o.w.WriteString("<SECID>\n")
+	o.field("UNIQUEID", symbol)
+	o.field("UNIQUEIDTYPE", "TICKER")
+	o.w.WriteString("</SECID>\n")
+}
+
+// currency writes the CURRENCY aggregate for amounts not in the base currency
+func (o *ofxWriter) currency(currency, date string) error {
+	if currency == "" || currency == o.header.BaseCurrency {
+		return nil
+	}
+	rate, err := o.rate(currency, date)
+	if err != nil {
+		return err
+	}
+	o.w.WriteString("<CURRENCY>\n")
+	o.field("CURRATE", number(rate))
+	o.field("CURSYM", currency)
+	o.w.WriteString("</CURRENCY>\n")
+	return nil
+}
+
+func (o *ofxWriter) Write(record interface{}) error {
+	if err := check(o.header.What, record); err != nil {
+		return err
+	}
+	switch r := record.(type) {
+	case models.HoldingWithQuote:
+		o.openList(o.header.ExportedAt.UTC().Format("20060102"))
+		return o.position(r)
+	case models.Transaction:
+		o.openList(ofxDate(r.TradeDate))
+		return o.transaction(r)
+	}
+	return nil
+}
+
+func (o *ofxWriter) position(h models.HoldingWithQuote) error {
+	o.w.WriteString("<POSSTOCK>\n<INVPOS>\n")
+	o.secID(h.Symbol)
+	o.field("HELDINACCT", "CASH")
+	o.field("POSTYPE", "LONG")
+	o.field("UNITS", h.Quantity.String())
+	o.field("UNITPRICE", h.CurrentPrice.String())
+	o.field("MKTVAL", h.MarketValue.String())
+	o.field("DTPRICEASOF", o.header.ExportedAt.UTC().Format("20060102"))
+	if err := o.currency(h.Currency, ""); err != nil {
+		return err
+	}
+	o.w.WriteString("</INVPOS>\n</POSSTOCK>\n")
+	return nil
+}
+
+func (o *ofxWriter) invTran(t models.Transaction) {
+	o.w.WriteString("<INVTRAN>\n")
+	o.field("FITID", strconv.FormatInt(t.ID, 10))
+	o.field("DTTRADE", ofxDate(t.TradeDate))
+	if t.Note != "" {
+		o.field("MEMO", t.Note)
+	}
+	o.w.WriteString("</INVTRAN>\n")
+}
+
+func (o *ofxWriter) charges(t models.Transaction) {
+	if t.Commission.Sign() > 0 {
+		o.field("COMMISSION", t.Commission.String())
+	}
+	if t.Tax.Sign() > 0 {
+		o.field("TAXES", t.Tax.String())
+	}
+	if t.Fee.Sign() > 0 {
+		o.field("FEES", t.Fee.String())
+	}
+}
+
+func (o *ofxWriter) transaction(t models.Transaction) error {
+	charges := ledger.Charges(t)
+	switch t.Type {
+	case models.TxBuy, models.TxSell:
+		value := t.Quantity.Mul(t.Price)
+		aggregate, inner, kind, units, total := "BUYSTOCK", "INVBUY", "BUYTYPE", t.Quantity, value.Add(charges).Neg()
+		if t.Type == models.TxSell {
+			aggregate, inner, kind, units, total = "SELLSTOCK", "INVSELL", "SELLTYPE", t.Quantity.Neg(), value.Sub(charges)
+		}
+		o.shares[t.Symbol] = o.shares[t.Symbol].Add(units)
+		o.w.WriteString("<" + aggregate + ">\n<" + inner + ">\n")
+		o.invTran(t)
+		o.secID(t.Symbol)
+		o.field("UNITS", units.String())
+		o.field("UNITPRICE", t.Price.String())
+		o.charges(t)
+		o.field("TOTAL", total.String())
+		if err := o.currency(t.Currency, t.TradeDate); err != nil {
+			return err
+		}
+		o.field("SUBACCTSEC", "CASH")
+		o.field("SUBACCTFUND", "CASH")
+		o.w.WriteString("</" + inner + ">\n")
+		o.field(kind, strings.ToUpper(t.Type))
+		o.w.WriteString("</" + aggregate + ">\n")
+	case models.TxDividend:
+		o.w.WriteString("<INCOME>\n")
+		o.invTran(t)
+		o.secID(t.Symbol)
+		o.field("INCOMETYPE", "DIV")
+		o.field("TOTAL", t.Amount.Sub(charges).String())
+		o.field("SUBACCTSEC", "CASH")
+		o.field("SUBACCTFUND", "CASH")
+		if t.Tax.Sign() > 0 {
+			o.field("WITHHOLDING", t.Tax.String())
+		}
+		if err := o.currency(t.Currency, t.TradeDate); err != nil {
+			return err
+		}
+		o.w.WriteString("</INCOME>\n")
+	case models.TxSplit:
+		before := o.shares[t.Symbol]
+		o.shares[t.Symbol] = before.Mul(t.Quantity)
+		o.w.WriteString("<SPLIT>\n")
+		o.invTran(t)
+		o.secID(t.Symbol)
+		o.field("SUBACCTSEC", "CASH")
+		o.field("OLDUNITS", before.String())
+		o.field("NEWUNITS", o.shares[t.Symbol].String())
+		o.field("NUMERATOR", t.Quantity.String())
+		o.field("DENOMINATOR", "1")
+		o.w.WriteString("</SPLIT>\n")
+	case models.TxTransferIn, models.TxTransferOut:
+		units, action := t.Quantity, "IN"
+		if t.Type == models.TxTransferOut {
+			units, action = t.Quantity.Neg(), "OUT"
+		}
+		o.shares[t.Symbol] = o.shares[t.Symbol].Add(units)
+		o.w.WriteString("<TRANSFER>\n")
+		o.invTran(t)
+		o.secID(t.Symbol)
+		o.field("SUBACCTSEC", "CASH")
+		o.field("UNITS", units.String())
+		o.field("TFERACTION", action)
+		o.field("POSTYPE", "LONG")
+		if t.Price.Sign() > 0 {
+			o.field("UNITPRICE", t.Price.String())
+		}
+		o.w.WriteString("</TRANSFER>\n")
+	default:
+		// Deposits, withdrawals and fees are cash movements
+		kind, amount := "CREDIT", t.Amount.Sub(charges)
+		switch t.Type {
+		case models.TxWithdrawal:
+			kind, amount = "DEBIT", t.Amount.Add(charges).Neg()
+		case models.TxFee:
+			kind, amount = "FEE", t.Amount.Add(charges).Neg()
+		}
+		o.w.WriteString("<INVBANKTRAN>\n<STMTTRN>\n")
+		o.field("TRNTYPE", kind)
+		o.field("DTPOSTED", ofxDate(t.TradeDate))
+		o.field("TRNAMT", amount.String())
+		o.field("FITID", strconv.FormatInt(t.ID, 10))
+		if memo := strings.TrimSpace(t.Symbol + " " + t.Note); memo != "" {
+			o.field("MEMO", memo)
+		}
+		if err := o.currency(t.Currency, t.TradeDate); err != nil {
+			return err
+		}
+		o.w.WriteString("</STMTTRN>\n")
+		o.field("SUBACCTFUND", "CASH")
+		o.w.WriteString("</INVBANKTRAN>\n")
+	}
+	return nil
+}
+
+// Close ends the list and statement and lists the securities referenced
+func (o *ofxWriter) Close() error {
+	o.openList(o.header.ExportedAt.UTC().Format("20060102"))
+	if o.header.What == models.ExportHoldings {
+		o.w.WriteString("</INVPOSLIST>\n")
+	} else {
+		o.w.WriteString("</INVTRANLIST>\n")
+	}
+	o.w.WriteString("</INVSTMTRS>\n</INVSTMTTRNRS>\n</INVSTMTMSGSRSV1>\n")
+	if len(o.symbols) > 0 {
+		symbols := make([]string, 0, len(o.symbols))
+		for s := range o.symbols {
+			symbols = append(symbols, s)
+		}
+		sort.Strings(symbols)
+		o.w.WriteString("<SECLISTMSGSRSV1>\n<SECLIST>\n")
+		for _, s := range symbols {
+			o.w.WriteString("<STOCKINFO>\n<SECINFO>\n")
+			o.secID(s)
+			o.field("SECNAME", s)
+			o.field("TICKER", s)
+			o.w.WriteString("</SECINFO>\n</STOCKINFO>\n")
+		}
+		o.w.WriteString("</SECLIST>\n</SECLISTMSGSRSV1>\n")
+	}
+	o.w.WriteString("</OFX>\n")
+	return o.w.Flush()
+}
+
+// ofxDate converts YYYY-MM-DD to OFX's YYYYMMDD
+func ofxDate(date string) string {
+	return strings.ReplaceAll(date, "-", "")
+}
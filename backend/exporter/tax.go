@@ -0,0 +1,111 @@
+package exporter
+
+import (
+	"encoding/csv"
+	"fmt"
+	"html/template"
+	"io"
+
+	"tinystock/backend/internal/decimal"
+	"tinystock/backend/models"
+)
+
+// money formats a report amount to cents
+func money(v decimal.Decimal) string {
+	return v.StringFixed(2)
+}
+
+func term(longTerm bool) string {
+	if longTerm {
+		return "long"
+	}
+	return "short"
+}
+
+// WriteTaxCSV writes a tax report's lots followed by short-term, long-term and
+// overall totals; amounts are in the report currency and wash sale lots carry code W
+func WriteTaxCSV(w io.Writer, r *models.TaxReport) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"term", "symbol", "quantity", "openDate", "closeDate", "holdingDays", "currency",
+		"proceeds", "cost", "adjustment", "code", "gain"})
+	for _, l := range r.Lots {
+		cw.Write([]string{term(l.LongTerm), l.Symbol, l.Quantity.String(), l.OpenDate, l.CloseDate,
+			fmt.Sprint(l.HoldingDays), l.Currency, money(l.Proceeds), money(l.Cost), money(l.Adjustment), l.Code, money(l.Gain)})
+	}
+	for _, t := range []struct {
+		name   string
+		totals models.TaxTotals
+	}{{"short total", r.ShortTerm}, {"long total", r.LongTerm}, {"total", r.Total}} {
+		cw.Write([]string{t.name, "", "", r.From, r.To, "", r.Currency,
+			money(t.totals.Proceeds), money(t.totals.Cost), money(t.totals.Adjustments), "", money(t.totals.Net)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+var taxTemplate = template.Must(template.New("tax").Funcs(template.FuncMap{
+	"money": money,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Capital gains {{.FiscalYearLabel}} - {{.PortfolioName}}</title>
+<style>
+body { font-family: Helvetica, Arial, sans-serif; font-size: 12px; margin: 2em; color: #111; }
+h1 { font-size: 18px; margin-bottom: 0; }
+p.meta { color: #555; margin-top: 4px; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 1.5em; }
+th, td { border-bottom: 1px solid #ddd; padding: 4px 6px; text-align: left; }
+td.num, th.num { text-align: right; font-variant-numeric: tabular-nums; }
+tr.total td { font-weight: bold; border-top: 2px solid #111; }
+.loss { color: #b00; }
+@media print { body { margin: 0; } h2 { page-break-after: avoid; } tr { page-break-inside: avoid; } }
+</style>
+</head>
+<body>
+<h1>Capital gains {{.FiscalYearLabel}}</h1>
+<p class="meta">{{.PortfolioName}} &middot; {{.JurisdictionName}} &middot; {{.From}} to {{.To}} &middot;
+amounts in {{.Currency}} &middot; {{.CostBasisMethod}} lot matching &middot; long-term after {{.LongTermMonths}} months{{if .WashSales}} &middot; wash sale losses deferred (code W){{end}}</p>
+
+<h2>Summary</h2>
+<table>
+<tr><th></th><th class="num">Proceeds</th><th class="num">Cost</th><th class="num">Adjustments</th><th class="num">Gains</th><th class="num">Losses</th><th class="num">Net</th></tr>
+<tr><td>Short-term</td>{{template "totals" .ShortTerm}}</tr>
+<tr><td>Long-term</td>{{template "totals" .LongTerm}}</tr>
+<tr class="total"><td>Total</td>{{template "totals" .Total}}</tr>
+</table>
+{{range .Sections}}{{template "section" .}}{{end}}
+</body>
+</html>
+{{define "totals"}}<td class="num">{{money .Proceeds}}</td><td class="num">{{money .Cost}}</td><td class="num">{{money .Adjustments}}</td><td class="num">{{money .Gains}}</td><td class="num loss">{{money .Losses}}</td><td class="num">{{money .Net}}</td>{{end}}
+{{define "section"}}<h2>{{.Title}}</h2>
+{{if .Lots}}<table>
+<tr><th>Symbol</th><th class="num">Quantity</th><th>Acquired</th><th>Sold</th><th class="num">Days</th><th>Currency</th><th class="num">Proceeds</th><th class="num">Cost</th><th class="num">Adjustment</th><th>Code</th><th class="num">Gain</th></tr>
+{{range .Lots}}<tr><td>{{.Symbol}}</td><td class="num">{{.Quantity}}</td><td>{{.OpenDate}}</td><td>{{.CloseDate}}</td><td class="num">{{.HoldingDays}}</td><td>{{.Currency}}</td><td class="num">{{money .Proceeds}}</td><td class="num">{{money .Cost}}</td><td class="num">{{money .Adjustment}}</td><td>{{.Code}}</td><td class="num{{if lt .Gain.Sign 0}} loss{{end}}">{{money .Gain}}</td></tr>
+{{end}}<tr class="total"><td colspan="6">Total</td><td class="num">{{money .Totals.Proceeds}}</td><td class="num">{{money .Totals.Cost}}</td><td class="num">{{money .Totals.Adjustments}}</td><td></td><td class="num">{{money .Totals.Net}}</td></tr>
+</table>{{else}}<p>No sales.</p>{{end}}
+{{end}}`))
+
+// taxSection is one holding-period table of the HTML report
+type taxSection struct {
+	Title  string
+	Lots   []models.TaxLot
+	Totals models.TaxTotals
+}
+
+// WriteTaxHTML writes a tax report as a standalone page laid out for printing
+func WriteTaxHTML(w io.Writer, r *models.TaxReport) error {
+	short := taxSection{Title: "Short-term", Totals: r.ShortTerm}
+	long := taxSection{Title: "Long-term", Totals: r.LongTerm}
+	for _, l := range r.Lots {
+		if l.LongTerm {
+			long.Lots = append(long.Lots, l)
+		} else {
+			short.Lots = append(short.Lots, l)
+		}
+	}
+	return taxTemplate.Execute(w, struct {
+		*models.TaxReport
+		Sections []taxSection
+	}{r, []taxSection{short, long}})
+}
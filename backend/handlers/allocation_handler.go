@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"tinystock/backend/internal/response"
+	"tinystock/backend/middleware"
+	"tinystock/backend/services"
+)
+
+// AllocationHandler handles portfolio allocation endpoints (requires auth)
+type AllocationHandler struct {
+	allocation *services.AllocationService
+}
+
+// NewAllocationHandler creates a new AllocationHandler
+func NewAllocationHandler(allocation *services.AllocationService) *AllocationHandler {
+	return &AllocationHandler{allocation: allocation}
+}
+
+// Get handles GET /api/portfolio/allocation and GET /api/portfolios/:pid/allocation
+// ?by=sector|assetType|currency|exchange|symbol&currency=INR
+func (h *AllocationHandler) Get(c *gin.Context) {
+	pid, ok := portfolioID(c)
+	if !ok {
+		return
+	}
+	userID := middleware.GetUserID(c)
+	result, err := h.allocation.GetAllocation(c.Request.Context(), userID, pid, c.Query("by"), c.Query("currency"))
+	if err != nil {
+		portfolioError(c, err, "Failed to compute allocation")
+		return
+	}
+	response.Success(c, result)
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"tinystock/backend/internal/response"
+	"tinystock/backend/services"
+)
+
+// CompareHandler handles symbol comparison endpoints (public, no auth)
+type CompareHandler struct {
+	compare *services.CompareService
+}
+
+// NewCompareHandler creates a new CompareHandler
+func NewCompareHandler(compare *services.CompareService) *CompareHandler {
+	return &CompareHandler{compare: compare}
+}
+
+// Compare handles GET /api/compare?symbols=AAPL,MSFT,NVDA&range=1y
+func (h *CompareHandler) Compare(c *gin.Context) {
+	symbols := strings.Split(c.Query("symbols"), ",")
+	range_ := c.DefaultQuery("range", "1y")
+	result, err := h.compare.Compare(c.Request.Context(), symbols, range_)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidComparison) {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		response.NotFound(c, err.Error())
+		return
+	}
+	response.Success(c, result)
+}
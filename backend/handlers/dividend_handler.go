@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"tinystock/backend/internal/response"
+	"tinystock/backend/middleware"
+	"tinystock/backend/services"
+)
+
+// DividendHandler handles dividend ingestion and income endpoints (requires auth)
+type DividendHandler struct {
+	dividends *services.DividendService
+}
+
+// NewDividendHandler creates a new DividendHandler
+func NewDividendHandler(dividends *services.DividendService) *DividendHandler {
+	return &DividendHandler{dividends: dividends}
+}
+
+// Sync handles POST /api/portfolio/dividends/sync and POST /api/portfolios/:pid/dividends/sync
+func (h *DividendHandler) Sync(c *gin.Context) {
+	pid, ok := portfolioID(c)
+	if !ok {
+		return
+	}
+	result, err := h.dividends.Sync(c.Request.Context(), middleware.GetUserID(c), pid)
+	if err != nil {
+		portfolioError(c, err, "Failed to sync dividends")
+		return
+	}
+	response.Success(c, result)
+}
+
+// Income handles GET /api/portfolio/income and GET /api/portfolios/:pid/income?currency=INR
+func (h *DividendHandler) Income(c *gin.Context) {
+	pid, ok := portfolioID(c)
+	if !ok {
+		return
+	}
+	report, err := h.dividends.Income(c.Request.Context(), middleware.GetUserID(c), pid, c.Query("currency"))
+	if err != nil {
+		portfolioError(c, err, "Failed to compute income")
+		return
+	}
+	response.Success(c, report)
+}
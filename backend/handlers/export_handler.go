@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"tinystock/backend/internal/response"
+	"tinystock/backend/middleware"
+	"tinystock/backend/services"
+)
+
+// ExportHandler handles data exports (requires auth)
+type ExportHandler struct {
+	exports *services.ExportService
+}
+
+// NewExportHandler creates a new ExportHandler
+func NewExportHandler(exports *services.ExportService) *ExportHandler {
+	return &ExportHandler{exports: exports}
+}
+
+// Export handles GET /api/export?format=csv|json|ofx&what=holdings|transactions|watchlist&portfolioId=.
+// The file streams as an attachment; portfolioId defaults to the default portfolio.
+func (h *ExportHandler) Export(c *gin.Context) {
+	var pid int64
+	if q := c.Query("portfolioId"); q != "" {
+		id, err := strconv.ParseInt(q, 10, 64)
+		if err != nil || id <= 0 {
+			response.BadRequest(c, "Invalid portfolioId")
+			return
+		}
+		pid = id
+	}
+	open := func(contentType, filename string) io.Writer {
+		c.Header("Content-Type", contentType)
+		c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+		c.Status(http.StatusOK)
+		return c.Writer
+	}
+	err := h.exports.Export(c.Request.Context(), middleware.GetUserID(c), pid,
+		c.DefaultQuery("format", "csv"), c.DefaultQuery("what", "transactions"), open)
+	switch {
+	case err == nil:
+	case c.Writer.Written():
+		// Headers are sent; a truncated body is all that can signal the failure
+		log.Printf("export: %v", err)
+		c.Abort()
+	default:
+		c.Writer.Header().Del("Content-Disposition")
+		c.Writer.Header().Del("Content-Type")
+		portfolioError(c, err, "Failed to export")
+	}
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"tinystock/backend/internal/response"
+	"tinystock/backend/middleware"
+	"tinystock/backend/models"
+	"tinystock/backend/services"
+)
+
+// FeeHandler handles broker fee schedule endpoints (requires auth)
+type FeeHandler struct {
+	fees *services.FeeService
+}
+
+// NewFeeHandler creates a new FeeHandler
+func NewFeeHandler(fees *services.FeeService) *FeeHandler {
+	return &FeeHandler{fees: fees}
+}
+
+// List handles GET /api/fee-schedules
+func (h *FeeHandler) List(c *gin.Context) {
+	schedules, err := h.fees.ListFeeSchedules(c.Request.Context(), middleware.GetUserID(c))
+	if err != nil {
+		response.InternalError(c, "Failed to list fee schedules")
+		return
+	}
+	response.Success(c, schedules)
+}
+
+// Create handles POST /api/fee-schedules
+func (h *FeeHandler) Create(c *gin.Context) {
+	var req models.FeeSchedule
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "name and kind are required")
+		return
+	}
+	req.ID = 0
+	req.UserID = middleware.GetUserID(c)
+	if err := h.fees.CreateFeeSchedule(c.Request.Context(), &req); err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidFeeSchedule):
+			response.BadRequest(c, err.Error())
+		case strings.Contains(err.Error(), "UNIQUE") || strings.Contains(err.Error(), "unique"):
+			response.ErrorResponse(c, http.StatusConflict, "FEE_SCHEDULE_EXISTS", "A fee schedule with this name already exists")
+		default:
+			response.InternalError(c, "Failed to create fee schedule")
+		}
+		return
+	}
+	response.Created(c, req)
+}
+
+// Delete handles DELETE /api/fee-schedules/:id
+func (h *FeeHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid fee schedule id")
+		return
+	}
+	if err := h.fees.DeleteFeeSchedule(c.Request.Context(), middleware.GetUserID(c), id); err != nil {
+		response.InternalError(c, "Failed to delete fee schedule")
+		return
+	}
+	response.Success(c, gin.H{"message": "removed"})
+}
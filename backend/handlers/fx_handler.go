@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"tinystock/backend/internal/response"
+	"tinystock/backend/models"
+	"tinystock/backend/services"
+)
+
+// FXHandler handles currency endpoints (public, no auth)
+type FXHandler struct {
+	fx *services.FXService
+}
+
+// NewFXHandler creates a new FXHandler
+func NewFXHandler(fx *services.FXService) *FXHandler {
+	return &FXHandler{fx: fx}
+}
+
+// Convert handles GET /api/fx?from=USD&to=INR&amount=100&date=2024-01-02
+func (h *FXHandler) Convert(c *gin.Context) {
+	from := strings.TrimSpace(c.Query("from"))
+	to := strings.TrimSpace(c.Query("to"))
+	if from == "" || to == "" {
+		response.BadRequest(c, "from and to currencies are required")
+		return
+	}
+	amount := 1.0
+	if a := c.Query("amount"); a != "" {
+		n, err := strconv.ParseFloat(a, 64)
+		if err != nil {
+			response.BadRequest(c, "Invalid amount")
+			return
+		}
+		amount = n
+	}
+
+	var (
+		rate *models.FXRate
+		err  error
+	)
+	if d := c.Query("date"); d != "" {
+		date, parseErr := time.Parse("2006-01-02", d)
+		if parseErr != nil {
+			response.BadRequest(c, "date must be YYYY-MM-DD")
+			return
+		}
+		rate, err = h.fx.GetRateOn(c.Request.Context(), from, to, date)
+	} else {
+		rate, err = h.fx.GetRate(c.Request.Context(), from, to)
+	}
+	if err != nil {
+		if err == services.ErrInvalidCurrency {
+			response.BadRequest(c, "Invalid currency code")
+			return
+		}
+		response.NotFound(c, "Exchange rate not available")
+		return
+	}
+	response.Success(c, models.FXConversion{FXRate: *rate, Amount: amount, Converted: amount * rate.Rate})
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"tinystock/backend/internal/response"
+	"tinystock/backend/middleware"
+	"tinystock/backend/services"
+)
+
+// maxImportSize caps an uploaded statement
+const maxImportSize = 5 << 20
+
+// ImportHandler handles statement imports (requires auth)
+type ImportHandler struct {
+	imports *services.ImportService
+}
+
+// NewImportHandler creates a new ImportHandler
+func NewImportHandler(imports *services.ImportService) *ImportHandler {
+	return &ImportHandler{imports: imports}
+}
+
+// Profiles handles GET /api/import/profiles
+func (h *ImportHandler) Profiles(c *gin.Context) {
+	response.Success(c, h.imports.Profiles())
+}
+
+// Import handles POST /api/portfolio/import and POST /api/portfolios/:pid/import.
+// The CSV is a multipart "file" field or the raw body, up to 5 MB. ?profile= picks
+// the layout (generic, zerodha, ibkr, robinhood), ?mapping= is a JSON object of
+// field -> column header, and ?dryRun=true returns the preview without importing.
+func (h *ImportHandler) Import(c *gin.Context) {
+	pid, ok := portfolioID(c)
+	if !ok {
+		return
+	}
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+	opts := services.ImportOptions{
+		Profile: c.Query("profile"),
+		DryRun:  c.Query("dryRun") == "true",
+	}
+	mapping := c.Query("mapping")
+
+	var body io.Reader = c.Request.Body
+	if c.ContentType() == "multipart/form-data" {
+		file, err := c.FormFile("file")
+		if err != nil {
+			response.BadRequest(c, "file is required (max 5 MB)")
+			return
+		}
+		f, err := file.Open()
+		if err != nil {
+			response.BadRequest(c, "Unreadable file")
+			return
+		}
+		defer f.Close()
+		body = f
+		if mapping == "" {
+			mapping = c.PostForm("mapping")
+		}
+	}
+	if mapping != "" {
+		if err := json.Unmarshal([]byte(mapping), &opts.Mapping); err != nil {
+			response.BadRequest(c, "mapping must be a JSON object of field to column header")
+			return
+		}
+	}
+
+	result, err := h.imports.Import(c.Request.Context(), middleware.GetUserID(c), pid, body, opts)
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		response.ErrorResponse(c, http.StatusRequestEntityTooLarge, "FILE_TOO_LARGE", "Statements are limited to 5 MB")
+	case errors.Is(err, services.ErrImportRejected):
+		response.ErrorWithData(c, http.StatusUnprocessableEntity, "IMPORT_REJECTED", err.Error(), result)
+	case err != nil:
+		portfolioError(c, err, "Failed to import statement")
+	case opts.DryRun:
+		response.Success(c, result)
+	default:
+		response.Created(c, result)
+	}
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"tinystock/backend/internal/response"
+	"tinystock/backend/services"
+)
+
+// IndexHandler handles benchmark index endpoints (public, no auth)
+type IndexHandler struct {
+	indices *services.IndexService
+}
+
+// NewIndexHandler creates a new IndexHandler
+func NewIndexHandler(indices *services.IndexService) *IndexHandler {
+	return &IndexHandler{indices: indices}
+}
+
+// List handles GET /api/indices
+func (h *IndexHandler) List(c *gin.Context) {
+	indices, err := h.indices.ListIndices(c.Request.Context())
+	if err != nil {
+		response.InternalError(c, "Failed to get indices")
+		return
+	}
+	response.Success(c, gin.H{"indices": indices})
+}
+
+// Constituents handles GET /api/indices/:id/constituents?quotes=true
+func (h *IndexHandler) Constituents(c *gin.Context) {
+	withQuotes := c.Query("quotes") == "true"
+	result, err := h.indices.GetConstituents(c.Request.Context(), c.Param("id"), withQuotes)
+	if err != nil {
+		if err == services.ErrIndexNotFound {
+			response.NotFound(c, "Index not found")
+			return
+		}
+		response.InternalError(c, "Failed to get constituents")
+		return
+	}
+	response.Success(c, result)
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"tinystock/backend/indicators"
+	"tinystock/backend/internal/response"
+	"tinystock/backend/services"
+)
+
+// IndicatorHandler handles technical indicator endpoints (public, no auth)
+type IndicatorHandler struct {
+	indicators *services.IndicatorService
+}
+
+// NewIndicatorHandler creates a new IndicatorHandler
+func NewIndicatorHandler(indicators *services.IndicatorService) *IndicatorHandler {
+	return &IndicatorHandler{indicators: indicators}
+}
+
+// Get handles GET /api/indicators/:symbol?set=rsi:14,sma:50&range=1y&interval=1d
+func (h *IndicatorHandler) Get(c *gin.Context) {
+	symbol := strings.ToUpper(strings.TrimSpace(c.Param("symbol")))
+	if symbol == "" {
+		response.BadRequest(c, "Symbol is required")
+		return
+	}
+	specs, err := indicators.ParseSet(c.Query("set"))
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	range_ := c.DefaultQuery("range", "1y")
+	interval := c.DefaultQuery("interval", "1d")
+	series, err := h.indicators.Compute(c.Request.Context(), symbol, specs, range_, interval)
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+	response.Success(c, gin.H{"symbol": symbol, "range": range_, "interval": interval, "indicators": series})
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"tinystock/backend/internal/response"
+	"tinystock/backend/services"
+)
+
+// MarketHandler handles market overview endpoints (public, no auth)
+type MarketHandler struct {
+	movers *services.MoversService
+}
+
+// NewMarketHandler creates a new MarketHandler
+func NewMarketHandler(movers *services.MoversService) *MarketHandler {
+	return &MarketHandler{movers: movers}
+}
+
+// Movers handles GET /api/market/movers?universe=watched|sp500&metric=changePct|volume&limit=
+func (h *MarketHandler) Movers(c *gin.Context) {
+	limit := 0
+	if l := c.Query("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n <= 0 {
+			response.BadRequest(c, "limit must be a positive integer")
+			return
+		}
+		limit = n
+	}
+	movers, err := h.movers.GetMovers(c.Request.Context(), c.Query("universe"), c.Query("metric"), limit)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidUniverse:
+			response.BadRequest(c, "universe must be watched or an index id (e.g. sp500)")
+		case services.ErrInvalidMetric:
+			response.BadRequest(c, "metric must be changePct or volume")
+		default:
+			response.InternalError(c, "Failed to compute movers")
+		}
+		return
+	}
+	response.Success(c, movers)
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"tinystock/backend/internal/response"
+	"tinystock/backend/middleware"
+	"tinystock/backend/services"
+)
+
+// PerformanceHandler handles portfolio return and history endpoints (requires auth)
+type PerformanceHandler struct {
+	performance *services.PerformanceService
+	snapshots   *services.SnapshotService
+}
+
+// NewPerformanceHandler creates a new PerformanceHandler
+func NewPerformanceHandler(performance *services.PerformanceService, snapshots *services.SnapshotService) *PerformanceHandler {
+	return &PerformanceHandler{performance: performance, snapshots: snapshots}
+}
+
+// Performance handles GET /api/portfolio/performance and GET /api/portfolios/:pid/performance
+// ?period=1m|3m|6m|ytd|1y|3y|5y|all or ?from=2024-01-01&to=2024-06-30, plus &currency=INR
+func (h *PerformanceHandler) Performance(c *gin.Context) {
+	pid, ok := portfolioID(c)
+	if !ok {
+		return
+	}
+	userID := middleware.GetUserID(c)
+	result, err := h.performance.GetPerformance(c.Request.Context(), userID, pid,
+		c.Query("currency"), c.Query("period"), c.Query("from"), c.Query("to"))
+	if err != nil {
+		portfolioError(c, err, "Failed to compute performance")
+		return
+	}
+	response.Success(c, result)
+}
+
+// Benchmark handles GET /api/portfolio/benchmark and GET /api/portfolios/:pid/benchmark?symbol=SPY&range=1y
+func (h *PerformanceHandler) Benchmark(c *gin.Context) {
+	pid, ok := portfolioID(c)
+	if !ok {
+		return
+	}
+	userID := middleware.GetUserID(c)
+	result, err := h.performance.Benchmark(c.Request.Context(), userID, pid, c.Query("symbol"), strings.ToLower(c.Query("range")))
+	if err != nil {
+		portfolioError(c, err, "Failed to compare with benchmark")
+		return
+	}
+	response.Success(c, result)
+}
+
+// Risk handles GET /api/portfolio/risk and GET /api/portfolios/:pid/risk?range=1y&riskFreeRate=0.04
+func (h *PerformanceHandler) Risk(c *gin.Context) {
+	pid, ok := portfolioID(c)
+	if !ok {
+		return
+	}
+	var riskFreeRate *float64
+	if rf := c.Query("riskFreeRate"); rf != "" {
+		v, err := strconv.ParseFloat(rf, 64)
+		if err != nil || v < -1 || v > 1 {
+			response.BadRequest(c, "riskFreeRate must be an annual rate such as 0.04")
+			return
+		}
+		riskFreeRate = &v
+	}
+	userID := middleware.GetUserID(c)
+	result, err := h.performance.Risk(c.Request.Context(), userID, pid, strings.ToLower(c.Query("range")), riskFreeRate)
+	if err != nil {
+		portfolioError(c, err, "Failed to compute risk")
+		return
+	}
+	response.Success(c, result)
+}
+
+// History handles GET /api/portfolio/history and GET /api/portfolios/:pid/history?range=1y
+func (h *PerformanceHandler) History(c *gin.Context) {
+	pid, ok := portfolioID(c)
+	if !ok {
+		return
+	}
+	userID := middleware.GetUserID(c)
+	result, err := h.snapshots.History(c.Request.Context(), userID, pid, strings.ToLower(c.Query("range")))
+	if err != nil {
+		portfolioError(c, err, "Failed to load portfolio history")
+		return
+	}
+	response.Success(c, result)
+}
+
+// Backfill handles POST /api/portfolio/history/backfill and
+// POST /api/portfolios/:pid/history/backfill?from=2024-01-01 (from defaults to inception)
+func (h *PerformanceHandler) Backfill(c *gin.Context) {
+	pid, ok := portfolioID(c)
+	if !ok {
+		return
+	}
+	userID := middleware.GetUserID(c)
+	n, err := h.snapshots.Backfill(c.Request.Context(), userID, pid, c.Query("from"))
+	if err != nil {
+		portfolioError(c, err, "Failed to rebuild portfolio history")
+		return
+	}
+	response.Success(c, gin.H{"snapshots": n})
+}
@@ -1,71 +1,313 @@
 package handlers
 
 import (
+	"errors"
+	"net/http"
 	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"tinystock/backend/internal/decimal"
 	"tinystock/backend/internal/response"
+	"tinystock/backend/ledger"
 	"tinystock/backend/middleware"
+	"tinystock/backend/models"
 	"tinystock/backend/services"
 )
 
-// PortfolioHandler handles portfolio endpoints (requires auth)
+// PortfolioHandler handles portfolio endpoints (requires auth). Routes under
+// /api/portfolios/:pid act on that portfolio; the legacy /api/portfolio routes act
+// on the user's default portfolio.
 type PortfolioHandler struct {
-	portfolio *services.PortfolioService
+	portfolio   *services.PortfolioService
+	performance *services.PerformanceService
 }
 
 // NewPortfolioHandler creates a new PortfolioHandler
-func NewPortfolioHandler(portfolio *services.PortfolioService) *PortfolioHandler {
-	return &PortfolioHandler{portfolio: portfolio}
+func NewPortfolioHandler(portfolio *services.PortfolioService, performance *services.PerformanceService) *PortfolioHandler {
+	return &PortfolioHandler{portfolio: portfolio, performance: performance}
 }
 
-// List handles GET /api/portfolio
+// portfolioID reads the :pid route param; routes without it use 0 (the default portfolio)
+func portfolioID(c *gin.Context) (int64, bool) {
+	pid := c.Param("pid")
+	if pid == "" {
+		return 0, true
+	}
+	id, err := strconv.ParseInt(pid, 10, 64)
+	if err != nil || id <= 0 {
+		response.BadRequest(c, "Invalid portfolio id")
+		return 0, false
+	}
+	return id, true
+}
+
+// List handles GET /api/portfolio and GET /api/portfolios/:pid?currency=INR&method=fifo;
+// &benchmark=SPY&range=1y adds benchmark-relative statistics
 func (h *PortfolioHandler) List(c *gin.Context) {
+	pid, ok := portfolioID(c)
+	if !ok {
+		return
+	}
+	userID := middleware.GetUserID(c)
+	summary, err := h.portfolio.GetPortfolio(c.Request.Context(), userID, pid, c.Query("currency"), c.Query("method"))
+	if err != nil {
+		portfolioError(c, err, "Failed to get portfolio")
+		return
+	}
+	if symbol := c.Query("benchmark"); symbol != "" {
+		summary.Benchmark, err = h.performance.Benchmark(c.Request.Context(), userID, summary.PortfolioID, symbol, strings.ToLower(c.Query("range")))
+		if err != nil {
+			portfolioError(c, err, "Failed to compare with benchmark")
+			return
+		}
+	}
+	response.Success(c, summary)
+}
+
+// Realized handles GET /api/portfolio/realized and GET /api/portfolios/:pid/realized?currency=INR&method=hifo
+func (h *PortfolioHandler) Realized(c *gin.Context) {
+	pid, ok := portfolioID(c)
+	if !ok {
+		return
+	}
+	userID := middleware.GetUserID(c)
+	report, err := h.portfolio.GetRealized(c.Request.Context(), userID, pid, c.Query("currency"), c.Query("method"))
+	if err != nil {
+		portfolioError(c, err, "Failed to get realized gains")
+		return
+	}
+	response.Success(c, report)
+}
+
+// Consolidated handles GET /api/portfolios/consolidated?currency=USD
+func (h *PortfolioHandler) Consolidated(c *gin.Context) {
 	userID := middleware.GetUserID(c)
-	summary, err := h.portfolio.GetPortfolio(c.Request.Context(), userID)
+	summary, err := h.portfolio.GetConsolidated(c.Request.Context(), userID, c.Query("currency"))
 	if err != nil {
-		response.InternalError(c, "Failed to get portfolio")
+		portfolioError(c, err, "Failed to get consolidated portfolio")
 		return
 	}
 	response.Success(c, summary)
 }
 
-// Add handles POST /api/portfolio
+// ListPortfolios handles GET /api/portfolios
+func (h *PortfolioHandler) ListPortfolios(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	list, err := h.portfolio.ListPortfolios(c.Request.Context(), userID)
+	if err != nil {
+		response.InternalError(c, "Failed to list portfolios")
+		return
+	}
+	response.Success(c, list)
+}
+
+// portfolioRequest is the body for creating or updating a portfolio
+type portfolioRequest struct {
+	Name            string `json:"name"`
+	BaseCurrency    string `json:"baseCurrency"`
+	CostBasisMethod string `json:"costBasisMethod"`
+	StrictCash      bool   `json:"strictCash"`
+	WashSales       bool   `json:"washSales"`
+}
+
+// CreatePortfolio handles POST /api/portfolios
+func (h *PortfolioHandler) CreatePortfolio(c *gin.Context) {
+	var req portfolioRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "name is required")
+		return
+	}
+	p := &models.Portfolio{
+		UserID:          middleware.GetUserID(c),
+		Name:            req.Name,
+		BaseCurrency:    req.BaseCurrency,
+		CostBasisMethod: req.CostBasisMethod,
+		StrictCash:      req.StrictCash,
+		WashSales:       req.WashSales,
+	}
+	if err := h.portfolio.CreatePortfolio(c.Request.Context(), p); err != nil {
+		portfolioError(c, err, "Failed to create portfolio")
+		return
+	}
+	response.Created(c, p)
+}
+
+// UpdatePortfolio handles PUT /api/portfolios/:pid
+func (h *PortfolioHandler) UpdatePortfolio(c *gin.Context) {
+	pid, ok := portfolioID(c)
+	if !ok {
+		return
+	}
+	var req portfolioRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "name is required")
+		return
+	}
+	p := &models.Portfolio{
+		ID:              pid,
+		UserID:          middleware.GetUserID(c),
+		Name:            req.Name,
+		BaseCurrency:    req.BaseCurrency,
+		CostBasisMethod: req.CostBasisMethod,
+		StrictCash:      req.StrictCash,
+		WashSales:       req.WashSales,
+	}
+	if err := h.portfolio.UpdatePortfolio(c.Request.Context(), p); err != nil {
+		portfolioError(c, err, "Failed to update portfolio")
+		return
+	}
+	response.Success(c, p)
+}
+
+// DeletePortfolio handles DELETE /api/portfolios/:pid
+func (h *PortfolioHandler) DeletePortfolio(c *gin.Context) {
+	pid, ok := portfolioID(c)
+	if !ok {
+		return
+	}
+	userID := middleware.GetUserID(c)
+	if err := h.portfolio.DeletePortfolio(c.Request.Context(), userID, pid); err != nil {
+		portfolioError(c, err, "Failed to delete portfolio")
+		return
+	}
+	response.Success(c, gin.H{"message": "removed"})
+}
+
+// portfolioError maps validation errors to 4xx and everything else to 500
+func portfolioError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrInvalidCurrency):
+		response.BadRequest(c, "Invalid currency code")
+	case errors.Is(err, ledger.ErrInvalidMethod):
+		response.BadRequest(c, "Invalid cost basis method (fifo, lifo, hifo or average)")
+	case errors.Is(err, services.ErrInvalidPortfolio), errors.Is(err, services.ErrInvalidPeriod),
+		errors.Is(err, services.ErrInvalidBenchmark), errors.Is(err, services.ErrInvalidAllocation),
+		errors.Is(err, services.ErrInvalidTarget), errors.Is(err, services.ErrInvalidRebalance),
+		errors.Is(err, services.ErrInvalidImport), errors.Is(err, services.ErrInvalidExport),
+		errors.Is(err, services.ErrInvalidTaxReport):
+		response.BadRequest(c, err.Error())
+	case errors.Is(err, services.ErrFXUnavailable):
+		response.ErrorResponse(c, http.StatusUnprocessableEntity, "FX_UNAVAILABLE", err.Error())
+	case errors.Is(err, ledger.ErrInsufficientCash):
+		response.ErrorResponse(c, http.StatusConflict, "INSUFFICIENT_CASH", err.Error())
+	case errors.Is(err, services.ErrPortfolioNotFound):
+		response.NotFound(c, "Portfolio not found")
+	case strings.Contains(err.Error(), "UNIQUE") || strings.Contains(err.Error(), "unique"):
+		response.ErrorResponse(c, http.StatusConflict, "PORTFOLIO_EXISTS", "A portfolio with this name already exists")
+	default:
+		response.InternalError(c, message)
+	}
+}
+
+// Add handles POST /api/portfolio and POST /api/portfolios/:pid/holdings; fee,
+// commission, tax and feeScheduleId are optional
 func (h *PortfolioHandler) Add(c *gin.Context) {
+	pid, ok := portfolioID(c)
+	if !ok {
+		return
+	}
 	var req struct {
-		Symbol   string  `json:"symbol"`
-		Quantity float64 `json:"quantity"`
-		BuyPrice float64 `json:"buyPrice"`
+		Symbol   string          `json:"symbol"`
+		Quantity decimal.Decimal `json:"quantity"`
+		BuyPrice decimal.Decimal `json:"buyPrice"`
+		models.Charges
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		response.BadRequest(c, "symbol, quantity, and buyPrice are required")
 		return
 	}
 	symbol := strings.ToUpper(strings.TrimSpace(req.Symbol))
-	if symbol == "" || req.Quantity <= 0 || req.BuyPrice <= 0 {
+	if symbol == "" || req.Quantity.Sign() <= 0 || req.BuyPrice.Sign() <= 0 {
 		response.BadRequest(c, "symbol, quantity, and buyPrice must be positive")
 		return
 	}
 	userID := middleware.GetUserID(c)
-	err := h.portfolio.AddHolding(c.Request.Context(), userID, symbol, req.Quantity, req.BuyPrice)
+	err := h.portfolio.AddHolding(c.Request.Context(), userID, pid, symbol, req.Quantity, req.BuyPrice, req.Charges)
 	if err != nil {
-		response.BadRequest(c, err.Error())
+		transactionError(c, err)
 		return
 	}
 	response.Created(c, gin.H{"message": "added", "symbol": symbol})
 }
 
-// Remove handles DELETE /api/portfolio/:id
-func (h *PortfolioHandler) Remove(c *gin.Context) {
-	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+// UpdateHolding handles PATCH /api/portfolio/:id and PATCH /api/portfolios/:pid/holdings/:id,
+// correcting a buy recorded with Add. The body holds only the fields to change; an
+// If-Match header or a version field guards against concurrent edits.
+func (h *PortfolioHandler) UpdateHolding(c *gin.Context) {
+	pid, id, ok := transactionID(c)
+	if !ok {
+		return
+	}
+	var req struct {
+		Symbol        *string          `json:"symbol"`
+		Quantity      *decimal.Decimal `json:"quantity"`
+		BuyPrice      *decimal.Decimal `json:"buyPrice"`
+		TradeDate     *string          `json:"tradeDate"`
+		Note          *string          `json:"note"`
+		Fee           *decimal.Decimal `json:"fee"`
+		Commission    *decimal.Decimal `json:"commission"`
+		Tax           *decimal.Decimal `json:"tax"`
+		FeeScheduleID *int64           `json:"feeScheduleId"`
+		Version       int64            `json:"version"`
+	}
+	if !decodePatch(c, &req) || !ifMatch(c, &req.Version) {
+		return
+	}
+	patch := services.TransactionPatch{
+		Symbol:        req.Symbol,
+		Quantity:      req.Quantity,
+		Price:         req.BuyPrice,
+		TradeDate:     req.TradeDate,
+		Note:          req.Note,
+		Fee:           req.Fee,
+		Commission:    req.Commission,
+		Tax:           req.Tax,
+		FeeScheduleID: req.FeeScheduleID,
+		Version:       req.Version,
+	}
+	tx, err := h.portfolio.UpdateHolding(c.Request.Context(), middleware.GetUserID(c), pid, id, patch)
 	if err != nil {
-		response.BadRequest(c, "Invalid holding id")
+		transactionError(c, err)
+		return
+	}
+	setETag(c, tx.Version)
+	response.Success(c, tx)
+}
+
+// DeleteHolding handles DELETE /api/portfolio/:id and DELETE /api/portfolios/:pid/holdings/:id,
+// removing a buy recorded with Add
+func (h *PortfolioHandler) DeleteHolding(c *gin.Context) {
+	pid, id, ok := transactionID(c)
+	if !ok {
+		return
+	}
+	if err := h.portfolio.DeleteHolding(c.Request.Context(), middleware.GetUserID(c), pid, id); err != nil {
+		transactionError(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "removed"})
+}
+
+// RemovePosition handles DELETE /api/portfolio/positions/:symbol and
+// DELETE /api/portfolios/:pid/positions/:symbol by deleting the symbol's transactions
+func (h *PortfolioHandler) RemovePosition(c *gin.Context) {
+	pid, ok := portfolioID(c)
+	if !ok {
+		return
+	}
+	symbol := strings.ToUpper(strings.TrimSpace(c.Param("symbol")))
+	if symbol == "" {
+		response.BadRequest(c, "Symbol is required")
 		return
 	}
 	userID := middleware.GetUserID(c)
-	if err := h.portfolio.RemoveHolding(c.Request.Context(), userID, id); err != nil {
-		response.InternalError(c, "Failed to remove")
+	if err := h.portfolio.RemovePosition(c.Request.Context(), userID, pid, symbol); err != nil {
+		if errors.Is(err, services.ErrPositionNotFound) {
+			response.NotFound(c, "No transactions for "+symbol)
+			return
+		}
+		portfolioError(c, err, "Failed to remove")
 		return
 	}
 	response.Success(c, gin.H{"message": "removed"})
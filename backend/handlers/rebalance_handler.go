@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"tinystock/backend/internal/response"
+	"tinystock/backend/middleware"
+	"tinystock/backend/models"
+	"tinystock/backend/services"
+)
+
+// RebalanceHandler handles target allocation and rebalancing endpoints (requires auth)
+type RebalanceHandler struct {
+	rebalance *services.RebalanceService
+}
+
+// NewRebalanceHandler creates a new RebalanceHandler
+func NewRebalanceHandler(rebalance *services.RebalanceService) *RebalanceHandler {
+	return &RebalanceHandler{rebalance: rebalance}
+}
+
+// GetTargets handles GET /api/portfolio/targets and GET /api/portfolios/:pid/targets
+func (h *RebalanceHandler) GetTargets(c *gin.Context) {
+	pid, ok := portfolioID(c)
+	if !ok {
+		return
+	}
+	targets, err := h.rebalance.GetTargets(c.Request.Context(), middleware.GetUserID(c), pid)
+	if err != nil {
+		portfolioError(c, err, "Failed to get targets")
+		return
+	}
+	response.Success(c, targets)
+}
+
+// SetTargets handles PUT /api/portfolio/targets and PUT /api/portfolios/:pid/targets,
+// replacing every target with the body's list; an empty list clears them
+func (h *RebalanceHandler) SetTargets(c *gin.Context) {
+	pid, ok := portfolioID(c)
+	if !ok {
+		return
+	}
+	var req struct {
+		Targets []models.Target `json:"targets"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "targets must be a list of {kind, key, weight, threshold}")
+		return
+	}
+	if req.Targets == nil {
+		req.Targets = []models.Target{}
+	}
+	targets, err := h.rebalance.SetTargets(c.Request.Context(), middleware.GetUserID(c), pid, req.Targets)
+	if err != nil {
+		portfolioError(c, err, "Failed to save targets")
+		return
+	}
+	response.Success(c, targets)
+}
+
+// Rebalance handles GET /api/portfolio/rebalance and GET /api/portfolios/:pid/rebalance
+// ?mode=full|cash-only&rounding=whole|fractional&minTrade=100
+func (h *RebalanceHandler) Rebalance(c *gin.Context) {
+	pid, ok := portfolioID(c)
+	if !ok {
+		return
+	}
+	opts := services.RebalanceOptions{Mode: c.Query("mode"), Rounding: c.Query("rounding")}
+	if v := c.Query("minTrade"); v != "" {
+		minTrade, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			response.BadRequest(c, "minTrade must be a number")
+			return
+		}
+		opts.MinTrade = minTrade
+	}
+	result, err := h.rebalance.Rebalance(c.Request.Context(), middleware.GetUserID(c), pid, opts)
+	if err != nil {
+		portfolioError(c, err, "Failed to compute rebalance")
+		return
+	}
+	response.Success(c, result)
+}
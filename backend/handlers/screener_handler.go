@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"tinystock/backend/internal/response"
+	"tinystock/backend/middleware"
+	"tinystock/backend/models"
+	"tinystock/backend/services"
+)
+
+// ScreenerHandler handles stock screener endpoints (requires auth)
+type ScreenerHandler struct {
+	screener *services.ScreenerService
+}
+
+// NewScreenerHandler creates a new ScreenerHandler
+func NewScreenerHandler(screener *services.ScreenerService) *ScreenerHandler {
+	return &ScreenerHandler{screener: screener}
+}
+
+// Run handles POST /api/screener
+func (h *ScreenerHandler) Run(c *gin.Context) {
+	var req models.ScreenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "filter or screenId is required")
+		return
+	}
+	if strings.TrimSpace(req.Filter) == "" && req.ScreenID == 0 {
+		response.BadRequest(c, "filter or screenId is required")
+		return
+	}
+	userID := middleware.GetUserID(c)
+	page, err := h.screener.Run(c.Request.Context(), userID, req)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+	response.Success(c, page)
+}
+
+// ListScreens handles GET /api/screener/screens
+func (h *ScreenerHandler) ListScreens(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	screens, err := h.screener.ListScreens(c.Request.Context(), userID)
+	if err != nil {
+		response.InternalError(c, "Failed to list screens")
+		return
+	}
+	response.Success(c, gin.H{"screens": screens})
+}
+
+// CreateScreen handles POST /api/screener/screens
+func (h *ScreenerHandler) CreateScreen(c *gin.Context) {
+	var req struct {
+		Name     string `json:"name"`
+		Filter   string `json:"filter"`
+		Universe string `json:"universe"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "name and filter are required")
+		return
+	}
+	screen := &models.Screen{
+		UserID:   middleware.GetUserID(c),
+		Name:     req.Name,
+		Filter:   req.Filter,
+		Universe: req.Universe,
+	}
+	if err := h.screener.CreateScreen(c.Request.Context(), screen); err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") || strings.Contains(err.Error(), "unique") {
+			response.ErrorResponse(c, http.StatusConflict, "SCREEN_EXISTS", "A screen with this name already exists")
+			return
+		}
+		h.error(c, err)
+		return
+	}
+	response.Created(c, screen)
+}
+
+// DeleteScreen handles DELETE /api/screener/screens/:id
+func (h *ScreenerHandler) DeleteScreen(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid screen id")
+		return
+	}
+	userID := middleware.GetUserID(c)
+	if err := h.screener.DeleteScreen(c.Request.Context(), userID, id); err != nil {
+		response.InternalError(c, "Failed to delete screen")
+		return
+	}
+	response.Success(c, gin.H{"message": "removed"})
+}
+
+func (h *ScreenerHandler) error(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidFilter):
+		response.ErrorResponse(c, http.StatusBadRequest, "INVALID_FILTER", err.Error())
+	case errors.Is(err, services.ErrInvalidUniverse):
+		response.BadRequest(c, "universe must be watched or an index id (e.g. sp500)")
+	case errors.Is(err, services.ErrScreenNotFound):
+		response.NotFound(c, "Screen not found")
+	default:
+		response.InternalError(c, "Screener failed")
+	}
+}
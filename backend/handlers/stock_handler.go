@@ -51,6 +51,21 @@ func (h *StockHandler) GetHistory(c *gin.Context) {
 	response.Success(c, gin.H{"symbol": symbol, "history": history})
 }
 
+// GetDividends handles GET /api/dividends/:symbol?range=5y
+func (h *StockHandler) GetDividends(c *gin.Context) {
+	symbol := strings.ToUpper(strings.TrimSpace(c.Param("symbol")))
+	if symbol == "" {
+		response.BadRequest(c, "Symbol is required")
+		return
+	}
+	events, err := h.stock.GetDividends(c.Request.Context(), symbol, c.DefaultQuery("range", "5y"))
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+	response.Success(c, gin.H{"symbol": symbol, "dividends": events})
+}
+
 // Search handles GET /api/search
 func (h *StockHandler) Search(c *gin.Context) {
 	query := strings.TrimSpace(c.Query("q"))
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"tinystock/backend/exporter"
+	"tinystock/backend/internal/response"
+	"tinystock/backend/middleware"
+	"tinystock/backend/services"
+)
+
+// TaxHandler handles capital gains tax reports (requires auth)
+type TaxHandler struct {
+	tax *services.TaxService
+}
+
+// NewTaxHandler creates a new TaxHandler
+func NewTaxHandler(tax *services.TaxService) *TaxHandler {
+	return &TaxHandler{tax: tax}
+}
+
+// Report handles GET /api/portfolio/tax-report and GET /api/portfolios/:pid/tax-report
+// ?jurisdiction=US|IN&year=2024&method=fifo&format=json|csv|html. year is the
+// calendar year the fiscal year starts in (2024 is FY 2024-25 in India).
+func (h *TaxHandler) Report(c *gin.Context) {
+	pid, ok := portfolioID(c)
+	if !ok {
+		return
+	}
+	year := 0
+	if q := c.Query("year"); q != "" {
+		y, err := strconv.Atoi(q)
+		if err != nil {
+			response.BadRequest(c, "Invalid year")
+			return
+		}
+		year = y
+	}
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" && format != "html" {
+		response.BadRequest(c, "format must be json, csv or html")
+		return
+	}
+	report, err := h.tax.Report(c.Request.Context(), middleware.GetUserID(c), pid, c.Query("jurisdiction"), year, c.Query("method"))
+	if err != nil {
+		portfolioError(c, err, "Failed to build tax report")
+		return
+	}
+	switch format {
+	case "csv":
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="capital-gains-%s-%d.csv"`, report.Jurisdiction, report.FiscalYear))
+		c.Status(http.StatusOK)
+		exporter.WriteTaxCSV(c.Writer, report)
+	case "html":
+		c.Header("Content-Type", "text/html; charset=utf-8")
+		c.Status(http.StatusOK)
+		exporter.WriteTaxHTML(c.Writer, report)
+	default:
+		response.Success(c, report)
+	}
+}
@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"tinystock/backend/internal/decimal"
+	"tinystock/backend/internal/response"
+	"tinystock/backend/ledger"
+	"tinystock/backend/middleware"
+	"tinystock/backend/models"
+	"tinystock/backend/services"
+)
+
+// TransactionHandler handles ledger endpoints (requires auth)
+type TransactionHandler struct {
+	portfolio *services.PortfolioService
+}
+
+// NewTransactionHandler creates a new TransactionHandler
+func NewTransactionHandler(portfolio *services.PortfolioService) *TransactionHandler {
+	return &TransactionHandler{portfolio: portfolio}
+}
+
+// List handles GET /api/transactions (all portfolios) and GET /api/portfolios/:pid/transactions?symbol=AAPL
+func (h *TransactionHandler) List(c *gin.Context) {
+	pid, ok := portfolioID(c)
+	if !ok {
+		return
+	}
+	userID := middleware.GetUserID(c)
+	txs, err := h.portfolio.ListTransactions(c.Request.Context(), userID, pid, c.Query("symbol"))
+	if err != nil {
+		transactionError(c, err)
+		return
+	}
+	response.Success(c, txs)
+}
+
+// Create handles POST /api/transactions and POST /api/portfolios/:pid/transactions;
+// the former records into body.portfolioId or the default portfolio
+func (h *TransactionHandler) Create(c *gin.Context) {
+	pid, ok := portfolioID(c)
+	if !ok {
+		return
+	}
+	var req struct {
+		PortfolioID int64           `json:"portfolioId"`
+		Symbol      string          `json:"symbol"`
+		Type        string          `json:"type"`
+		Quantity    decimal.Decimal `json:"quantity"`
+		Price       decimal.Decimal `json:"price"`
+		Amount      decimal.Decimal `json:"amount"`
+		Currency    string          `json:"currency"`
+		TradeDate   string          `json:"tradeDate"`
+		Note        string          `json:"note"`
+		models.Charges
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid transaction")
+		return
+	}
+	if pid == 0 {
+		pid = req.PortfolioID
+	}
+	tx := &models.Transaction{
+		UserID:      middleware.GetUserID(c),
+		PortfolioID: pid,
+		Symbol:      req.Symbol,
+		Type:        req.Type,
+		Quantity:    req.Quantity,
+		Price:       req.Price,
+		Amount:      req.Amount,
+		Currency:    req.Currency,
+		TradeDate:   req.TradeDate,
+		Note:        req.Note,
+		Charges:     req.Charges,
+	}
+	if err := h.portfolio.RecordTransaction(c.Request.Context(), tx); err != nil {
+		transactionError(c, err)
+		return
+	}
+	response.Created(c, tx)
+}
+
+// Delete handles DELETE /api/transactions/:id and DELETE /api/portfolios/:pid/transactions/:id
+func (h *TransactionHandler) Delete(c *gin.Context) {
+	pid, id, ok := transactionID(c)
+	if !ok {
+		return
+	}
+	userID := middleware.GetUserID(c)
+	if err := h.portfolio.DeleteTransaction(c.Request.Context(), userID, pid, id); err != nil {
+		transactionError(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "deleted"})
+}
+
+// Get handles GET /api/transactions/:id and GET /api/portfolios/:pid/transactions/:id;
+// the ETag is the transaction's version, for use in If-Match
+func (h *TransactionHandler) Get(c *gin.Context) {
+	pid, id, ok := transactionID(c)
+	if !ok {
+		return
+	}
+	tx, err := h.portfolio.GetTransaction(c.Request.Context(), middleware.GetUserID(c), pid, id)
+	if err != nil {
+		transactionError(c, err)
+		return
+	}
+	setETag(c, tx.Version)
+	response.Success(c, tx)
+}
+
+// Update handles PATCH /api/transactions/:id and PATCH /api/portfolios/:pid/transactions/:id.
+// The body holds only the fields to change; an If-Match header or a version field
+// rejects the edit with 412 if the transaction changed in the meantime.
+func (h *TransactionHandler) Update(c *gin.Context) {
+	pid, id, ok := transactionID(c)
+	if !ok {
+		return
+	}
+	var patch services.TransactionPatch
+	if !decodePatch(c, &patch) || !ifMatch(c, &patch.Version) {
+		return
+	}
+	tx, err := h.portfolio.UpdateTransaction(c.Request.Context(), middleware.GetUserID(c), pid, id, patch)
+	if err != nil {
+		transactionError(c, err)
+		return
+	}
+	setETag(c, tx.Version)
+	response.Success(c, tx)
+}
+
+// History handles GET /api/transactions/:id/history and GET /api/portfolios/:pid/transactions/:id/history
+func (h *TransactionHandler) History(c *gin.Context) {
+	pid, id, ok := transactionID(c)
+	if !ok {
+		return
+	}
+	changes, err := h.portfolio.TransactionHistory(c.Request.Context(), middleware.GetUserID(c), pid, id)
+	if err != nil {
+		transactionError(c, err)
+		return
+	}
+	response.Success(c, changes)
+}
+
+// transactionID parses the optional :pid and the :id path parameters, writing a 400 on failure
+func transactionID(c *gin.Context) (int64, int64, bool) {
+	pid, ok := portfolioID(c)
+	if !ok {
+		return 0, 0, false
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		response.BadRequest(c, "Invalid transaction id")
+		return 0, 0, false
+	}
+	return pid, id, true
+}
+
+// decodePatch reads a partial update body, rejecting fields it does not know
+func decodePatch(c *gin.Context, v interface{}) bool {
+	dec := json.NewDecoder(c.Request.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		response.BadRequest(c, "Invalid patch: "+strings.TrimPrefix(err.Error(), "json: "))
+		return false
+	}
+	return true
+}
+
+// ifMatch merges an If-Match header into version, writing a 400 for a malformed one.
+// "*" is not accepted: an edit must name the version it was based on.
+func ifMatch(c *gin.Context, version *int64) bool {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" {
+		return true
+	}
+	v, err := strconv.ParseInt(strings.Trim(strings.TrimPrefix(header, "W/"), `"`), 10, 64)
+	if err != nil || v <= 0 {
+		response.BadRequest(c, "If-Match must be an ETag returned by this API")
+		return false
+	}
+	if *version != 0 && *version != v {
+		response.ErrorResponse(c, http.StatusPreconditionFailed, "VERSION_CONFLICT", "If-Match and version disagree")
+		return false
+	}
+	*version = v
+	return true
+}
+
+// setETag exposes a transaction's version as its ETag
+func setETag(c *gin.Context, version int64) {
+	c.Header("ETag", strconv.Quote(strconv.FormatInt(version, 10)))
+}
+
+// transactionError maps ledger and service errors to HTTP responses
+func transactionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ledger.ErrInvalidTransaction), errors.Is(err, services.ErrInvalidCurrency),
+		errors.Is(err, services.ErrInvalidSymbol), errors.Is(err, ledger.ErrInvalidMethod):
+		response.BadRequest(c, err.Error())
+	case errors.Is(err, ledger.ErrInsufficientQuantity):
+		response.ErrorResponse(c, http.StatusConflict, "INSUFFICIENT_QUANTITY", err.Error())
+	case errors.Is(err, ledger.ErrInsufficientCash):
+		response.ErrorResponse(c, http.StatusConflict, "INSUFFICIENT_CASH", err.Error())
+	case errors.Is(err, services.ErrVersionConflict):
+		response.ErrorResponse(c, http.StatusPreconditionFailed, "VERSION_CONFLICT", err.Error())
+	case errors.Is(err, services.ErrVersionRequired):
+		response.ErrorResponse(c, http.StatusPreconditionRequired, "PRECONDITION_REQUIRED", err.Error())
+	case errors.Is(err, services.ErrTransactionNotFound):
+		response.NotFound(c, "Transaction not found")
+	case errors.Is(err, services.ErrPortfolioNotFound):
+		response.NotFound(c, "Portfolio not found")
+	case errors.Is(err, services.ErrFeeScheduleNotFound):
+		response.NotFound(c, "Fee schedule not found")
+	default:
+		// Anything else is a server fault; its text may carry database details
+		log.Printf("transaction: %v", err)
+		response.InternalError(c, "Failed to process transaction")
+	}
+}
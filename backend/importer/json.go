@@ -0,0 +1,57 @@
+package importer
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"tinystock/backend/models"
+)
+
+// JSON is the profile that reads TinyStock's own versioned JSON export
+const JSON = "json"
+
+// IsJSON reports whether a buffered statement starts with a JSON object
+func IsJSON(br *bufio.Reader) bool {
+	for i := 1; ; i++ {
+		b, err := br.Peek(i)
+		if err != nil {
+			return false
+		}
+		switch c := b[i-1]; c {
+		case ' ', '\t', '\r', '\n', 0xef, 0xbb, 0xbf:
+		default:
+			return c == '{'
+		}
+	}
+}
+
+// ParseJSON reads a transactions export. Every field but the ID, portfolio and
+// creation time carries over; rows are numbered from 1 in export order.
+func ParseJSON(r io.Reader) (*models.ImportResult, error) {
+	br := bufio.NewReader(r)
+	if bom, _ := br.Peek(3); string(bom) == "\xef\xbb\xbf" {
+		br.Discard(3)
+	}
+	var doc models.TransactionExport
+	if err := json.NewDecoder(br).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %v", err)
+	}
+	if doc.Format != models.ExportFormat {
+		return nil, fmt.Errorf("not a %s export", models.ExportFormat)
+	}
+	if doc.Version < 1 || doc.Version > models.ExportVersion {
+		return nil, fmt.Errorf("unsupported export version %d (up to %d is supported)", doc.Version, models.ExportVersion)
+	}
+	if doc.What != models.ExportTransactions {
+		return nil, fmt.Errorf("only transaction exports can be imported; holdings are derived from them")
+	}
+	result := &models.ImportResult{Profile: JSON, Rows: []models.ImportRow{}, Errors: []models.ImportError{}}
+	for i, t := range doc.Transactions {
+		t.ID, t.PortfolioID, t.CreatedAt = 0, 0, time.Time{}
+		result.Rows = append(result.Rows, models.ImportRow{Row: i + 1, Transaction: t})
+	}
+	return result, nil
+}
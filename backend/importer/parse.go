@@ -0,0 +1,250 @@
+package importer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"tinystock/backend/internal/decimal"
+	"tinystock/backend/models"
+)
+
+// Parse reads a CSV statement laid out as p, or a JSON export for the json profile.
+// The header is the first row that has the profile's required columns; rows before
+// it are ignored. Each data row becomes a transaction or a row-level error, and
+// blank, summary, filtered and ignored rows are counted as skipped. Transactions
+// are not validated against the ledger.
+func Parse(r io.Reader, p Profile) (*models.ImportResult, error) {
+	if p.Name == JSON {
+		return ParseJSON(r)
+	}
+	br := bufio.NewReader(r)
+	if bom, _ := br.Peek(3); string(bom) == "\xef\xbb\xbf" {
+		br.Discard(3)
+	}
+	cr := csv.NewReader(br)
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+	cr.TrimLeadingSpace = true
+
+	result := &models.ImportResult{Profile: p.Name, Rows: []models.ImportRow{}, Errors: []models.ImportError{}}
+	var header map[string]int
+	var filter map[int]string
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var perr *csv.ParseError
+			if errors.As(err, &perr) {
+				return nil, fmt.Errorf("line %d: %v", perr.Line, perr.Err)
+			}
+			return nil, err
+		}
+		line, _ := cr.FieldPos(0)
+
+		if p.Section != "" {
+			if len(record) < 2 || !strings.EqualFold(strings.TrimSpace(record[0]), p.Section) {
+				continue
+			}
+			kind := strings.TrimSpace(record[1])
+			record = record[2:]
+			// Statements repeat the header for each asset class
+			if strings.EqualFold(kind, "Header") {
+				if h := p.header(record); h != nil {
+					header, filter = h, p.filter(record)
+				}
+				continue
+			}
+			if !strings.EqualFold(kind, "Data") {
+				if header != nil {
+					result.Skipped++
+				}
+				continue
+			}
+		}
+		if header == nil {
+			if p.Section == "" {
+				if h := p.header(record); h != nil {
+					header, filter = h, p.filter(record)
+				}
+			}
+			continue
+		}
+
+		row, skip, err := p.row(record, header, filter)
+		switch {
+		case err != nil:
+			result.Errors = append(result.Errors, models.ImportError{Row: line, Message: err.Error()})
+		case skip:
+			result.Skipped++
+		default:
+			result.Rows = append(result.Rows, models.ImportRow{Row: line, Transaction: row})
+		}
+	}
+	if header == nil {
+		var want []string
+		for _, f := range p.required() {
+			want = append(want, fmt.Sprintf("%s (%s)", f, strings.Join(p.Columns[f], " or ")))
+		}
+		return nil, fmt.Errorf("no header row with the %s columns %s", p.Name, strings.Join(want, ", "))
+	}
+	return result, nil
+}
+
+// header maps fields to column indexes, or returns nil if a required field is missing
+func (p Profile) header(record []string) map[string]int {
+	index := make(map[string]int)
+	for f, names := range p.Columns {
+		for i, cell := range record {
+			if matchesAny(cell, names) {
+				index[f] = i
+				break
+			}
+		}
+	}
+	for _, f := range p.required() {
+		if _, ok := index[f]; !ok {
+			return nil
+		}
+	}
+	return index
+}
+
+// filter maps column indexes to the values the profile requires in them
+func (p Profile) filter(record []string) map[int]string {
+	filter := make(map[int]string)
+	for name, want := range p.Filter {
+		for i, cell := range record {
+			if matchesAny(cell, []string{name}) {
+				filter[i] = want
+			}
+		}
+	}
+	return filter
+}
+
+func matchesAny(cell string, names []string) bool {
+	cell = strings.TrimSpace(cell)
+	for _, n := range names {
+		if strings.EqualFold(cell, n) {
+			return true
+		}
+	}
+	return false
+}
+
+// row converts one data row; skip is set for rows that carry no transaction
+func (p Profile) row(record []string, header map[string]int, filter map[int]string) (tx models.Transaction, skip bool, err error) {
+	cell := func(f string) string {
+		i, ok := header[f]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+	for i, want := range filter {
+		if i >= len(record) || !strings.EqualFold(strings.TrimSpace(record[i]), want) {
+			return tx, true, nil
+		}
+	}
+	date, kind, symbol := cell(FieldDate), cell(FieldType), cell(FieldSymbol)
+	// Footers, disclaimers and blank lines
+	if date == "" && kind == "" && symbol == "" {
+		return tx, true, nil
+	}
+
+	tx.TradeDate, err = p.parseDate(date)
+	if err != nil {
+		return tx, false, err
+	}
+	numbers := map[string]*decimal.Decimal{
+		FieldQuantity: &tx.Quantity, FieldPrice: &tx.Price, FieldAmount: &tx.Amount,
+		FieldFee: &tx.Fee, FieldCommission: &tx.Commission, FieldTax: &tx.Tax,
+	}
+	for _, f := range fields {
+		dst, ok := numbers[f]
+		if !ok {
+			continue
+		}
+		if *dst, err = parseNumber(cell(f)); err != nil {
+			return tx, false, fmt.Errorf("invalid %s %q", f, cell(f))
+		}
+	}
+
+	_, hasType := header[FieldType]
+	switch {
+	case hasType:
+		t, ok := p.Types[strings.ToLower(kind)]
+		if !ok {
+			return tx, false, fmt.Errorf("unsupported type %q", kind)
+		}
+		if t == "" {
+			return tx, true, nil
+		}
+		tx.Type = t
+	case tx.Quantity.Sign() < 0:
+		tx.Type = models.TxSell
+	default:
+		tx.Type = models.TxBuy
+	}
+	// Statements sign cash movements; the ledger takes magnitudes
+	if tx.Type == models.TxDeposit && tx.Amount.Sign() < 0 {
+		tx.Type = models.TxWithdrawal
+	}
+	for _, dst := range numbers {
+		*dst = dst.Abs()
+	}
+	if tx.Type == models.TxBuy || tx.Type == models.TxSell {
+		tx.Amount = decimal.Zero
+	}
+
+	if tx.Type != models.TxDeposit && tx.Type != models.TxWithdrawal {
+		tx.Symbol = p.NormalizeSymbol(symbol, cell(FieldExchange))
+	}
+	tx.Currency = strings.ToUpper(cell(FieldCurrency))
+	if tx.Currency == "" {
+		tx.Currency = p.Currency
+	}
+	tx.Note = cell(FieldNote)
+	if ref := cell(FieldReference); ref != "" {
+		tx.Note = strings.TrimSpace(tx.Note + " " + p.Name + " ref " + ref)
+	}
+	return tx, false, nil
+}
+
+// parseDate returns a statement date as YYYY-MM-DD
+func (p Profile) parseDate(s string) (string, error) {
+	for _, layout := range p.DateLayouts {
+		if d, err := time.Parse(layout, s); err == nil {
+			return d.Format("2006-01-02"), nil
+		}
+	}
+	return "", fmt.Errorf("unrecognized date %q", s)
+}
+
+// parseNumber reads amounts written with currency symbols, thousands separators
+// or accounting parentheses; an empty cell is zero
+func parseNumber(s string) (decimal.Decimal, error) {
+	s = strings.NewReplacer("$", "", "₹", "", "€", "", "£", "", ",", "", " ", "").Replace(s)
+	if s == "" || s == "-" || s == "--" {
+		return decimal.Zero, nil
+	}
+	neg := false
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		neg, s = true, s[1:len(s)-1]
+	}
+	v, err := decimal.Parse(s)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("invalid number %q", s)
+	}
+	if neg {
+		v = v.Neg()
+	}
+	return v, nil
+}
@@ -0,0 +1,233 @@
+// Package importer parses broker CSV exports into ledger transactions.
+package importer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"tinystock/backend/models"
+)
+
+// Fields a profile maps statement columns to
+const (
+	FieldDate       = "date"
+	FieldType       = "type"
+	FieldSymbol     = "symbol"
+	FieldExchange   = "exchange"
+	FieldQuantity   = "quantity"
+	FieldPrice      = "price"
+	FieldAmount     = "amount"
+	FieldCurrency   = "currency"
+	FieldFee        = "fee"
+	FieldCommission = "commission"
+	FieldTax        = "tax"
+	FieldNote       = "note"
+	FieldReference  = "reference"
+)
+
+var fields = []string{
+	FieldDate, FieldType, FieldSymbol, FieldExchange, FieldQuantity, FieldPrice, FieldAmount,
+	FieldCurrency, FieldFee, FieldCommission, FieldTax, FieldNote, FieldReference,
+}
+
+// Profile describes one broker's export layout
+type Profile struct {
+	Name        string
+	Description string
+	// Columns lists the accepted headers per field, matched case-insensitively
+	Columns map[string][]string
+	// Section, when set, reads a multi-table statement: only rows whose first cell is
+	// Section count, with "Header" or "Data" in the second cell
+	Section string
+	// Filter skips data rows whose named columns hold other values
+	Filter map[string]string
+	// DateLayouts are tried in order
+	DateLayouts []string
+	// Types maps lower-case type cells to ledger types; an empty type skips the row
+	Types map[string]string
+	// SignedQuantity reads a negative quantity as a sell when there is no type column
+	SignedQuantity bool
+	// Currency is used when the statement has no currency column
+	Currency string
+	// Exchanges maps exchange cells to the provider's symbol suffix
+	Exchanges map[string]string
+	// ShareClassDot reads "BRK.B" as share class B rather than an exchange suffix
+	ShareClassDot bool
+	// NewestFirst marks statements listing the latest activity first, so rows on
+	// the same date happened from the bottom up
+	NewestFirst bool
+}
+
+// isoDates are accepted by every profile
+var isoDates = []string{"2006-01-02", "2006-01-02 15:04:05", "2006-01-02T15:04:05Z07:00", "2006-01-02T15:04:05"}
+
+// generic types accept the ledger's own names and common synonyms
+var genericTypes = map[string]string{
+	"buy": models.TxBuy, "bought": models.TxBuy, "purchase": models.TxBuy, "b": models.TxBuy,
+	"sell": models.TxSell, "sold": models.TxSell, "sale": models.TxSell, "s": models.TxSell,
+	"dividend": models.TxDividend, "div": models.TxDividend,
+	"fee": models.TxFee, "split": models.TxSplit,
+	"transfer_in": models.TxTransferIn, "transfer in": models.TxTransferIn,
+	"transfer_out": models.TxTransferOut, "transfer out": models.TxTransferOut,
+	"deposit": models.TxDeposit, "withdrawal": models.TxWithdrawal, "withdraw": models.TxWithdrawal,
+}
+
+var profiles = map[string]Profile{
+	JSON: {
+		Name:        JSON,
+		Description: "TinyStock transactions export (GET /api/export?format=json&what=transactions)",
+	},
+	"generic": {
+		Name:        "generic",
+		Description: "One transaction per row with date, type, symbol, quantity, price and amount columns",
+		Columns: map[string][]string{
+			FieldDate:       {"date", "trade date", "trade_date", "tradedate"},
+			FieldType:       {"type", "action", "side", "transaction type"},
+			FieldSymbol:     {"symbol", "ticker"},
+			FieldExchange:   {"exchange"},
+			FieldQuantity:   {"quantity", "qty", "shares"},
+			FieldPrice:      {"price", "unit price"},
+			FieldAmount:     {"amount", "value", "total"},
+			FieldCurrency:   {"currency"},
+			FieldFee:        {"fee", "fees"},
+			FieldCommission: {"commission"},
+			FieldTax:        {"tax", "taxes"},
+			FieldNote:       {"note", "notes", "description"},
+		},
+		DateLayouts: append(append([]string{}, isoDates...), "2006/01/02", "01/02/2006", "1/2/2006", "02-Jan-2006", "Jan 2, 2006"),
+		Types:       genericTypes,
+	},
+	"zerodha": {
+		Name:        "zerodha",
+		Description: "Zerodha Console tradebook",
+		Columns: map[string][]string{
+			FieldDate:      {"trade_date"},
+			FieldType:      {"trade_type"},
+			FieldSymbol:    {"symbol"},
+			FieldExchange:  {"exchange"},
+			FieldQuantity:  {"quantity"},
+			FieldPrice:     {"price"},
+			FieldReference: {"trade_id"},
+		},
+		DateLayouts: append(append([]string{}, isoDates...), "02-01-2006", "02/01/2006"),
+		Types:       map[string]string{"buy": models.TxBuy, "sell": models.TxSell},
+		Currency:    "INR",
+		Exchanges:   map[string]string{"NSE": ".NS", "BSE": ".BO"},
+	},
+	"ibkr": {
+		Name:        "ibkr",
+		Description: "Interactive Brokers activity statement, Trades section (stocks)",
+		Columns: map[string][]string{
+			FieldDate:       {"date/time"},
+			FieldSymbol:     {"symbol"},
+			FieldQuantity:   {"quantity"},
+			FieldPrice:      {"t. price"},
+			FieldCurrency:   {"currency"},
+			FieldCommission: {"comm/fee", "comm in base"},
+		},
+		Section:        "Trades",
+		Filter:         map[string]string{"DataDiscriminator": "Order", "Asset Category": "Stocks"},
+		DateLayouts:    append([]string{"2006-01-02, 15:04:05", "2006-01-02,15:04:05", "20060102;150405"}, isoDates...),
+		SignedQuantity: true,
+		Currency:       "USD",
+	},
+	"robinhood": {
+		Name:        "robinhood",
+		Description: "Robinhood account activity report",
+		Columns: map[string][]string{
+			FieldDate:     {"activity date"},
+			FieldType:     {"trans code"},
+			FieldSymbol:   {"instrument"},
+			FieldQuantity: {"quantity"},
+			FieldPrice:    {"price"},
+			FieldAmount:   {"amount"},
+			FieldNote:     {"description"},
+		},
+		DateLayouts: append([]string{"1/2/2006", "01/02/2006"}, isoDates...),
+		Types: map[string]string{
+			"buy": models.TxBuy, "sell": models.TxSell, "cdiv": models.TxDividend,
+			"ach": models.TxDeposit, "gold": models.TxFee, "dtax": models.TxFee,
+			// Interest, stock lending and journal entries have no ledger equivalent
+			"int": "", "slip": "", "jnls": "",
+		},
+		Currency:      "USD",
+		ShareClassDot: true,
+		NewestFirst:   true,
+	},
+}
+
+// Lookup returns a built-in profile by name; an empty name is the generic profile
+func Lookup(name string) (Profile, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		name = "generic"
+	}
+	p, ok := profiles[name]
+	return p, ok
+}
+
+// Profiles returns the built-in profiles sorted by name
+func Profiles() []Profile {
+	list := make([]Profile, 0, len(profiles))
+	for _, p := range profiles {
+		list = append(list, p)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// WithMapping returns a copy of p reading each field in mapping from the named
+// column; an empty column stops reading the field
+func (p Profile) WithMapping(mapping map[string]string) (Profile, error) {
+	columns := make(map[string][]string, len(p.Columns))
+	for f, headers := range p.Columns {
+		columns[f] = headers
+	}
+	for f, header := range mapping {
+		f = strings.ToLower(strings.TrimSpace(f))
+		if !knownField(f) {
+			return p, fmt.Errorf("unknown field %q (want one of %s)", f, strings.Join(fields, ", "))
+		}
+		if header = strings.TrimSpace(header); header == "" {
+			delete(columns, f)
+		} else {
+			columns[f] = []string{header}
+		}
+	}
+	p.Columns = columns
+	return p, nil
+}
+
+func knownField(f string) bool {
+	for _, k := range fields {
+		if k == f {
+			return true
+		}
+	}
+	return false
+}
+
+// required lists the fields a header row must have
+func (p Profile) required() []string {
+	if p.SignedQuantity && len(p.Columns[FieldType]) == 0 {
+		return []string{FieldDate, FieldQuantity}
+	}
+	return []string{FieldDate, FieldType}
+}
+
+// NormalizeSymbol upper-cases a statement symbol, writes share classes with a dash
+// as the quote provider does (BRK B and, where the profile says so, BRK.B become
+// BRK-B) and appends the suffix of a mapped exchange
+func (p Profile) NormalizeSymbol(symbol, exchange string) string {
+	symbol = strings.Join(strings.Fields(strings.ToUpper(symbol)), "-")
+	if p.ShareClassDot {
+		if i := strings.LastIndex(symbol, "."); i > 0 && i == len(symbol)-2 {
+			symbol = symbol[:i] + "-" + symbol[i+1:]
+		}
+	}
+	if suffix := p.Exchanges[strings.ToUpper(strings.TrimSpace(exchange))]; suffix != "" && symbol != "" && !strings.Contains(symbol, ".") {
+		symbol += suffix
+	}
+	return symbol
+}
@@ -0,0 +1,81 @@
+package indicators
+
+// Engine runs a set of indicators over the same bar stream
+type Engine struct {
+	indicators []Indicator
+	last       Bar
+	bars       int
+}
+
+// NewEngine creates an engine with a fresh indicator per spec
+func NewEngine(specs []Spec) (*Engine, error) {
+	e := &Engine{}
+	for _, spec := range specs {
+		ind, err := New(spec)
+		if err != nil {
+			return nil, err
+		}
+		e.indicators = append(e.indicators, ind)
+	}
+	return e, nil
+}
+
+// Update feeds one bar to every indicator and returns the points that are ready,
+// keyed by indicator name. Bars at or before the last seen bar are ignored, so a
+// refreshed history can be replayed into a long-lived engine.
+func (e *Engine) Update(b Bar) map[string]Point {
+	if e.bars > 0 && !after(b, e.last) {
+		return nil
+	}
+	e.last = b
+	e.bars++
+
+	out := make(map[string]Point, len(e.indicators))
+	for _, ind := range e.indicators {
+		values, ok := ind.Update(b)
+		if !ok {
+			continue
+		}
+		p := Point{Date: b.Date, Time: b.Time, Values: make(map[string]float64, len(values))}
+		for i, name := range ind.Outputs() {
+			p.Values[name] = values[i]
+		}
+		out[ind.Name()] = p
+	}
+	return out
+}
+
+// Names returns the canonical names of the engine's indicators in spec order
+func (e *Engine) Names() []string {
+	names := make([]string, len(e.indicators))
+	for i, ind := range e.indicators {
+		names[i] = ind.Name()
+	}
+	return names
+}
+
+// Compute runs specs over bars and returns each indicator's full series
+func Compute(bars []Bar, specs []Spec) (map[string][]Point, error) {
+	e, err := NewEngine(specs)
+	if err != nil {
+		return nil, err
+	}
+	series := make(map[string][]Point, len(specs))
+	for _, name := range e.Names() {
+		series[name] = []Point{}
+	}
+	for _, b := range bars {
+		for name, p := range e.Update(b) {
+			series[name] = append(series[name], p)
+		}
+	}
+	return series, nil
+}
+
+// after reports whether b comes strictly after prev, preferring timestamps when both have them
+func after(b, prev Bar) bool {
+	if b.Time != 0 && prev.Time != 0 {
+		return b.Time > prev.Time
+	}
+	return b.Date > prev.Date
+}
@@ -0,0 +1,182 @@
+// Package indicators computes technical indicators over OHLCV bars.
+//
+// Every indicator is a streaming state machine: feed bars in chronological
+// order with Update and it returns a value once enough bars have been seen.
+// The same instance can keep receiving new bars, so a series computed once
+// can be extended incrementally without recomputing history.
+package indicators
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"tinystock/backend/models"
+)
+
+// Bar is one OHLCV observation
+type Bar struct {
+	Date   string
+	Time   int64
+	Open   float64
+	High   float64
+	Low    float64
+	Close  float64
+	Volume float64
+}
+
+// Indicator is a streaming technical indicator
+type Indicator interface {
+	// Name is the canonical spec, e.g. "rsi:14"
+	Name() string
+	// Outputs names the values returned by Update, e.g. ["macd", "signal", "histogram"]
+	Outputs() []string
+	// Update consumes the next bar; ok is false while the indicator is warming up
+	Update(b Bar) (values []float64, ok bool)
+}
+
+// Point is an indicator's output at one bar, keyed by output name
+type Point struct {
+	Date   string             `json:"date"`
+	Time   int64              `json:"time,omitempty"`
+	Values map[string]float64 `json:"values"`
+}
+
+// Spec identifies an indicator and its parameters, e.g. {Kind: "macd", Params: [12 26 9]}
+type Spec struct {
+	Kind   string
+	Params []float64
+}
+
+// String returns the canonical form of the spec, e.g. "bb:20:2"
+func (s Spec) String() string {
+	parts := []string{s.Kind}
+	for _, p := range s.Params {
+		parts = append(parts, strconv.FormatFloat(p, 'f', -1, 64))
+	}
+	return strings.Join(parts, ":")
+}
+
+// MaxPeriod is the longest lookback accepted in a spec; indicators buffer a
+// period's worth of values, so the bound keeps requests from sizing huge windows
+const MaxPeriod = 500
+
+// defaults holds the parameters used when a spec omits them
+var defaults = map[string][]float64{
+	"sma":  {20},
+	"ema":  {20},
+	"rsi":  {14},
+	"macd": {12, 26, 9},
+	"bb":   {20, 2},
+	"atr":  {14},
+	"vwap": {},
+	"obv":  {},
+}
+
+// ParseSet parses a comma-separated list such as "rsi:14,sma:50,macd:12:26:9"
+func ParseSet(set string) ([]Spec, error) {
+	var specs []Spec
+	seen := make(map[string]bool)
+	for _, item := range strings.Split(set, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		spec, err := ParseSpec(item)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[spec.String()] {
+			seen[spec.String()] = true
+			specs = append(specs, spec)
+		}
+	}
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("no indicators requested")
+	}
+	return specs, nil
+}
+
+// ParseSpec parses a single indicator such as "bb:20:2"; omitted parameters take defaults
+func ParseSpec(s string) (Spec, error) {
+	parts := strings.Split(strings.ToLower(strings.TrimSpace(s)), ":")
+	kind := parts[0]
+	def, ok := defaults[kind]
+	if !ok {
+		return Spec{}, fmt.Errorf("unknown indicator %q", kind)
+	}
+	if len(parts)-1 > len(def) {
+		return Spec{}, fmt.Errorf("%s takes at most %d parameters", kind, len(def))
+	}
+	params := append([]float64(nil), def...)
+	for i, p := range parts[1:] {
+		v, err := strconv.ParseFloat(p, 64)
+		if err != nil || v <= 0 {
+			return Spec{}, fmt.Errorf("invalid %s parameter %q", kind, p)
+		}
+		params[i] = v
+	}
+	for i, p := range params {
+		// Only Bollinger's width multiplier may be fractional; periods are whole bars
+		if kind == "bb" && i == 1 {
+			continue
+		}
+		if p > MaxPeriod {
+			return Spec{}, fmt.Errorf("%s period must be at most %d", kind, MaxPeriod)
+		}
+		if p != float64(int(p)) {
+			return Spec{}, fmt.Errorf("%s period must be a whole number", kind)
+		}
+	}
+	if kind == "macd" && params[0] >= params[1] {
+		return Spec{}, fmt.Errorf("macd fast period must be shorter than slow period")
+	}
+	return Spec{Kind: kind, Params: params}, nil
+}
+
+// New creates a fresh indicator for spec
+func New(spec Spec) (Indicator, error) {
+	p := spec.Params
+	switch spec.Kind {
+	case "sma":
+		return NewSMA(int(p[0])), nil
+	case "ema":
+		return NewEMA(int(p[0])), nil
+	case "rsi":
+		return NewRSI(int(p[0])), nil
+	case "macd":
+		return NewMACD(int(p[0]), int(p[1]), int(p[2])), nil
+	case "bb":
+		return NewBollinger(int(p[0]), p[1]), nil
+	case "atr":
+		return NewATR(int(p[0])), nil
+	case "vwap":
+		return NewVWAP(), nil
+	case "obv":
+		return NewOBV(), nil
+	}
+	return nil, fmt.Errorf("unknown indicator %q", spec.Kind)
+}
+
+// FromHistory converts provider history to bars, dropping points without a close
+func FromHistory(points []models.HistoryPoint) []Bar {
+	bars := make([]Bar, 0, len(points))
+	for _, p := range points {
+		if p.Close <= 0 {
+			continue
+		}
+		b := Bar{Date: p.Date, Time: p.Time, Open: p.Open, High: p.High, Low: p.Low, Close: p.Close, Volume: float64(p.Volume)}
+		// Close-only history still supports range-based indicators as zero-range bars
+		if b.High <= 0 {
+			b.High = b.Close
+		}
+		if b.Low <= 0 {
+			b.Low = b.Close
+		}
+		if b.Open <= 0 {
+			b.Open = b.Close
+		}
+		bars = append(bars, b)
+	}
+	return bars
+}
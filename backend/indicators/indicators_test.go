@@ -0,0 +1,254 @@
+package indicators
+
+import (
+	"math"
+	"testing"
+)
+
+// closes builds close-only bars with increasing timestamps
+func closes(values ...float64) []Bar {
+	bars := make([]Bar, len(values))
+	for i, v := range values {
+		bars[i] = Bar{Time: int64(i + 1), High: v, Low: v, Close: v}
+	}
+	return bars
+}
+
+// run feeds bars to ind and returns output index of every ready value
+func run(ind Indicator, bars []Bar, output int) []float64 {
+	var out []float64
+	for _, b := range bars {
+		if values, ok := ind.Update(b); ok {
+			out = append(out, values[output])
+		}
+	}
+	return out
+}
+
+// StockCharts' moving average worksheet: 30 closes, 10-day SMA and EMA
+var stockChartsCloses = closes(
+	22.27, 22.19, 22.08, 22.17, 22.18, 22.13, 22.23, 22.43, 22.24, 22.29,
+	22.15, 22.39, 22.38, 22.61, 23.36, 24.05, 23.75, 23.83, 23.95, 23.63,
+	23.82, 23.87, 23.65, 23.19, 23.10, 23.33, 22.68, 23.10, 22.40, 22.17,
+)
+
+// StockCharts' RSI worksheet: 33 closes, 14-period RSI
+var wilderCloses = closes(
+	44.34, 44.09, 44.15, 43.61, 44.33, 44.83, 45.10, 45.42, 45.84, 46.08,
+	45.89, 46.03, 45.61, 46.28, 46.28, 46.00, 46.03, 46.41, 46.22, 45.64,
+	46.21, 46.25, 45.71, 46.45, 45.78, 45.35, 44.03, 44.18, 44.22, 44.57,
+	43.42, 42.66, 43.13,
+)
+
+// linear closes 1, 2, ..., n: every EMA seeded with an SMA lags by exactly
+// (period-1)/2, so MACD is (slow-fast)/2 and the histogram is zero
+func linear(n int) []Bar {
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = float64(i + 1)
+	}
+	return closes(values...)
+}
+
+func TestIndicators(t *testing.T) {
+	tests := []struct {
+		name   string
+		ind    Indicator
+		bars   []Bar
+		output int
+		want   []float64
+		tol    float64
+	}{
+		{
+			name: "sma 10", ind: NewSMA(10), bars: stockChartsCloses, tol: 0.01,
+			want: []float64{
+				22.22, 22.21, 22.23, 22.26, 22.30, 22.42, 22.61, 22.77, 22.91, 23.08, 23.21,
+				23.38, 23.52, 23.65, 23.71, 23.68, 23.61, 23.51, 23.43, 23.28, 23.13,
+			},
+		},
+		{
+			name: "ema 10", ind: NewEMA(10), bars: stockChartsCloses, tol: 0.01,
+			want: []float64{
+				22.22, 22.21, 22.24, 22.27, 22.33, 22.52, 22.80, 22.97, 23.13, 23.28, 23.34,
+				23.43, 23.51, 23.53, 23.47, 23.40, 23.39, 23.26, 23.23, 23.08, 22.92,
+			},
+		},
+		{
+			// StockCharts rounds the running averages to two places, so its
+			// published values sit up to 0.07 above the unrounded series
+			name: "rsi 14", ind: NewRSI(14), bars: wilderCloses, tol: 0.1,
+			want: []float64{
+				70.53, 66.32, 66.55, 69.41, 66.36, 57.97, 62.93, 63.26, 56.06, 62.38,
+				54.71, 50.42, 39.99, 41.46, 41.87, 45.46, 37.30, 33.08, 37.77,
+			},
+		},
+		{
+			name: "rsi flat", ind: NewRSI(3), bars: closes(5, 5, 5, 5, 5), tol: 1e-9,
+			want: []float64{50, 50},
+		},
+		{
+			name: "rsi only gains", ind: NewRSI(2), bars: closes(1, 2, 3, 4), tol: 1e-9,
+			want: []float64{100, 100},
+		},
+		{
+			name: "macd line", ind: NewMACD(12, 26, 9), bars: linear(36), output: 0, tol: 1e-9,
+			want: []float64{7, 7, 7},
+		},
+		{
+			name: "macd signal", ind: NewMACD(12, 26, 9), bars: linear(36), output: 1, tol: 1e-9,
+			want: []float64{7, 7, 7},
+		},
+		{
+			name: "macd histogram", ind: NewMACD(12, 26, 9), bars: linear(36), output: 2, tol: 1e-9,
+			want: []float64{0, 0, 0},
+		},
+		{
+			// The textbook population standard deviation example: mean 5, deviation 2
+			name: "bollinger upper", ind: NewBollinger(8, 2), bars: closes(2, 4, 4, 4, 5, 5, 7, 9), output: 0, tol: 1e-9,
+			want: []float64{9},
+		},
+		{
+			name: "bollinger middle", ind: NewBollinger(8, 2), bars: closes(2, 4, 4, 4, 5, 5, 7, 9), output: 1, tol: 1e-9,
+			want: []float64{5},
+		},
+		{
+			name: "bollinger lower", ind: NewBollinger(8, 2), bars: closes(2, 4, 4, 4, 5, 5, 7, 9), output: 2, tol: 1e-9,
+			want: []float64{1},
+		},
+		{
+			// True ranges 2, 2, 3, 3, 4: seeded with their mean, then Wilder-smoothed
+			name: "atr 3", ind: NewATR(3), tol: 1e-9,
+			bars: []Bar{
+				{High: 10, Low: 8, Close: 9},
+				{High: 11, Low: 9, Close: 10},
+				{High: 12, Low: 9, Close: 11},
+				{High: 13, Low: 10, Close: 12},
+				{High: 11, Low: 8, Close: 9},
+			},
+			want: []float64{7.0 / 3, 23.0 / 9, 82.0 / 27},
+		},
+		{
+			// Typical prices 10 and 12 weighted 100 and 300; a zero-volume bar waits
+			name: "vwap", ind: NewVWAP(), tol: 1e-9,
+			bars: []Bar{
+				{High: 10, Low: 10, Close: 10},
+				{High: 12, Low: 9, Close: 9, Volume: 100},
+				{High: 13, Low: 11, Close: 12, Volume: 300},
+			},
+			want: []float64{10, 11.5},
+		},
+		{
+			name: "obv", ind: NewOBV(), tol: 1e-9,
+			bars: []Bar{
+				{Close: 10, Volume: 100},
+				{Close: 11, Volume: 200},
+				{Close: 11, Volume: 300},
+				{Close: 10.5, Volume: 400},
+				{Close: 12, Volume: 500},
+			},
+			want: []float64{0, 200, 200, -200, 300},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := run(tt.ind, tt.bars, tt.output)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d values %v, want %d", len(got), got, len(tt.want))
+			}
+			for i := range got {
+				if math.Abs(got[i]-tt.want[i]) > tt.tol {
+					t.Errorf("value %d = %.6f, want %.6f", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestIncrementalMatchesBatch(t *testing.T) {
+	specs, err := ParseSet("sma:10,ema:10,rsi:14,macd:3:6:2,bb:5:2,atr:5,vwap,obv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bars := append(append([]Bar(nil), wilderCloses...), stockChartsCloses...)
+	for i := range bars {
+		bars[i].Time = int64(i + 1)
+		bars[i].Volume = float64(100 + i*10)
+	}
+	batch, err := Compute(bars, specs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Feed the first half, then a refreshed history that repeats it; the engine
+	// must skip the repeated bars and continue where it left off
+	e, err := NewEngine(specs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	incremental := make(map[string][]Point)
+	half := len(bars) / 2
+	for _, chunk := range [][]Bar{bars[:half], bars} {
+		for _, b := range chunk {
+			for name, p := range e.Update(b) {
+				incremental[name] = append(incremental[name], p)
+			}
+		}
+	}
+
+	for name, want := range batch {
+		got := incremental[name]
+		if len(got) != len(want) {
+			t.Fatalf("%s: got %d points, want %d", name, len(got), len(want))
+		}
+		for i := range want {
+			if got[i].Time != want[i].Time {
+				t.Fatalf("%s point %d: time %d, want %d", name, i, got[i].Time, want[i].Time)
+			}
+			for k, v := range want[i].Values {
+				if got[i].Values[k] != v {
+					t.Errorf("%s point %d %s = %v, want %v", name, i, k, got[i].Values[k], v)
+				}
+			}
+		}
+	}
+}
+
+func TestParseSpec(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "sma", want: "sma:20"},
+		{in: "BB:20:2.5", want: "bb:20:2.5"},
+		{in: "macd:5", want: "macd:5:26:9"},
+		{in: "sma:500", want: "sma:500"},
+		{in: "sma:501", wantErr: true},
+		{in: "sma:1000000000", wantErr: true},
+		{in: "sma:1e20", wantErr: true},
+		{in: "macd:12:26:1e20", wantErr: true},
+		{in: "ema:2.5", wantErr: true},
+		{in: "rsi:0", wantErr: true},
+		{in: "rsi:-3", wantErr: true},
+		{in: "macd:26:12", wantErr: true},
+		{in: "obv:3", wantErr: true},
+		{in: "kdj", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			spec, err := ParseSpec(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseSpec(%q) = %s, want error", tt.in, spec)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseSpec(%q): %v", tt.in, err)
+			}
+			if spec.String() != tt.want {
+				t.Errorf("ParseSpec(%q) = %s, want %s", tt.in, spec, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package indicators
+
+import "fmt"
+
+// RSI is Wilder's relative strength index over Period price changes
+type RSI struct {
+	period  int
+	prev    float64
+	seen    int
+	avgGain float64
+	avgLoss float64
+}
+
+// NewRSI creates a relative strength index
+func NewRSI(period int) *RSI {
+	return &RSI{period: period}
+}
+
+func (r *RSI) Name() string      { return fmt.Sprintf("rsi:%d", r.period) }
+func (r *RSI) Outputs() []string { return []string{"rsi"} }
+
+// Update implements Indicator
+func (r *RSI) Update(b Bar) ([]float64, bool) {
+	r.seen++
+	if r.seen == 1 {
+		r.prev = b.Close
+		return []float64{0}, false
+	}
+	change := b.Close - r.prev
+	r.prev = b.Close
+	gain, loss := 0.0, 0.0
+	if change > 0 {
+		gain = change
+	} else {
+		loss = -change
+	}
+
+	n := float64(r.period)
+	changes := r.seen - 1
+	switch {
+	case changes < r.period:
+		// Accumulate the first Period changes for a simple-average seed
+		r.avgGain += gain
+		r.avgLoss += loss
+		return []float64{0}, false
+	case changes == r.period:
+		r.avgGain = (r.avgGain + gain) / n
+		r.avgLoss = (r.avgLoss + loss) / n
+	default:
+		r.avgGain = (r.avgGain*(n-1) + gain) / n
+		r.avgLoss = (r.avgLoss*(n-1) + loss) / n
+	}
+
+	if r.avgLoss == 0 {
+		if r.avgGain == 0 {
+			return []float64{50}, true
+		}
+		return []float64{100}, true
+	}
+	rs := r.avgGain / r.avgLoss
+	return []float64{100 - 100/(1+rs)}, true
+}
+
+// MACD is the difference between fast and slow EMAs of closes, with an EMA signal line
+type MACD struct {
+	fast, slow, signal int
+	fastEMA, slowEMA   *EMA
+	signalEMA          *EMA
+}
+
+// NewMACD creates a moving average convergence/divergence indicator
+func NewMACD(fast, slow, signal int) *MACD {
+	return &MACD{
+		fast: fast, slow: slow, signal: signal,
+		fastEMA:   NewEMA(fast),
+		slowEMA:   NewEMA(slow),
+		signalEMA: NewEMA(signal),
+	}
+}
+
+func (m *MACD) Name() string      { return fmt.Sprintf("macd:%d:%d:%d", m.fast, m.slow, m.signal) }
+func (m *MACD) Outputs() []string { return []string{"macd", "signal", "histogram"} }
+
+// Update implements Indicator
+func (m *MACD) Update(b Bar) ([]float64, bool) {
+	f, fOK := m.fastEMA.add(b.Close)
+	s, sOK := m.slowEMA.add(b.Close)
+	if !fOK || !sOK {
+		return []float64{0, 0, 0}, false
+	}
+	macd := f - s
+	sig, ok := m.signalEMA.add(macd)
+	if !ok {
+		return []float64{macd, 0, 0}, false
+	}
+	return []float64{macd, sig, macd - sig}, true
+}
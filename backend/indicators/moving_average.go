@@ -0,0 +1,78 @@
+package indicators
+
+import "fmt"
+
+// SMA is the simple moving average of closes over Period bars
+type SMA struct {
+	period int
+	window []float64
+	next   int
+	count  int
+	sum    float64
+}
+
+// NewSMA creates a simple moving average
+func NewSMA(period int) *SMA {
+	return &SMA{period: period, window: make([]float64, period)}
+}
+
+func (s *SMA) Name() string      { return fmt.Sprintf("sma:%d", s.period) }
+func (s *SMA) Outputs() []string { return []string{"sma"} }
+
+// Update implements Indicator
+func (s *SMA) Update(b Bar) ([]float64, bool) {
+	v, ok := s.add(b.Close)
+	return []float64{v}, ok
+}
+
+// add pushes a raw value, so SMA can also smooth derived series
+func (s *SMA) add(v float64) (float64, bool) {
+	if s.count == s.period {
+		s.sum -= s.window[s.next]
+	} else {
+		s.count++
+	}
+	s.window[s.next] = v
+	s.sum += v
+	s.next = (s.next + 1) % s.period
+	if s.count < s.period {
+		return 0, false
+	}
+	return s.sum / float64(s.period), true
+}
+
+// EMA is the exponential moving average of closes, seeded with the SMA of the first Period bars
+type EMA struct {
+	period int
+	alpha  float64
+	seed   *SMA
+	value  float64
+	ready  bool
+}
+
+// NewEMA creates an exponential moving average
+func NewEMA(period int) *EMA {
+	return &EMA{period: period, alpha: 2 / float64(period+1), seed: NewSMA(period)}
+}
+
+func (e *EMA) Name() string      { return fmt.Sprintf("ema:%d", e.period) }
+func (e *EMA) Outputs() []string { return []string{"ema"} }
+
+// Update implements Indicator
+func (e *EMA) Update(b Bar) ([]float64, bool) {
+	v, ok := e.add(b.Close)
+	return []float64{v}, ok
+}
+
+func (e *EMA) add(v float64) (float64, bool) {
+	if !e.ready {
+		seed, ok := e.seed.add(v)
+		if !ok {
+			return 0, false
+		}
+		e.value, e.ready = seed, true
+		return e.value, true
+	}
+	e.value += e.alpha * (v - e.value)
+	return e.value, true
+}
@@ -0,0 +1,90 @@
+package indicators
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Bollinger bands are an SMA of closes plus and minus K population standard deviations
+type Bollinger struct {
+	period int
+	k      float64
+	window []float64
+	next   int
+	count  int
+}
+
+// NewBollinger creates Bollinger bands
+func NewBollinger(period int, k float64) *Bollinger {
+	return &Bollinger{period: period, k: k, window: make([]float64, period)}
+}
+
+func (bb *Bollinger) Name() string {
+	return fmt.Sprintf("bb:%d:%s", bb.period, strconv.FormatFloat(bb.k, 'f', -1, 64))
+}
+func (bb *Bollinger) Outputs() []string { return []string{"upper", "middle", "lower"} }
+
+// Update implements Indicator
+func (bb *Bollinger) Update(b Bar) ([]float64, bool) {
+	bb.window[bb.next] = b.Close
+	bb.next = (bb.next + 1) % bb.period
+	if bb.count < bb.period {
+		bb.count++
+	}
+	if bb.count < bb.period {
+		return []float64{0, 0, 0}, false
+	}
+
+	// Recompute over the window rather than keeping running sums of squares,
+	// which lose precision on long streams of large prices
+	mean := 0.0
+	for _, v := range bb.window {
+		mean += v
+	}
+	mean /= float64(bb.period)
+	variance := 0.0
+	for _, v := range bb.window {
+		variance += (v - mean) * (v - mean)
+	}
+	sd := math.Sqrt(variance / float64(bb.period))
+	return []float64{mean + bb.k*sd, mean, mean - bb.k*sd}, true
+}
+
+// ATR is Wilder's average true range over Period bars
+type ATR struct {
+	period    int
+	prevClose float64
+	seen      int
+	value     float64
+}
+
+// NewATR creates an average true range
+func NewATR(period int) *ATR {
+	return &ATR{period: period}
+}
+
+func (a *ATR) Name() string      { return fmt.Sprintf("atr:%d", a.period) }
+func (a *ATR) Outputs() []string { return []string{"atr"} }
+
+// Update implements Indicator
+func (a *ATR) Update(b Bar) ([]float64, bool) {
+	tr := b.High - b.Low
+	if a.seen > 0 {
+		tr = math.Max(tr, math.Max(math.Abs(b.High-a.prevClose), math.Abs(b.Low-a.prevClose)))
+	}
+	a.prevClose = b.Close
+	a.seen++
+
+	n := float64(a.period)
+	switch {
+	case a.seen < a.period:
+		a.value += tr
+		return []float64{0}, false
+	case a.seen == a.period:
+		a.value = (a.value + tr) / n
+	default:
+		a.value = (a.value*(n-1) + tr) / n
+	}
+	return []float64{a.value}, true
+}
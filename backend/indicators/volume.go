@@ -0,0 +1,56 @@
+package indicators
+
+// VWAP is the volume-weighted average typical price, anchored at the first bar
+type VWAP struct {
+	pv     float64
+	volume float64
+}
+
+// NewVWAP creates an anchored volume-weighted average price
+func NewVWAP() *VWAP {
+	return &VWAP{}
+}
+
+func (v *VWAP) Name() string      { return "vwap" }
+func (v *VWAP) Outputs() []string { return []string{"vwap"} }
+
+// Update implements Indicator
+func (v *VWAP) Update(b Bar) ([]float64, bool) {
+	typical := (b.High + b.Low + b.Close) / 3
+	v.pv += typical * b.Volume
+	v.volume += b.Volume
+	if v.volume == 0 {
+		return []float64{0}, false
+	}
+	return []float64{v.pv / v.volume}, true
+}
+
+// OBV is on-balance volume: volume added on up closes and subtracted on down closes
+type OBV struct {
+	prevClose float64
+	started   bool
+	value     float64
+}
+
+// NewOBV creates an on-balance volume indicator
+func NewOBV() *OBV {
+	return &OBV{}
+}
+
+func (o *OBV) Name() string      { return "obv" }
+func (o *OBV) Outputs() []string { return []string{"obv"} }
+
+// Update implements Indicator
+func (o *OBV) Update(b Bar) ([]float64, bool) {
+	if o.started {
+		switch {
+		case b.Close > o.prevClose:
+			o.value += b.Volume
+		case b.Close < o.prevClose:
+			o.value -= b.Volume
+		}
+	}
+	o.started = true
+	o.prevClose = b.Close
+	return []float64{o.value}, true
+}
@@ -0,0 +1,28 @@
+package decimal
+
+import "strings"
+
+// Precision is the number of fractional digits kept when dividing, as in cost per
+// share; it matches the scale of the quantity columns
+const Precision = 10
+
+// minorUnits lists ISO 4217 currencies whose minor unit is not two digits
+var minorUnits = map[string]int32{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+}
+
+// CurrencyPlaces returns the digits after the point that amounts in currency are
+// reported with: 0 for JPY, 3 for KWD, 2 for most others and unknown codes
+func CurrencyPlaces(currency string) int32 {
+	if places, ok := minorUnits[strings.ToUpper(currency)]; ok {
+		return places
+	}
+	return 2
+}
+
+// RoundCurrency rounds d half to even to currency's minor unit
+func (d Decimal) RoundCurrency(currency string) Decimal {
+	return d.Round(CurrencyPlaces(currency))
+}
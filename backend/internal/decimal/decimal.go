@@ -0,0 +1,369 @@
+// Package decimal provides an exact base-10 number for money and share quantities.
+package decimal
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Decimal is coef × 10^-scale. The zero value is 0. Values are immutable: every
+// operation returns a new Decimal, so they can be copied and shared freely.
+type Decimal struct {
+	coef  *big.Int // nil means zero
+	scale int32    // digits after the point, never negative
+}
+
+// Zero is the zero Decimal
+var Zero = Decimal{}
+
+var ten = big.NewInt(10)
+
+// maxExponent bounds the exponent Parse accepts, so untrusted input such as
+// "1e999999999" cannot demand an enormous power of ten
+const maxExponent = 100
+
+// New returns coef × 10^-scale; a negative scale multiplies by a power of ten
+func New(coef int64, scale int32) Decimal {
+	d := Decimal{coef: big.NewInt(coef), scale: scale}
+	if scale < 0 {
+		d.coef.Mul(d.coef, pow10(-scale))
+		d.scale = 0
+	}
+	return d
+}
+
+// NewFromInt returns i as a Decimal
+func NewFromInt(i int64) Decimal {
+	return New(i, 0)
+}
+
+// NewFromFloat returns the shortest decimal that reads back as f, so 0.1 becomes
+// exactly 0.1. NaN and infinities become zero.
+func NewFromFloat(f float64) Decimal {
+	if math.IsNaN(f) || math.IsInf(f, 0) || f == 0 {
+		return Zero
+	}
+	d, _ := Parse(strconv.FormatFloat(f, 'g', -1, 64))
+	return d
+}
+
+// Parse reads a decimal such as "-1234.5600" or "1.5e3"
+func Parse(s string) (Decimal, error) {
+	text := strings.TrimSpace(s)
+	exp := int64(0)
+	if i := strings.IndexAny(text, "eE"); i >= 0 {
+		e, err := strconv.ParseInt(text[i+1:], 10, 32)
+		if err != nil || e > maxExponent || e < -maxExponent {
+			return Zero, fmt.Errorf("decimal: invalid exponent in %q", s)
+		}
+		exp, text = e, text[:i]
+	}
+	neg := strings.HasPrefix(text, "-")
+	text = strings.TrimPrefix(strings.TrimPrefix(text, "-"), "+")
+	whole, frac := text, ""
+	if i := strings.IndexByte(text, '.'); i >= 0 {
+		whole, frac = text[:i], text[i+1:]
+	}
+	digits := whole + frac
+	if digits == "" || strings.Trim(digits, "0123456789") != "" {
+		return Zero, fmt.Errorf("decimal: invalid number %q", s)
+	}
+	coef, _ := new(big.Int).SetString(digits, 10)
+	if neg {
+		coef.Neg(coef)
+	}
+	scale := int64(len(frac)) - exp
+	if scale > math.MaxInt32 {
+		return Zero, fmt.Errorf("decimal: too many digits in %q", s)
+	}
+	if scale < 0 {
+		coef.Mul(coef, pow10(int32(-scale)))
+		scale = 0
+	}
+	return Decimal{coef: coef, scale: int32(scale)}.normalize(), nil
+}
+
+// MustParse is Parse for constants; it panics on invalid input
+func MustParse(s string) Decimal {
+	d, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(ten, big.NewInt(int64(n)), nil)
+}
+
+// int returns the coefficient, treating nil as zero
+func (d Decimal) int() *big.Int {
+	if d.coef == nil {
+		return new(big.Int)
+	}
+	return d.coef
+}
+
+// rescaled returns the coefficient at a larger scale
+func (d Decimal) rescaled(scale int32) *big.Int {
+	c := new(big.Int).Set(d.int())
+	if scale > d.scale {
+		c.Mul(c, pow10(scale-d.scale))
+	}
+	return c
+}
+
+// normalize drops trailing fractional zeros
+func (d Decimal) normalize() Decimal {
+	if d.coef == nil || d.coef.Sign() == 0 {
+		return Zero
+	}
+	c, scale := new(big.Int).Set(d.coef), d.scale
+	q, r := new(big.Int), new(big.Int)
+	for scale > 0 {
+		q.QuoRem(c, ten, r)
+		if r.Sign() != 0 {
+			break
+		}
+		c.Set(q)
+		scale--
+	}
+	return Decimal{coef: c, scale: scale}
+}
+
+// Add returns d + e
+func (d Decimal) Add(e Decimal) Decimal {
+	scale := d.scale
+	if e.scale > scale {
+		scale = e.scale
+	}
+	return Decimal{coef: new(big.Int).Add(d.rescaled(scale), e.rescaled(scale)), scale: scale}.normalize()
+}
+
+// Sub returns d - e
+func (d Decimal) Sub(e Decimal) Decimal {
+	return d.Add(e.Neg())
+}
+
+// Mul returns d × e exactly
+func (d Decimal) Mul(e Decimal) Decimal {
+	return Decimal{coef: new(big.Int).Mul(d.int(), e.int()), scale: d.scale + e.scale}.normalize()
+}
+
+// Div returns d ÷ e rounded half to even at places digits after the point.
+// Dividing by zero returns zero.
+func (d Decimal) Div(e Decimal, places int32) Decimal {
+	if e.IsZero() {
+		return Zero
+	}
+	// d/e = (cd / ce) × 10^(se-sd), so shift the coefficients until the integer
+	// quotient carries exactly places digits
+	num := new(big.Int).Set(d.int())
+	den := new(big.Int).Set(e.int())
+	if shift := places + e.scale - d.scale; shift >= 0 {
+		num.Mul(num, pow10(shift))
+	} else {
+		den.Mul(den, pow10(-shift))
+	}
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+	if r.Sign() != 0 {
+		twice := new(big.Int).Abs(r)
+		twice.Mul(twice, big.NewInt(2))
+		if c := twice.Cmp(new(big.Int).Abs(den)); c > 0 || c == 0 && q.Bit(0) == 1 {
+			if num.Sign()*den.Sign() < 0 {
+				q.Sub(q, big.NewInt(1))
+			} else {
+				q.Add(q, big.NewInt(1))
+			}
+		}
+	}
+	return Decimal{coef: q, scale: places}.normalize()
+}
+
+// Round returns d rounded half to even at places digits after the point
+func (d Decimal) Round(places int32) Decimal {
+	if places < 0 || d.scale <= places || d.IsZero() {
+		return d
+	}
+	unit := pow10(d.scale - places)
+	q, r := new(big.Int).QuoRem(d.coef, unit, new(big.Int))
+	// Compare twice the remainder with the unit to find which half it is in
+	half := new(big.Int).Abs(r)
+	half.Mul(half, big.NewInt(2))
+	switch c := half.Cmp(unit); {
+	case c > 0, c == 0 && q.Bit(0) == 1:
+		if d.coef.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return Decimal{coef: q, scale: places}.normalize()
+}
+
+// Truncate drops digits beyond places after the point
+func (d Decimal) Truncate(places int32) Decimal {
+	if places < 0 || d.scale <= places || d.IsZero() {
+		return d
+	}
+	return Decimal{coef: new(big.Int).Quo(d.coef, pow10(d.scale-places)), scale: places}.normalize()
+}
+
+// Neg returns -d
+func (d Decimal) Neg() Decimal {
+	if d.IsZero() {
+		return Zero
+	}
+	return Decimal{coef: new(big.Int).Neg(d.coef), scale: d.scale}
+}
+
+// Abs returns |d|
+func (d Decimal) Abs() Decimal {
+	if d.Sign() < 0 {
+		return d.Neg()
+	}
+	return d
+}
+
+// Sign returns -1, 0 or 1
+func (d Decimal) Sign() int {
+	if d.coef == nil {
+		return 0
+	}
+	return d.coef.Sign()
+}
+
+// IsZero reports whether d is 0
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// Cmp returns -1, 0 or 1 as d is less than, equal to or greater than e
+func (d Decimal) Cmp(e Decimal) int {
+	scale := d.scale
+	if e.scale > scale {
+		scale = e.scale
+	}
+	return d.rescaled(scale).Cmp(e.rescaled(scale))
+}
+
+// Min returns the smaller of d and e
+func Min(d, e Decimal) Decimal {
+	if d.Cmp(e) <= 0 {
+		return d
+	}
+	return e
+}
+
+// Max returns the larger of d and e
+func Max(d, e Decimal) Decimal {
+	if d.Cmp(e) >= 0 {
+		return d
+	}
+	return e
+}
+
+// Sum adds ds
+func Sum(ds ...Decimal) Decimal {
+	total := Zero
+	for _, d := range ds {
+		total = total.Add(d)
+	}
+	return total
+}
+
+// Float64 returns the nearest float64, for statistics and other float APIs
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// String formats d without an exponent or trailing fractional zeros
+func (d Decimal) String() string {
+	d = d.normalize()
+	if d.scale == 0 {
+		return d.int().String()
+	}
+	return d.StringFixed(d.scale)
+}
+
+// StringFixed formats d rounded to exactly places digits after the point
+func (d Decimal) StringFixed(places int32) string {
+	if places < 0 {
+		places = 0
+	}
+	d = d.Round(places)
+	digits := d.rescaled(places)
+	neg := digits.Sign() < 0
+	s := digits.Abs(digits).String()
+	if places > 0 {
+		if len(s) <= int(places) {
+			s = strings.Repeat("0", int(places)-len(s)+1) + s
+		}
+		s = s[:len(s)-int(places)] + "." + s[len(s)-int(places):]
+	}
+	if neg {
+		s = "-" + s
+	}
+	return s
+}
+
+// MarshalJSON writes d as a JSON number with every significant digit
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON accepts a JSON number, a numeric string or null (zero)
+func (d *Decimal) UnmarshalJSON(b []byte) error {
+	s := string(b)
+	if s == "null" {
+		*d = Zero
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+	v, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// Scan implements sql.Scanner for NUMERIC, REAL, INTEGER and text columns
+func (d *Decimal) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*d = Zero
+	case int64:
+		*d = NewFromInt(v)
+	case float64:
+		*d = NewFromFloat(v)
+	case []byte:
+		return d.scanText(string(v))
+	case string:
+		return d.scanText(v)
+	default:
+		return fmt.Errorf("decimal: cannot scan %T", src)
+	}
+	return nil
+}
+
+func (d *Decimal) scanText(s string) error {
+	v, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// Value implements driver.Valuer; the decimal is sent as text so no digits are lost
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
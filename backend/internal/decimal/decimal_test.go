@@ -0,0 +1,285 @@
+package decimal
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "0", want: "0"},
+		{in: "-0", want: "0"},
+		{in: "+0.000", want: "0"},
+		{in: "1234.5600", want: "1234.56"},
+		{in: "-1234.56", want: "-1234.56"},
+		{in: "+7", want: "7"},
+		{in: " 42 ", want: "42"},
+		{in: ".5", want: "0.5"},
+		{in: "-.5", want: "-0.5"},
+		{in: "5.", want: "5"},
+		{in: "0.0000000001", want: "0.0000000001"},
+		{in: "123456789012345678901234567890.123456789", want: "123456789012345678901234567890.123456789"},
+		{in: "1.5e3", want: "1500"},
+		{in: "1.5E+3", want: "1500"},
+		{in: "-2.5e-3", want: "-0.0025"},
+		{in: "1e100", want: "1" + strings.Repeat("0", 100)},
+		{in: "1e-100", want: "0." + strings.Repeat("0", 99) + "1"},
+		{in: "", wantErr: true},
+		{in: "-", wantErr: true},
+		{in: ".", wantErr: true},
+		{in: "+-1", wantErr: true},
+		{in: "--1", wantErr: true},
+		{in: "1.2.3", wantErr: true},
+		{in: "1,000", wantErr: true},
+		{in: "abc", wantErr: true},
+		{in: "NaN", wantErr: true},
+		{in: "Inf", wantErr: true},
+		{in: "0x10", wantErr: true},
+		{in: "1e", wantErr: true},
+		{in: "e5", wantErr: true},
+		{in: "1e1.5", wantErr: true},
+		{in: "1e101", wantErr: true},
+		{in: "1e-101", wantErr: true},
+		{in: "1e999999999", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := Parse(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Parse(%q) = %s, want error", tt.in, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.in, err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("Parse(%q) = %s, want %s", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	d := MustParse
+	tenth := 0.1 // a variable, so 0.1 + 0.2 is float64 arithmetic rather than an exact constant
+	tests := []struct {
+		name string
+		got  Decimal
+		want string
+	}{
+		{"0.1 + 0.2", d("0.1").Add(d("0.2")), "0.3"},
+		{"tiny amounts survive", d("1234.56").Add(d("0.0000000001")).Sub(d("1234.56")), "0.0000000001"},
+		{"sub to negative", d("1").Sub(d("1.25")), "-0.25"},
+		{"mul", d("1.5").Mul(d("-0.2")), "-0.3"},
+		{"mul negatives", d("-3").Mul(d("-0.5")), "1.5"},
+		{"neg zero", Zero.Neg(), "0"},
+		{"abs", d("-2.5").Abs(), "2.5"},
+		{"sum", Sum(d("0.1"), d("0.1"), d("0.1"), d("-0.3")), "0"},
+		{"min", Min(d("-1"), d("0.5")), "-1"},
+		{"max", Max(d("-1"), d("0.5")), "0.5"},
+		{"new", New(12345, 2), "123.45"},
+		{"new negative scale", New(12, -3), "12000"},
+		{"from float", NewFromFloat(0.1), "0.1"},
+		{"from float tiny", NewFromFloat(-3.14e-7), "-0.000000314"},
+		{"from float sum", NewFromFloat(tenth + 0.2), "0.30000000000000004"},
+	}
+	for _, tt := range tests {
+		if tt.got.String() != tt.want {
+			t.Errorf("%s = %s, want %s", tt.name, tt.got, tt.want)
+		}
+	}
+	if MustParse("1.10").Cmp(MustParse("1.1")) != 0 || MustParse("-1").Cmp(Zero) != -1 || MustParse("0.01").Cmp(Zero) != 1 {
+		t.Error("Cmp ignores trailing zeros and orders by value")
+	}
+	if !MustParse("0.000").IsZero() || Zero.Sign() != 0 || MustParse("-0.1").Sign() != -1 {
+		t.Error("Sign and IsZero")
+	}
+}
+
+func TestDiv(t *testing.T) {
+	d := MustParse
+	tests := []struct {
+		a, b   string
+		places int32
+		want   string
+	}{
+		{"10", "3", 4, "3.3333"},
+		{"-10", "3", 4, "-3.3333"},
+		{"20", "3", 4, "6.6667"},
+		{"-20", "3", 4, "-6.6667"},
+		{"2", "3", 0, "1"},
+		// Exact halves round to the even neighbour, in both directions
+		{"1", "8", 2, "0.12"},
+		{"3", "8", 2, "0.38"},
+		{"-1", "8", 2, "-0.12"},
+		{"-3", "8", 2, "-0.38"},
+		{"5", "2", 0, "2"},
+		{"7", "2", 0, "4"},
+		{"5", "-2", 0, "-2"},
+		{"7", "-2", 0, "-4"},
+		{"-5", "-2", 0, "2"},
+		{"1e3", "0.25", 2, "4000"},
+		{"0.000123", "456.7", 10, "0.0000002693"},
+		{"1", "3", 10, "0.3333333333"},
+		{"1", "0", 2, "0"},
+	}
+	for _, tt := range tests {
+		if got := d(tt.a).Div(d(tt.b), tt.places); got.String() != tt.want {
+			t.Errorf("%s / %s at %d places = %s, want %s", tt.a, tt.b, tt.places, got, tt.want)
+		}
+	}
+}
+
+func TestRound(t *testing.T) {
+	tests := []struct {
+		in     string
+		places int32
+		want   string
+	}{
+		{"2.345", 2, "2.34"},
+		{"2.355", 2, "2.36"},
+		{"2.3451", 2, "2.35"},
+		{"-2.345", 2, "-2.34"},
+		{"-2.355", 2, "-2.36"},
+		{"-2.3451", 2, "-2.35"},
+		{"2.5", 0, "2"},
+		{"3.5", 0, "4"},
+		{"-2.5", 0, "-2"},
+		{"-3.5", 0, "-4"},
+		{"0.004", 2, "0"},
+		{"-0.005", 2, "0"},
+		{"9.995", 2, "10"},
+		{"1.2", 4, "1.2"},
+		{"1234.5600000001", 2, "1234.56"},
+	}
+	for _, tt := range tests {
+		if got := MustParse(tt.in).Round(tt.places); got.String() != tt.want {
+			t.Errorf("Round(%s, %d) = %s, want %s", tt.in, tt.places, got, tt.want)
+		}
+	}
+	if got := MustParse("-2.999").Truncate(2); got.String() != "-2.99" {
+		t.Errorf("Truncate(-2.999, 2) = %s, want -2.99", got)
+	}
+}
+
+func TestStringFixed(t *testing.T) {
+	tests := []struct {
+		in     string
+		places int32
+		want   string
+	}{
+		{"0.5", 2, "0.50"},
+		{"-0.05", 3, "-0.050"},
+		{"12", 2, "12.00"},
+		{"-0.004", 2, "0.00"},
+		{"-0.006", 2, "-0.01"},
+		{"1.005", 2, "1.00"},
+		{"2.5", 0, "2"},
+		{"0", 2, "0.00"},
+	}
+	for _, tt := range tests {
+		if got := MustParse(tt.in).StringFixed(tt.places); got != tt.want {
+			t.Errorf("StringFixed(%s, %d) = %s, want %s", tt.in, tt.places, got, tt.want)
+		}
+	}
+}
+
+func TestRoundCurrency(t *testing.T) {
+	tests := []struct {
+		in, currency, want string
+	}{
+		{"1234.5", "JPY", "1234"},
+		{"1235.5", "jpy", "1236"},
+		{"1.23456", "KWD", "1.235"},
+		{"1.235", "USD", "1.24"},
+		{"1.225", "XYZ", "1.22"},
+	}
+	for _, tt := range tests {
+		if got := MustParse(tt.in).RoundCurrency(tt.currency); got.String() != tt.want {
+			t.Errorf("RoundCurrency(%s, %s) = %s, want %s", tt.in, tt.currency, got, tt.want)
+		}
+	}
+}
+
+func TestJSON(t *testing.T) {
+	type doc struct {
+		A Decimal  `json:"a"`
+		B Decimal  `json:"b"`
+		C Decimal  `json:"c"`
+		P *Decimal `json:"p"`
+	}
+	var v doc
+	in := `{"a":1234.560000000001,"b":"-0.30","c":null,"p":"1e-3"}`
+	if err := json.Unmarshal([]byte(in), &v); err != nil {
+		t.Fatal(err)
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"a":1234.560000000001,"b":-0.3,"c":0,"p":0.001}`; string(out) != want {
+		t.Errorf("round trip = %s, want %s", out, want)
+	}
+
+	var back doc
+	if err := json.Unmarshal(out, &back); err != nil {
+		t.Fatal(err)
+	}
+	if back.A.Cmp(v.A) != 0 || back.B.Cmp(v.B) != 0 || back.C.Cmp(v.C) != 0 || back.P.Cmp(*v.P) != 0 {
+		t.Errorf("second round trip changed values: %+v", back)
+	}
+
+	for _, bad := range []string{`{"a":"x"}`, `{"a":true}`, `{"a":"1e999"}`, `{"a":[1]}`} {
+		if err := json.Unmarshal([]byte(bad), &v); err == nil {
+			t.Errorf("Unmarshal(%s) succeeded, want error", bad)
+		}
+	}
+}
+
+func TestScanValue(t *testing.T) {
+	tests := []struct {
+		name string
+		src  interface{}
+		want string
+	}{
+		{"nil", nil, "0"},
+		{"int64", int64(-7), "-7"},
+		{"float64", 0.1, "0.1"},
+		{"float64 integer", float64(25), "25"},
+		{"bytes", []byte("1234.560000"), "1234.56"},
+		{"postgres numeric", []byte("-0.0000001000"), "-0.0000001"},
+		{"string", "99.99", "99.99"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := MustParse("123")
+			if err := d.Scan(tt.src); err != nil {
+				t.Fatal(err)
+			}
+			if d.String() != tt.want {
+				t.Errorf("Scan(%v) = %s, want %s", tt.src, d, tt.want)
+			}
+			v, err := d.Value()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v != tt.want {
+				t.Errorf("Value() = %#v, want %q", v, tt.want)
+			}
+		})
+	}
+
+	var d Decimal
+	for _, bad := range []interface{}{"abc", []byte("1.2.3"), true} {
+		if err := d.Scan(bad); err == nil {
+			t.Errorf("Scan(%#v) succeeded, want error", bad)
+		}
+	}
+}
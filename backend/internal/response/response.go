@@ -29,6 +29,15 @@ func ErrorResponse(c *gin.Context, status int, code, message string) {
 	})
 }
 
+// ErrorWithData sends a structured error response together with the data that
+// explains it, such as the per-row results of a rejected import
+func ErrorWithData(c *gin.Context, status int, code, message string, data interface{}) {
+	c.JSON(status, gin.H{
+		"error": Error{Code: code, Message: message},
+		"data":  data,
+	})
+}
+
 // BadRequest sends 400
 func BadRequest(c *gin.Context, message string) {
 	ErrorResponse(c, http.StatusBadRequest, "BAD_REQUEST", message)
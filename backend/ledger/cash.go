@@ -0,0 +1,83 @@
+package ledger
+
+import (
+	"fmt"
+	"sort"
+
+	"tinystock/backend/internal/decimal"
+	"tinystock/backend/models"
+)
+
+// Charges returns the total fee, commission and tax on a transaction
+func Charges(t models.Transaction) decimal.Decimal {
+	return decimal.Sum(t.Fee, t.Commission, t.Tax)
+}
+
+// CashFlow returns the change in cash a transaction causes, in its currency, net
+// of its charges. Transfers and splits otherwise move shares only.
+func CashFlow(t models.Transaction) decimal.Decimal {
+	flow := decimal.Zero
+	switch t.Type {
+	case models.TxDeposit, models.TxDividend:
+		flow = t.Amount
+	case models.TxWithdrawal, models.TxFee:
+		flow = t.Amount.Neg()
+	case models.TxBuy:
+		flow = t.Quantity.Mul(t.Price).Neg()
+	case models.TxSell:
+		flow = t.Quantity.Mul(t.Price)
+	}
+	return flow.Sub(Charges(t))
+}
+
+// Cash replays txs in trade date order and returns the cash balance per currency.
+// An outflow larger than the balance is treated as funded by an unrecorded
+// contribution, so balances never go negative; ledgers that predate cash tracking
+// (buys without deposits) therefore hold no cash rather than a debt.
+func Cash(txs []models.Transaction) map[string]decimal.Decimal {
+	ordered := make([]models.Transaction, len(txs))
+	copy(ordered, txs)
+	Sort(ordered)
+
+	p := NewPosition()
+	for _, t := range ordered {
+		p.Apply(t)
+	}
+	for currency, amount := range p.Cash {
+		if amount.Sign() <= 0 {
+			delete(p.Cash, currency)
+		}
+	}
+	return p.Cash
+}
+
+// CheckCash fails with ErrInsufficientCash if, replayed in trade date order, any
+// transaction takes a currency's balance below zero
+func CheckCash(txs []models.Transaction) error {
+	ordered := make([]models.Transaction, len(txs))
+	copy(ordered, txs)
+	Sort(ordered)
+
+	balances := make(map[string]decimal.Decimal)
+	for _, t := range ordered {
+		flow := CashFlow(t)
+		balances[t.Currency] = balances[t.Currency].Add(flow)
+		if flow.Sign() < 0 && balances[t.Currency].Sign() < 0 {
+			places := decimal.CurrencyPlaces(t.Currency)
+			return fmt.Errorf("%w: %s on %s needs %s %s, %s available",
+				ErrInsufficientCash, t.Type, t.TradeDate, flow.Neg().StringFixed(places), t.Currency,
+				balances[t.Currency].Sub(flow).StringFixed(places))
+		}
+	}
+	return nil
+}
+
+// Currencies returns the currencies in balances, sorted
+func Currencies(balances map[string]decimal.Decimal) []string {
+	out := make([]string, 0, len(balances))
+	for c := range balances {
+		out = append(out, c)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,89 @@
+package ledger
+
+import (
+	"errors"
+	"testing"
+
+	"tinystock/backend/models"
+)
+
+// cash builds a deposit, withdrawal or fee in USD
+func cash(id int64, typ, date, amount string) models.Transaction {
+	return models.Transaction{ID: id, Type: typ, TradeDate: date, Currency: "USD", Amount: d(amount)}
+}
+
+func TestCheckCash(t *testing.T) {
+	tests := []struct {
+		name    string
+		txs     []models.Transaction
+		wantErr bool
+	}{
+		{
+			// The buy is the transaction being recorded: unsaved, on the deposit's date
+			name: "deposit then buy the same day",
+			txs: []models.Transaction{
+				cash(1, models.TxDeposit, "2023-10-01", "200"),
+				trade(0, models.TxBuy, "2023-10-01", "2", "100"),
+			},
+		},
+		{
+			name: "saved deposit and buy the same day",
+			txs: []models.Transaction{
+				trade(2, models.TxBuy, "2023-10-01", "2", "100"),
+				cash(1, models.TxDeposit, "2023-10-01", "200"),
+			},
+		},
+		{
+			name: "buy before the deposit",
+			txs: []models.Transaction{
+				trade(0, models.TxBuy, "2023-09-30", "2", "100"),
+				cash(1, models.TxDeposit, "2023-10-01", "200"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "charges overdraw",
+			txs: []models.Transaction{
+				cash(1, models.TxDeposit, "2023-10-01", "200"),
+				withCharges(trade(0, models.TxBuy, "2023-10-01", "2", "100"), "0", "0.01", "0"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "sale proceeds fund a withdrawal",
+			txs: []models.Transaction{
+				cash(1, models.TxDeposit, "2023-10-01", "100"),
+				trade(2, models.TxBuy, "2023-10-01", "1", "100"),
+				trade(3, models.TxSell, "2023-10-02", "1", "150"),
+				cash(0, models.TxWithdrawal, "2023-10-02", "150"),
+			},
+		},
+		{
+			// Balances are per currency: dollars do not fund a euro purchase
+			name: "other currency",
+			txs: []models.Transaction{
+				cash(1, models.TxDeposit, "2023-10-01", "500"),
+				func() models.Transaction {
+					tx := trade(0, models.TxBuy, "2023-10-01", "1", "100")
+					tx.Currency = "EUR"
+					return tx
+				}(),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckCash(tt.txs)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInsufficientCash) {
+					t.Fatalf("err = %v, want ErrInsufficientCash", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+// Package ledger derives positions from a user's transaction history.
+package ledger
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"tinystock/backend/internal/decimal"
+	"tinystock/backend/models"
+)
+
+// dateLayout is the format of Transaction.TradeDate
+const dateLayout = "2006-01-02"
+
+var (
+	ErrInvalidTransaction   = errors.New("invalid transaction")
+	ErrInsufficientQuantity = errors.New("insufficient quantity")
+	ErrInsufficientCash     = errors.New("insufficient cash")
+)
+
+// invalid wraps ErrInvalidTransaction with a user-facing reason
+func invalid(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidTransaction, fmt.Sprintf(format, args...))
+}
+
+// IsCashOnly reports whether a transaction type can be recorded without a symbol
+func IsCashOnly(t models.Transaction) bool {
+	switch t.Type {
+	case models.TxDeposit, models.TxWithdrawal:
+		return true
+	case models.TxFee:
+		return t.Symbol == ""
+	}
+	return false
+}
+
+// Validate checks a single transaction's fields; it does not look at other transactions
+func Validate(t models.Transaction) error {
+	if t.Symbol == "" && !IsCashOnly(t) {
+		return invalid("symbol is required")
+	}
+	if t.Symbol != "" && (t.Type == models.TxDeposit || t.Type == models.TxWithdrawal) {
+		return invalid("%s does not take a symbol", t.Type)
+	}
+	day, err := time.Parse(dateLayout, t.TradeDate)
+	if err != nil {
+		return invalid("tradeDate must be YYYY-MM-DD")
+	}
+	// Allow a day of slack for users ahead of UTC
+	if day.After(time.Now().UTC().AddDate(0, 0, 1)) {
+		return invalid("tradeDate is in the future")
+	}
+	if t.Fee.Sign() < 0 || t.Commission.Sign() < 0 || t.Tax.Sign() < 0 {
+		return invalid("fee, commission and tax must not be negative")
+	}
+	switch t.Type {
+	case models.TxBuy, models.TxSell:
+		if t.Quantity.Sign() <= 0 || t.Price.Sign() <= 0 {
+			return invalid("%s requires a positive quantity and price", t.Type)
+		}
+	case models.TxTransferIn, models.TxTransferOut:
+		if t.Quantity.Sign() <= 0 || t.Price.Sign() < 0 {
+			return invalid("%s requires a positive quantity", t.Type)
+		}
+	case models.TxDividend, models.TxFee, models.TxDeposit, models.TxWithdrawal:
+		if t.Amount.Sign() <= 0 {
+			return invalid("%s requires a positive amount", t.Type)
+		}
+	case models.TxSplit:
+		if t.Quantity.Sign() <= 0 {
+			return invalid("split requires a positive ratio in quantity")
+		}
+	default:
+		return invalid("unknown type %q", t.Type)
+	}
+	return nil
+}
+
+// Sort orders transactions by trade date, then by insertion order. Unsaved
+// transactions (ID 0), such as one being validated before it is stored, come after
+// the saved ones on their date and keep their order in txs.
+func Sort(txs []models.Transaction) {
+	sort.SliceStable(txs, func(i, j int) bool {
+		if txs[i].TradeDate != txs[j].TradeDate {
+			return txs[i].TradeDate < txs[j].TradeDate
+		}
+		if (txs[i].ID == 0) != (txs[j].ID == 0) {
+			return txs[j].ID == 0
+		}
+		return txs[i].ID < txs[j].ID
+	})
+}
+
+// insufficient reports a disposal larger than the position held on its trade date
+func insufficient(symbol string, t models.Transaction, held decimal.Decimal) error {
+	return fmt.Errorf("%w: %s %s %s on %s with %s held", ErrInsufficientQuantity, t.Type, symbol, t.Quantity, t.TradeDate, held)
+}
+
+// Holdings replays txs and returns open positions with average cost, sorted by symbol.
+// It fails with ErrInsufficientQuantity if any sell or transfer out exceeds the
+// quantity held on its trade date.
+func Holdings(txs []models.Transaction) ([]models.Holding, error) {
+	b, err := Replay(txs, MethodAverage)
+	if err != nil {
+		return nil, err
+	}
+	return b.Holdings(), nil
+}
@@ -0,0 +1,53 @@
+package ledger
+
+import (
+	"testing"
+
+	"tinystock/backend/models"
+)
+
+func TestSort(t *testing.T) {
+	txs := []models.Transaction{
+		trade(0, models.TxSell, "2023-01-10", "1", "100"),
+		trade(3, models.TxBuy, "2023-01-10", "1", "100"),
+		trade(0, models.TxSell, "2023-01-09", "1", "100"),
+		trade(1, models.TxBuy, "2023-01-10", "1", "100"),
+		trade(0, models.TxDividend, "2023-01-10", "0", ""),
+		trade(2, models.TxBuy, "2023-01-09", "1", "100"),
+	}
+	Sort(txs)
+	want := []struct {
+		id   int64
+		typ  string
+		date string
+	}{
+		{2, models.TxBuy, "2023-01-09"},
+		{0, models.TxSell, "2023-01-09"},
+		{1, models.TxBuy, "2023-01-10"},
+		{3, models.TxBuy, "2023-01-10"},
+		{0, models.TxSell, "2023-01-10"},
+		{0, models.TxDividend, "2023-01-10"},
+	}
+	for i, w := range want {
+		if txs[i].ID != w.id || txs[i].Type != w.typ || txs[i].TradeDate != w.date {
+			t.Errorf("position %d = {%d %s %s}, want {%d %s %s}", i, txs[i].ID, txs[i].Type, txs[i].TradeDate, w.id, w.typ, w.date)
+		}
+	}
+}
+
+func TestHoldingsSameDayCandidate(t *testing.T) {
+	// A sell being validated before it is stored has no ID yet; it must replay after
+	// the buys already saved for its date
+	txs := []models.Transaction{
+		trade(1, models.TxBuy, "2023-09-01", "10", "100"),
+		trade(2, models.TxBuy, "2023-10-01", "2", "110"),
+		trade(0, models.TxSell, "2023-10-01", "12", "120"),
+	}
+	holdings, err := Holdings(txs)
+	if err != nil {
+		t.Fatalf("same-day sell after a buy: %v", err)
+	}
+	if len(holdings) != 0 {
+		t.Errorf("holdings = %+v, want none", holdings)
+	}
+}
@@ -0,0 +1,252 @@
+package ledger
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"tinystock/backend/internal/decimal"
+	"tinystock/backend/models"
+)
+
+// Cost basis methods for matching sells against lots
+const (
+	MethodFIFO    = "fifo"
+	MethodLIFO    = "lifo"
+	MethodHIFO    = "hifo" // highest cost first
+	MethodAverage = "average"
+)
+
+var ErrInvalidMethod = errors.New("invalid cost basis method")
+
+// ParseMethod normalizes a cost basis method name
+func ParseMethod(method string) (string, error) {
+	switch m := strings.ToLower(strings.TrimSpace(method)); m {
+	case MethodFIFO, MethodLIFO, MethodHIFO, MethodAverage:
+		return m, nil
+	case "highest", "highest_cost":
+		return MethodHIFO, nil
+	case "avg", "average_cost":
+		return MethodAverage, nil
+	}
+	return "", ErrInvalidMethod
+}
+
+// IsLongTerm reports whether a position opened on open and closed on close was held more than a year
+func IsLongTerm(open, close string) bool {
+	o, err1 := time.Parse(dateLayout, open)
+	c, err2 := time.Parse(dateLayout, close)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	return c.After(o.AddDate(1, 0, 0))
+}
+
+// Book is the result of replaying a ledger: open lots, realized gains and cash
+type Book struct {
+	Method   string
+	Lots     map[string][]models.Lot // open lots per symbol, in acquisition order
+	Realized []models.RealizedGain
+	Cash     map[string]decimal.Decimal // balance per currency
+
+	washSales bool
+	ordered   []models.Transaction
+	next      int                      // index in ordered of the transaction after the one replaying
+	bought    map[int64]bool           // buy transaction IDs; other acquisitions never replace
+	pending   map[int][]washAdjustment // disallowed loss waiting on buys not yet replayed, by index
+}
+
+// Options control how a ledger is replayed
+type Options struct {
+	Method string
+	// WashSales defers losses on shares repurchased within WashSaleDays of the sale
+	WashSales bool
+}
+
+// Replay matches sells and transfers out against lots using method. Charges on
+// buys and transfers in are part of a lot's cost; charges on sells reduce the
+// proceeds. Splits scale open lots; dividends and fees do not affect lots. It fails with
+// ErrInsufficientQuantity if a disposal exceeds the quantity held on its trade date.
+func Replay(txs []models.Transaction, method string) (*Book, error) {
+	return ReplayWith(txs, Options{Method: method})
+}
+
+// ReplayWith is Replay with wash sale rules optionally applied
+func ReplayWith(txs []models.Transaction, opts Options) (*Book, error) {
+	method, err := ParseMethod(opts.Method)
+	if err != nil {
+		return nil, err
+	}
+	ordered := make([]models.Transaction, len(txs))
+	copy(ordered, txs)
+	Sort(ordered)
+
+	b := &Book{
+		Method:    method,
+		Lots:      make(map[string][]models.Lot),
+		Cash:      Cash(ordered),
+		washSales: opts.WashSales,
+		ordered:   ordered,
+		bought:    make(map[int64]bool),
+		pending:   make(map[int][]washAdjustment),
+	}
+	currency := make(map[string]string)
+	for i, t := range ordered {
+		b.next = i + 1
+		if t.Symbol == "" {
+			continue
+		}
+		symbol := strings.ToUpper(t.Symbol)
+		if currency[symbol] == "" {
+			currency[symbol] = t.Currency
+		}
+		switch t.Type {
+		case models.TxBuy, models.TxTransferIn:
+			lot := models.Lot{
+				Symbol:        symbol,
+				TransactionID: t.ID,
+				OpenDate:      t.TradeDate,
+				Quantity:      t.Quantity,
+				CostPerShare:  t.Price.Add(Charges(t).Div(t.Quantity, decimal.Precision)),
+				Currency:      currency[symbol],
+			}
+			if t.Type == models.TxBuy {
+				b.bought[t.ID] = true
+			}
+			b.Lots[symbol] = append(b.Lots[symbol], b.replacements(lot, i)...)
+		case models.TxSell, models.TxTransferOut:
+			if err := b.dispose(symbol, t, currency[symbol]); err != nil {
+				return nil, err
+			}
+		case models.TxSplit:
+			for j := range b.Lots[symbol] {
+				l := &b.Lots[symbol][j]
+				l.Quantity = l.Quantity.Mul(t.Quantity)
+				l.CostPerShare = l.CostPerShare.Div(t.Quantity, decimal.Precision)
+				l.WashSaleAdjustment = l.WashSaleAdjustment.Div(t.Quantity, decimal.Precision)
+			}
+		}
+	}
+	return b, nil
+}
+
+// dispose removes t.Quantity shares from symbol's lots, recording gains for sells
+func (b *Book) dispose(symbol string, t models.Transaction, currency string) error {
+	lots := b.Lots[symbol]
+	held := decimal.Zero
+	for _, l := range lots {
+		held = held.Add(l.Quantity)
+	}
+	if t.Quantity.Cmp(held) > 0 {
+		return insufficient(symbol, t, held)
+	}
+
+	// Average cost: every remaining share carries the pooled cost, and lots are
+	// consumed oldest first so holding periods stay meaningful
+	if b.Method == MethodAverage && held.Sign() > 0 {
+		cost := decimal.Zero
+		for _, l := range lots {
+			cost = cost.Add(l.Quantity.Mul(l.CostPerShare))
+		}
+		for i := range lots {
+			lots[i].CostPerShare = cost.Div(held, decimal.Precision)
+		}
+	}
+
+	order := make([]int, len(lots))
+	for i := range order {
+		order[i] = i
+	}
+	switch b.Method {
+	case MethodLIFO:
+		for i, j := 0, len(order)-1; i < j; i, j = i+1, j-1 {
+			order[i], order[j] = order[j], order[i]
+		}
+	case MethodHIFO:
+		sort.SliceStable(order, func(i, j int) bool { return lots[order[i]].CostPerShare.Cmp(lots[order[j]].CostPerShare) > 0 })
+	}
+
+	// Sell charges are spread evenly over the shares sold
+	charges := Charges(t)
+	remaining := t.Quantity
+	start := len(b.Realized)
+	var sold []int64 // the lot behind each realized gain
+	for _, i := range order {
+		if remaining.Sign() <= 0 {
+			break
+		}
+		l := &lots[i]
+		q := decimal.Min(l.Quantity, remaining)
+		if t.Type == models.TxSell {
+			proceeds := q.Mul(t.Price).Sub(charges.Mul(q).Div(t.Quantity, decimal.Precision))
+			cost := q.Mul(l.CostPerShare)
+			b.Realized = append(b.Realized, models.RealizedGain{
+				Symbol:        symbol,
+				TransactionID: t.ID,
+				OpenDate:      l.OpenDate,
+				CloseDate:     t.TradeDate,
+				Quantity:      q,
+				Proceeds:      proceeds,
+				Cost:          cost,
+				Gain:          proceeds.Sub(cost),
+				Currency:      currency,
+				LongTerm:      IsLongTerm(l.OpenDate, t.TradeDate),
+			})
+			sold = append(sold, l.TransactionID)
+		}
+		l.Quantity = l.Quantity.Sub(q)
+		remaining = remaining.Sub(q)
+	}
+
+	open := lots[:0]
+	for _, l := range lots {
+		if l.Quantity.Sign() > 0 {
+			open = append(open, l)
+		}
+	}
+	if len(open) == 0 {
+		delete(b.Lots, symbol)
+	} else {
+		b.Lots[symbol] = open
+	}
+	if b.washSales {
+		for i, lot := range sold {
+			if g := &b.Realized[start+i]; g.Gain.Sign() < 0 {
+				b.washSale(symbol, g, lot)
+			}
+		}
+	}
+	return nil
+}
+
+// Holdings aggregates open lots into one position per symbol, sorted by symbol
+func (b *Book) Holdings() []models.Holding {
+	holdings := make([]models.Holding, 0, len(b.Lots))
+	for symbol, lots := range b.Lots {
+		h := models.Holding{Symbol: symbol}
+		cost := decimal.Zero
+		for _, l := range lots {
+			h.Quantity = h.Quantity.Add(l.Quantity)
+			cost = cost.Add(l.Quantity.Mul(l.CostPerShare))
+			h.Currency = l.Currency
+		}
+		if h.Quantity.Sign() <= 0 {
+			continue
+		}
+		h.BuyPrice = cost.Div(h.Quantity, decimal.Precision)
+		holdings = append(holdings, h)
+	}
+	sort.Slice(holdings, func(i, j int) bool { return holdings[i].Symbol < holdings[j].Symbol })
+	return holdings
+}
+
+// OpenLots returns symbol's open lots with holding periods measured to asOf (YYYY-MM-DD)
+func (b *Book) OpenLots(symbol, asOf string) []models.Lot {
+	lots := make([]models.Lot, len(b.Lots[symbol]))
+	copy(lots, b.Lots[symbol])
+	for i := range lots {
+		lots[i].LongTerm = IsLongTerm(lots[i].OpenDate, asOf)
+	}
+	return lots
+}
@@ -0,0 +1,408 @@
+package ledger
+
+import (
+	"errors"
+	"testing"
+
+	"tinystock/backend/internal/decimal"
+	"tinystock/backend/models"
+)
+
+var d = decimal.MustParse
+
+// trade builds a ledger entry for AAPL; ids follow the order of the calls in a test
+func trade(id int64, typ, date, quantity, price string) models.Transaction {
+	t := models.Transaction{ID: id, Symbol: "AAPL", Type: typ, TradeDate: date, Currency: "USD", Quantity: d(quantity)}
+	if price != "" {
+		t.Price = d(price)
+	}
+	return t
+}
+
+// withCharges sets a transaction's fee, commission and tax
+func withCharges(t models.Transaction, fee, commission, tax string) models.Transaction {
+	t.Charges = models.Charges{Fee: d(fee), Commission: d(commission), Tax: d(tax)}
+	return t
+}
+
+type wantGain struct {
+	openDate                 string
+	quantity, proceeds, cost string
+	longTerm                 bool
+}
+
+type wantLot struct {
+	id                     int64
+	quantity, costPerShare string
+}
+
+func checkGains(t *testing.T, got []models.RealizedGain, want []wantGain) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d realized gains %+v, want %d", len(got), got, len(want))
+	}
+	for i, w := range want {
+		g := got[i]
+		gain := d(w.proceeds).Sub(d(w.cost))
+		if g.OpenDate != w.openDate || g.Quantity.Cmp(d(w.quantity)) != 0 || g.Proceeds.Cmp(d(w.proceeds)) != 0 ||
+			g.Cost.Cmp(d(w.cost)) != 0 || g.Gain.Cmp(gain) != 0 || g.LongTerm != w.longTerm {
+			t.Errorf("gain %d = {%s %s proceeds %s cost %s gain %s long %v}, want {%s %s proceeds %s cost %s gain %s long %v}",
+				i, g.OpenDate, g.Quantity, g.Proceeds, g.Cost, g.Gain, g.LongTerm,
+				w.openDate, w.quantity, w.proceeds, w.cost, gain, w.longTerm)
+		}
+	}
+}
+
+func checkLots(t *testing.T, got []models.Lot, want []wantLot) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d open lots %+v, want %d", len(got), got, len(want))
+	}
+	for i, w := range want {
+		l := got[i]
+		if l.TransactionID != w.id || l.Quantity.Cmp(d(w.quantity)) != 0 || l.CostPerShare.Cmp(d(w.costPerShare)) != 0 {
+			t.Errorf("lot %d = {tx %d %s @ %s}, want {tx %d %s @ %s}",
+				i, l.TransactionID, l.Quantity, l.CostPerShare, w.id, w.quantity, w.costPerShare)
+		}
+	}
+}
+
+func TestReplayMethods(t *testing.T) {
+	// Three lots of 10 at 100, 120 and 110, then 15 sold at 130: every method
+	// consumes one lot whole and another in part
+	txs := []models.Transaction{
+		trade(4, models.TxSell, "2023-04-10", "15", "130"),
+		trade(1, models.TxBuy, "2023-01-10", "10", "100"),
+		trade(2, models.TxBuy, "2023-02-10", "10", "120"),
+		trade(3, models.TxBuy, "2023-03-10", "10", "110"),
+	}
+	tests := []struct {
+		method string
+		gains  []wantGain
+		lots   []wantLot
+	}{
+		{
+			method: MethodFIFO,
+			gains: []wantGain{
+				{openDate: "2023-01-10", quantity: "10", proceeds: "1300", cost: "1000"},
+				{openDate: "2023-02-10", quantity: "5", proceeds: "650", cost: "600"},
+			},
+			lots: []wantLot{{2, "5", "120"}, {3, "10", "110"}},
+		},
+		{
+			method: MethodLIFO,
+			gains: []wantGain{
+				{openDate: "2023-03-10", quantity: "10", proceeds: "1300", cost: "1100"},
+				{openDate: "2023-02-10", quantity: "5", proceeds: "650", cost: "600"},
+			},
+			lots: []wantLot{{1, "10", "100"}, {2, "5", "120"}},
+		},
+		{
+			method: MethodHIFO,
+			gains: []wantGain{
+				{openDate: "2023-02-10", quantity: "10", proceeds: "1300", cost: "1200"},
+				{openDate: "2023-03-10", quantity: "5", proceeds: "650", cost: "550"},
+			},
+			lots: []wantLot{{1, "10", "100"}, {3, "5", "110"}},
+		},
+		{
+			// Pooled cost 3300 / 30 = 110 a share, with the oldest lots consumed first
+			method: MethodAverage,
+			gains: []wantGain{
+				{openDate: "2023-01-10", quantity: "10", proceeds: "1300", cost: "1100"},
+				{openDate: "2023-02-10", quantity: "5", proceeds: "650", cost: "550"},
+			},
+			lots: []wantLot{{2, "5", "110"}, {3, "10", "110"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			b, err := Replay(txs, tt.method)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkGains(t, b.Realized, tt.gains)
+			checkLots(t, b.OpenLots("AAPL", "2023-04-10"), tt.lots)
+		})
+	}
+}
+
+func TestReplayPartialLots(t *testing.T) {
+	// Successive sells eat into the same lot before moving on
+	txs := []models.Transaction{
+		trade(1, models.TxBuy, "2023-01-10", "10", "100"),
+		trade(2, models.TxBuy, "2023-01-20", "10", "200"),
+		trade(3, models.TxSell, "2023-02-01", "4", "150"),
+		trade(4, models.TxSell, "2023-02-02", "4", "150"),
+		trade(5, models.TxSell, "2023-02-03", "4", "150"),
+	}
+	b, err := Replay(txs, MethodFIFO)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkGains(t, b.Realized, []wantGain{
+		{openDate: "2023-01-10", quantity: "4", proceeds: "600", cost: "400"},
+		{openDate: "2023-01-10", quantity: "4", proceeds: "600", cost: "400"},
+		{openDate: "2023-01-10", quantity: "2", proceeds: "300", cost: "200"},
+		{openDate: "2023-01-20", quantity: "2", proceeds: "300", cost: "400"},
+	})
+	checkLots(t, b.OpenLots("AAPL", "2023-02-03"), []wantLot{{2, "8", "200"}})
+
+	// Selling everything closes the position
+	txs = append(txs, trade(6, models.TxSell, "2023-02-04", "8", "150"))
+	if b, err = Replay(txs, MethodFIFO); err != nil {
+		t.Fatal(err)
+	}
+	if lots, ok := b.Lots["AAPL"]; ok {
+		t.Errorf("closed position still has lots %+v", lots)
+	}
+	if h := b.Holdings(); len(h) != 0 {
+		t.Errorf("closed position still held: %+v", h)
+	}
+}
+
+func TestReplaySplits(t *testing.T) {
+	tests := []struct {
+		name  string
+		ratio string
+		sell  string
+		gains []wantGain
+		lots  []wantLot
+	}{
+		{
+			name: "2-for-1", ratio: "2", sell: "5",
+			gains: []wantGain{{openDate: "2023-01-10", quantity: "5", proceeds: "300", cost: "250"}},
+			lots:  []wantLot{{1, "15", "50"}},
+		},
+		{
+			// Cost per share keeps decimal.Precision digits, rounded half to even
+			name: "3-for-1", ratio: "3", sell: "3",
+			gains: []wantGain{{openDate: "2023-01-10", quantity: "3", proceeds: "180", cost: "99.9999999999"}},
+			lots:  []wantLot{{1, "27", "33.3333333333"}},
+		},
+		{
+			name: "1-for-2 reverse", ratio: "0.5", sell: "1",
+			gains: []wantGain{{openDate: "2023-01-10", quantity: "1", proceeds: "60", cost: "200"}},
+			lots:  []wantLot{{1, "4", "200"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txs := []models.Transaction{
+				trade(1, models.TxBuy, "2023-01-10", "10", "100"),
+				trade(2, models.TxSplit, "2023-02-01", tt.ratio, ""),
+				trade(3, models.TxSell, "2023-03-01", tt.sell, "60"),
+			}
+			b, err := Replay(txs, MethodFIFO)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkGains(t, b.Realized, tt.gains)
+			checkLots(t, b.OpenLots("AAPL", "2023-03-01"), tt.lots)
+		})
+	}
+}
+
+func TestReplayTransfers(t *testing.T) {
+	// A transfer in opens a lot at its price; a transfer out removes shares
+	// without realizing a gain
+	txs := []models.Transaction{
+		trade(1, models.TxTransferIn, "2023-01-10", "10", "50"),
+		trade(2, models.TxBuy, "2023-01-20", "10", "70"),
+		trade(3, models.TxTransferOut, "2023-02-01", "12", "0"),
+		trade(4, models.TxSell, "2023-03-01", "4", "80"),
+	}
+	b, err := Replay(txs, MethodFIFO)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkGains(t, b.Realized, []wantGain{
+		{openDate: "2023-01-20", quantity: "4", proceeds: "320", cost: "280"},
+	})
+	checkLots(t, b.OpenLots("AAPL", "2023-03-01"), []wantLot{{2, "4", "70"}})
+}
+
+func TestReplayInsufficientQuantity(t *testing.T) {
+	tests := []struct {
+		name string
+		txs  []models.Transaction
+	}{
+		{
+			name: "sell more than held",
+			txs: []models.Transaction{
+				trade(1, models.TxBuy, "2023-01-10", "10", "100"),
+				trade(2, models.TxSell, "2023-02-01", "10.0000000001", "110"),
+			},
+		},
+		{
+			name: "sell before the buy",
+			txs: []models.Transaction{
+				trade(1, models.TxBuy, "2023-02-01", "10", "100"),
+				trade(2, models.TxSell, "2023-01-10", "5", "110"),
+			},
+		},
+		{
+			name: "second sell oversells",
+			txs: []models.Transaction{
+				trade(1, models.TxBuy, "2023-01-10", "10", "100"),
+				trade(2, models.TxSell, "2023-02-01", "6", "110"),
+				trade(3, models.TxSell, "2023-02-02", "6", "110"),
+			},
+		},
+		{
+			name: "transfer out more than held",
+			txs: []models.Transaction{
+				trade(1, models.TxTransferIn, "2023-01-10", "3", "100"),
+				trade(2, models.TxTransferOut, "2023-02-01", "4", "0"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		for _, method := range []string{MethodFIFO, MethodLIFO, MethodHIFO, MethodAverage} {
+			t.Run(tt.name+"/"+method, func(t *testing.T) {
+				if _, err := Replay(tt.txs, method); !errors.Is(err, ErrInsufficientQuantity) {
+					t.Fatalf("err = %v, want ErrInsufficientQuantity", err)
+				}
+			})
+		}
+	}
+}
+
+func TestReplayHoldingPeriod(t *testing.T) {
+	// Long term means held more than a year: a sale on the anniversary is still short term
+	tests := []struct {
+		open, close string
+		longTerm    bool
+	}{
+		{"2023-03-15", "2024-03-14", false},
+		{"2023-03-15", "2024-03-15", false},
+		{"2023-03-15", "2024-03-16", true},
+		{"2023-12-31", "2024-12-31", false},
+		{"2023-12-31", "2025-01-01", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.open+"/"+tt.close, func(t *testing.T) {
+			txs := []models.Transaction{
+				trade(1, models.TxBuy, tt.open, "2", "100"),
+				trade(2, models.TxSell, tt.close, "1", "100"),
+			}
+			b, err := Replay(txs, MethodFIFO)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := b.Realized[0].LongTerm; got != tt.longTerm {
+				t.Errorf("realized long term = %v, want %v", got, tt.longTerm)
+			}
+			if got := b.OpenLots("AAPL", tt.close)[0].LongTerm; got != tt.longTerm {
+				t.Errorf("open lot long term as of %s = %v, want %v", tt.close, got, tt.longTerm)
+			}
+		})
+	}
+}
+
+func TestReplayCharges(t *testing.T) {
+	tests := []struct {
+		name  string
+		txs   []models.Transaction
+		gains []wantGain
+		lots  []wantLot
+	}{
+		{
+			// Buy charges of 20 on 10 shares add 2 to the cost per share
+			name: "buy charges added to basis",
+			txs: []models.Transaction{
+				withCharges(trade(1, models.TxBuy, "2023-01-10", "10", "100"), "5", "10", "5"),
+				trade(2, models.TxSell, "2023-02-01", "4", "110"),
+			},
+			gains: []wantGain{{openDate: "2023-01-10", quantity: "4", proceeds: "440", cost: "408"}},
+			lots:  []wantLot{{1, "6", "102"}},
+		},
+		{
+			name: "sell charges deducted from proceeds",
+			txs: []models.Transaction{
+				trade(1, models.TxBuy, "2023-01-10", "10", "100"),
+				withCharges(trade(2, models.TxSell, "2023-02-01", "10", "110"), "1", "20", "4"),
+			},
+			gains: []wantGain{{openDate: "2023-01-10", quantity: "10", proceeds: "1075", cost: "1000"}},
+		},
+		{
+			// Sell charges are spread over the lots in proportion to the shares taken
+			name: "sell charges split across lots",
+			txs: []models.Transaction{
+				trade(1, models.TxBuy, "2023-01-10", "2", "100"),
+				trade(2, models.TxBuy, "2023-01-11", "8", "100"),
+				withCharges(trade(3, models.TxSell, "2023-02-01", "10", "110"), "0", "10", "0"),
+			},
+			gains: []wantGain{
+				{openDate: "2023-01-10", quantity: "2", proceeds: "218", cost: "200"},
+				{openDate: "2023-01-11", quantity: "8", proceeds: "872", cost: "800"},
+			},
+		},
+		{
+			// 10 / 3 per share: the basis keeps decimal.Precision digits
+			name: "uneven charges",
+			txs: []models.Transaction{
+				withCharges(trade(1, models.TxBuy, "2023-01-10", "3", "100"), "0", "10", "0"),
+			},
+			lots: []wantLot{{1, "3", "103.3333333333"}},
+		},
+		{
+			name: "transfer in charges added to basis",
+			txs: []models.Transaction{
+				withCharges(trade(1, models.TxTransferIn, "2023-01-10", "5", "40"), "5", "0", "0"),
+			},
+			lots: []wantLot{{1, "5", "41"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := Replay(tt.txs, MethodFIFO)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkGains(t, b.Realized, tt.gains)
+			checkLots(t, b.OpenLots("AAPL", "2023-02-01"), tt.lots)
+		})
+	}
+}
+
+func TestReplayMixedSymbols(t *testing.T) {
+	// Sells only match lots of their own symbol, whatever the case of the ticker
+	msft := trade(2, models.TxBuy, "2023-01-11", "5", "300")
+	msft.Symbol = "msft"
+	sell := trade(3, models.TxSell, "2023-02-01", "5", "350")
+	sell.Symbol = "MSFT"
+	txs := []models.Transaction{trade(1, models.TxBuy, "2023-01-10", "10", "100"), msft, sell}
+	b, err := Replay(txs, MethodFIFO)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkGains(t, b.Realized, []wantGain{{openDate: "2023-01-11", quantity: "5", proceeds: "1750", cost: "1500"}})
+	holdings := b.Holdings()
+	if len(holdings) != 1 || holdings[0].Symbol != "AAPL" || holdings[0].Quantity.Cmp(d("10")) != 0 {
+		t.Errorf("holdings = %+v, want 10 AAPL", holdings)
+	}
+}
+
+func TestParseMethod(t *testing.T) {
+	tests := []struct {
+		in, want string
+		wantErr  bool
+	}{
+		{in: "FIFO", want: MethodFIFO},
+		{in: " lifo ", want: MethodLIFO},
+		{in: "highest_cost", want: MethodHIFO},
+		{in: "avg", want: MethodAverage},
+		{in: "specific", wantErr: true},
+		{in: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseMethod(tt.in)
+		if tt.wantErr != (err != nil) || got != tt.want {
+			t.Errorf("ParseMethod(%q) = %q, %v", tt.in, got, err)
+		}
+	}
+	if _, err := Replay(nil, "specific"); !errors.Is(err, ErrInvalidMethod) {
+		t.Errorf("Replay with unknown method: err = %v, want ErrInvalidMethod", err)
+	}
+}
@@ -0,0 +1,96 @@
+package ledger
+
+import (
+	"strings"
+
+	"tinystock/backend/internal/decimal"
+	"tinystock/backend/models"
+)
+
+// Flow is money or shares moving into (+) or out of (-) a portfolio from outside:
+// deposits, withdrawals, in-kind transfers and the unrecorded contributions that
+// fund an outflow larger than the cash on hand
+type Flow struct {
+	Date     string
+	Currency string
+	Amount   decimal.Decimal // cash
+	Symbol   string          // in-kind transfers only
+	Quantity decimal.Decimal // shares, in-kind transfers only
+}
+
+// Position tracks share counts and cash balances as transactions are applied in
+// trade date order, for valuing a portfolio on past dates
+type Position struct {
+	Shares   map[string]decimal.Decimal
+	Cash     map[string]decimal.Decimal
+	Currency map[string]string // trading currency per symbol (first seen)
+}
+
+// NewPosition returns an empty position
+func NewPosition() *Position {
+	return &Position{
+		Shares:   make(map[string]decimal.Decimal),
+		Cash:     make(map[string]decimal.Decimal),
+		Currency: make(map[string]string),
+	}
+}
+
+// Apply updates the position with t and returns the external flows it caused.
+// Callers must apply transactions in Sort order.
+func (p *Position) Apply(t models.Transaction) []Flow {
+	var flows []Flow
+	symbol := strings.ToUpper(t.Symbol)
+	if symbol != "" && p.Currency[symbol] == "" {
+		p.Currency[symbol] = t.Currency
+	}
+
+	switch t.Type {
+	case models.TxBuy:
+		p.Shares[symbol] = p.Shares[symbol].Add(t.Quantity)
+	case models.TxSell:
+		p.Shares[symbol] = p.Shares[symbol].Sub(t.Quantity)
+	case models.TxTransferIn:
+		p.Shares[symbol] = p.Shares[symbol].Add(t.Quantity)
+		flows = append(flows, Flow{Date: t.TradeDate, Currency: p.Currency[symbol], Symbol: symbol, Quantity: t.Quantity})
+	case models.TxTransferOut:
+		p.Shares[symbol] = p.Shares[symbol].Sub(t.Quantity)
+		flows = append(flows, Flow{Date: t.TradeDate, Currency: p.Currency[symbol], Symbol: symbol, Quantity: t.Quantity.Neg()})
+	case models.TxSplit:
+		p.Shares[symbol] = p.Shares[symbol].Mul(t.Quantity)
+	case models.TxDeposit:
+		flows = append(flows, Flow{Date: t.TradeDate, Currency: t.Currency, Amount: t.Amount})
+	case models.TxWithdrawal:
+		flows = append(flows, Flow{Date: t.TradeDate, Currency: t.Currency, Amount: t.Amount.Neg()})
+	}
+	if symbol != "" && p.Shares[symbol].Sign() <= 0 {
+		delete(p.Shares, symbol)
+	}
+
+	if cash := CashFlow(t); !cash.IsZero() {
+		p.Cash[t.Currency] = p.Cash[t.Currency].Add(cash)
+		if short := p.Cash[t.Currency]; short.Sign() < 0 {
+			// Funded from outside the ledger; see Cash
+			flows = append(flows, Flow{Date: t.TradeDate, Currency: t.Currency, Amount: short.Neg()})
+			p.Cash[t.Currency] = decimal.Zero
+		}
+	}
+	return flows
+}
+
+// SharesBefore returns the shares of symbol held at the end of the day before date,
+// which is what a dividend with that ex-date is paid on
+func SharesBefore(txs []models.Transaction, symbol, date string) decimal.Decimal {
+	symbol = strings.ToUpper(symbol)
+	var held []models.Transaction
+	for _, t := range txs {
+		if strings.ToUpper(t.Symbol) == symbol && t.TradeDate < date {
+			held = append(held, t)
+		}
+	}
+	Sort(held)
+	p := NewPosition()
+	for _, t := range held {
+		p.Apply(t)
+	}
+	return p.Shares[symbol]
+}
@@ -0,0 +1,70 @@
+package ledger
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Jurisdiction holds a tax authority's capital gains rules
+type Jurisdiction struct {
+	Code string
+	Name string
+	// Currency is the one gains are reported in
+	Currency string
+	// LongTermMonths is the holding period a position must exceed to be long-term
+	LongTermMonths int
+	// FiscalStart is the month the tax year begins
+	FiscalStart time.Month
+	// Method is the lot matching the authority requires; empty means the portfolio's
+	Method string
+	// WashSales is whether losses on repurchased shares are deferred, for portfolios
+	// that enable it
+	WashSales bool
+}
+
+// Jurisdictions are the supported tax rules by code
+var Jurisdictions = map[string]Jurisdiction{
+	"US": {Code: "US", Name: "United States", Currency: "USD", LongTermMonths: 12, FiscalStart: time.January, WashSales: true},
+	// Listed equity held more than 12 months is long-term; demat holdings are matched first in, first out
+	"IN": {Code: "IN", Name: "India", Currency: "INR", LongTermMonths: 12, FiscalStart: time.April, Method: MethodFIFO},
+}
+
+// LookupJurisdiction returns the rules for a code such as US or IN
+func LookupJurisdiction(code string) (Jurisdiction, bool) {
+	j, ok := Jurisdictions[strings.ToUpper(strings.TrimSpace(code))]
+	return j, ok
+}
+
+// IsLongTerm reports whether a position opened on open and closed on close was held
+// longer than the jurisdiction's long-term period
+func (j Jurisdiction) IsLongTerm(open, close string) bool {
+	o, err1 := time.Parse(dateLayout, open)
+	c, err2 := time.Parse(dateLayout, close)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	return c.After(o.AddDate(0, j.LongTermMonths, 0))
+}
+
+// FiscalYear returns the tax year containing date, named by the calendar year it starts in
+func (j Jurisdiction) FiscalYear(date time.Time) int {
+	if date.Month() < j.FiscalStart {
+		return date.Year() - 1
+	}
+	return date.Year()
+}
+
+// FiscalYearRange returns the first and last day (YYYY-MM-DD) of a tax year
+func (j Jurisdiction) FiscalYearRange(year int) (string, string) {
+	start := time.Date(year, j.FiscalStart, 1, 0, 0, 0, 0, time.UTC)
+	return start.Format(dateLayout), start.AddDate(1, 0, -1).Format(dateLayout)
+}
+
+// FiscalYearLabel names a tax year: 2024 for a calendar year, FY 2024-25 otherwise
+func (j Jurisdiction) FiscalYearLabel(year int) string {
+	if j.FiscalStart == time.January {
+		return fmt.Sprint(year)
+	}
+	return fmt.Sprintf("FY %d-%02d", year, (year+1)%100)
+}
@@ -0,0 +1,116 @@
+package ledger
+
+import (
+	"strings"
+	"time"
+
+	"tinystock/backend/internal/decimal"
+	"tinystock/backend/models"
+)
+
+// WashSaleDays is how close to a loss sale, before or after, a purchase of the same
+// symbol makes it a wash sale
+const WashSaleDays = 30
+
+// washAdjustment is disallowed loss per share owed to part of a buy not yet replayed
+type washAdjustment struct {
+	quantity decimal.Decimal
+	perShare decimal.Decimal
+}
+
+// washSale disallows g's loss on as many shares as were repurchased around the sale.
+// Replacement shares are matched in acquisition order: first open lots bought in the
+// WashSaleDays before the sale, then buys in the WashSaleDays after it. Each share
+// replaces at most one sold share, and shares bought together with the sold lot
+// never replace it. The disallowed loss moves into the replacement shares' cost.
+func (b *Book) washSale(symbol string, g *models.RealizedGain, soldLot int64) {
+	day, err := time.Parse(dateLayout, g.CloseDate)
+	if err != nil {
+		return
+	}
+	from := day.AddDate(0, 0, -WashSaleDays).Format(dateLayout)
+	to := day.AddDate(0, 0, WashSaleDays).Format(dateLayout)
+	perShare := g.Gain.Neg().Div(g.Quantity, decimal.Precision)
+	remaining := g.Quantity
+
+	lots := make([]models.Lot, 0, len(b.Lots[symbol]))
+	for _, l := range b.Lots[symbol] {
+		if remaining.Sign() <= 0 || l.WashSaleAdjustment.Sign() > 0 || !b.bought[l.TransactionID] ||
+			l.TransactionID == soldLot || l.OpenDate < from || l.OpenDate > g.CloseDate {
+			lots = append(lots, l)
+			continue
+		}
+		q := decimal.Min(l.Quantity, remaining)
+		replaced := l
+		replaced.Quantity = q
+		replaced.CostPerShare = replaced.CostPerShare.Add(perShare)
+		replaced.WashSaleAdjustment = perShare
+		lots = append(lots, replaced)
+		if l.Quantity.Cmp(q) > 0 {
+			l.Quantity = l.Quantity.Sub(q)
+			lots = append(lots, l)
+		}
+		remaining = remaining.Sub(q)
+	}
+	if len(lots) > 0 {
+		b.Lots[symbol] = lots
+	}
+
+	for i := b.next; i < len(b.ordered) && remaining.Sign() > 0; i++ {
+		t := b.ordered[i]
+		if t.TradeDate > to {
+			break
+		}
+		if !strings.EqualFold(t.Symbol, symbol) {
+			continue
+		}
+		if t.Type == models.TxSplit {
+			// Share counts on either side of a split are not comparable
+			break
+		}
+		if t.Type != models.TxBuy {
+			continue
+		}
+		free := t.Quantity
+		for _, a := range b.pending[i] {
+			free = free.Sub(a.quantity)
+		}
+		if free.Sign() <= 0 {
+			continue
+		}
+		q := decimal.Min(free, remaining)
+		b.pending[i] = append(b.pending[i], washAdjustment{quantity: q, perShare: perShare})
+		remaining = remaining.Sub(q)
+	}
+
+	// Prorate the loss itself rather than multiplying the rounded per-share figure,
+	// so a fully washed sale disallows exactly its loss
+	disallowed := g.Gain.Neg().Mul(g.Quantity.Sub(remaining)).Div(g.Quantity, decimal.Precision)
+	if disallowed.Sign() > 0 {
+		g.WashSaleDisallowed = disallowed
+		g.Gain = g.Gain.Add(disallowed)
+	}
+}
+
+// replacements splits the lot opened by the i'th transaction into the parts that
+// replace earlier loss sales, each carrying its disallowed loss, and the rest
+func (b *Book) replacements(lot models.Lot, i int) []models.Lot {
+	adjustments := b.pending[i]
+	if len(adjustments) == 0 {
+		return []models.Lot{lot}
+	}
+	delete(b.pending, i)
+	lots := make([]models.Lot, 0, len(adjustments)+1)
+	for _, a := range adjustments {
+		replaced := lot
+		replaced.Quantity = a.quantity
+		replaced.CostPerShare = replaced.CostPerShare.Add(a.perShare)
+		replaced.WashSaleAdjustment = a.perShare
+		lots = append(lots, replaced)
+		lot.Quantity = lot.Quantity.Sub(a.quantity)
+	}
+	if lot.Quantity.Sign() > 0 {
+		lots = append(lots, lot)
+	}
+	return lots
+}
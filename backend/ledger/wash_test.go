@@ -0,0 +1,157 @@
+package ledger
+
+import (
+	"testing"
+
+	"tinystock/backend/models"
+)
+
+func TestReplayWashSales(t *testing.T) {
+	type wantWash struct{ gain, disallowed string }
+	tests := []struct {
+		name     string
+		txs      []models.Transaction
+		disabled bool // the portfolio does not apply wash sale rules
+		gains    []wantWash
+		lots     []wantLot
+	}{
+		{
+			name: "repurchase before the loss sale",
+			txs: []models.Transaction{
+				trade(1, models.TxBuy, "2023-01-03", "10", "100"),
+				trade(2, models.TxBuy, "2023-02-20", "10", "80"),
+				trade(3, models.TxSell, "2023-03-01", "10", "70"),
+			},
+			gains: []wantWash{{"0", "300"}},
+			lots:  []wantLot{{2, "10", "110"}},
+		},
+		{
+			name: "repurchase after the loss sale",
+			txs: []models.Transaction{
+				trade(1, models.TxBuy, "2023-01-03", "10", "100"),
+				trade(2, models.TxSell, "2023-03-01", "10", "70"),
+				trade(3, models.TxBuy, "2023-03-31", "10", "75"),
+			},
+			gains: []wantWash{{"0", "300"}},
+			lots:  []wantLot{{3, "10", "105"}},
+		},
+		{
+			name: "repurchase after the window",
+			txs: []models.Transaction{
+				trade(1, models.TxBuy, "2023-01-03", "10", "100"),
+				trade(2, models.TxSell, "2023-03-01", "10", "70"),
+				trade(3, models.TxBuy, "2023-04-01", "10", "75"),
+			},
+			gains: []wantWash{{"-300", "0"}},
+			lots:  []wantLot{{3, "10", "75"}},
+		},
+		{
+			name: "partial replacement",
+			txs: []models.Transaction{
+				trade(1, models.TxBuy, "2023-01-03", "10", "100"),
+				trade(2, models.TxSell, "2023-03-01", "10", "70"),
+				trade(3, models.TxBuy, "2023-03-15", "4", "75"),
+			},
+			gains: []wantWash{{"-180", "120"}},
+			lots:  []wantLot{{3, "4", "105"}},
+		},
+		{
+			// Only as many shares as were sold take on the loss
+			name: "replacement larger than the sale",
+			txs: []models.Transaction{
+				trade(1, models.TxBuy, "2023-01-03", "10", "100"),
+				trade(2, models.TxSell, "2023-03-01", "10", "70"),
+				trade(3, models.TxBuy, "2023-03-15", "15", "75"),
+			},
+			gains: []wantWash{{"0", "300"}},
+			lots:  []wantLot{{3, "10", "105"}, {3, "5", "75"}},
+		},
+		{
+			// The unsold rest of the lot was bought with the sold shares, not after them
+			name: "rest of the sold lot",
+			txs: []models.Transaction{
+				trade(1, models.TxBuy, "2023-02-20", "20", "100"),
+				trade(2, models.TxSell, "2023-03-01", "10", "70"),
+			},
+			gains: []wantWash{{"-300", "0"}},
+			lots:  []wantLot{{1, "10", "100"}},
+		},
+		{
+			// A split after the sale ends the search for replacements
+			name: "repurchase across a split",
+			txs: []models.Transaction{
+				trade(1, models.TxBuy, "2023-01-03", "10", "100"),
+				trade(2, models.TxSell, "2023-03-01", "10", "70"),
+				trade(3, models.TxSplit, "2023-03-10", "2", ""),
+				trade(4, models.TxBuy, "2023-03-15", "20", "40"),
+			},
+			gains: []wantWash{{"-300", "0"}},
+			lots:  []wantLot{{4, "20", "40"}},
+		},
+		{
+			// The adjustment is per share, so a split scales it with the cost
+			name: "replacement split after the sale",
+			txs: []models.Transaction{
+				trade(1, models.TxBuy, "2023-01-03", "10", "100"),
+				trade(2, models.TxBuy, "2023-02-20", "10", "80"),
+				trade(3, models.TxSell, "2023-03-01", "10", "70"),
+				trade(4, models.TxSplit, "2023-03-10", "2", ""),
+			},
+			gains: []wantWash{{"0", "300"}},
+			lots:  []wantLot{{2, "20", "55"}},
+		},
+		{
+			name: "gain sale",
+			txs: []models.Transaction{
+				trade(1, models.TxBuy, "2023-01-03", "10", "100"),
+				trade(2, models.TxSell, "2023-03-01", "10", "120"),
+				trade(3, models.TxBuy, "2023-03-15", "10", "110"),
+			},
+			gains: []wantWash{{"200", "0"}},
+			lots:  []wantLot{{3, "10", "110"}},
+		},
+		{
+			name: "another symbol",
+			txs: []models.Transaction{
+				trade(1, models.TxBuy, "2023-01-03", "10", "100"),
+				trade(2, models.TxSell, "2023-03-01", "10", "70"),
+				func() models.Transaction {
+					tx := trade(3, models.TxBuy, "2023-03-15", "10", "75")
+					tx.Symbol = "MSFT"
+					return tx
+				}(),
+			},
+			gains: []wantWash{{"-300", "0"}},
+		},
+		{
+			// Wash sales are a portfolio setting; a ledger replayed without it keeps the loss
+			name: "disabled for the portfolio",
+			txs: []models.Transaction{
+				trade(1, models.TxBuy, "2023-01-03", "10", "100"),
+				trade(2, models.TxSell, "2023-03-01", "10", "70"),
+				trade(3, models.TxBuy, "2023-03-15", "10", "75"),
+			},
+			disabled: true,
+			gains:    []wantWash{{"-300", "0"}},
+			lots:     []wantLot{{3, "10", "75"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := ReplayWith(tt.txs, Options{Method: MethodFIFO, WashSales: !tt.disabled})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(b.Realized) != len(tt.gains) {
+				t.Fatalf("got %d realized gains %+v, want %d", len(b.Realized), b.Realized, len(tt.gains))
+			}
+			for i, w := range tt.gains {
+				g := b.Realized[i]
+				if g.Gain.Cmp(d(w.gain)) != 0 || g.WashSaleDisallowed.Cmp(d(w.disallowed)) != 0 {
+					t.Errorf("gain %d = %s disallowed %s, want %s disallowed %s", i, g.Gain, g.WashSaleDisallowed, w.gain, w.disallowed)
+				}
+			}
+			checkLots(t, b.Lots["AAPL"], tt.lots)
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log"
 
 	"github.com/gin-gonic/gin"
@@ -25,15 +26,50 @@ func main() {
 
 	authService := services.NewAuthService(db, cfg.JWTSecret, cfg.JWTExpiry)
 	stockService := services.NewStockService()
+	fxService := services.NewFXService(stockService)
+	indexService, err := services.NewIndexService(stockService)
+	if err != nil {
+		log.Fatal("indices:", err)
+	}
+	universeService := services.NewUniverseService(db, indexService, cfg.MoversUniverse)
+	moversService := services.NewMoversService(universeService, stockService)
+	indicatorService := services.NewIndicatorService(stockService)
+	compareService := services.NewCompareService(stockService)
+	screenerService := services.NewScreenerService(db, stockService, indicatorService, indexService, universeService)
 	watchlistService := services.NewWatchlistService(db, stockService)
-	portfolioService := services.NewPortfolioService(db, stockService)
+	portfolioService := services.NewPortfolioService(db, db, db, db, stockService, fxService, cfg.BaseCurrency, cfg.CostBasisMethod)
+	snapshotService := services.NewSnapshotService(portfolioService, db)
+	snapshotService.Start(context.Background(), cfg.SnapshotHour)
+	performanceService := services.NewPerformanceService(portfolioService, snapshotService, stockService, fxService, cfg.RiskFreeRate)
+	allocationService := services.NewAllocationService(portfolioService, stockService, indexService)
+	rebalanceService := services.NewRebalanceService(portfolioService, db, stockService, indexService)
+	dividendService := services.NewDividendService(portfolioService, stockService)
+	feeService := services.NewFeeService(db)
+	importService := services.NewImportService(portfolioService)
+	exportService := services.NewExportService(portfolioService, db, fxService)
+	taxService := services.NewTaxService(portfolioService, fxService)
 
 	deps := &routes.Dependencies{
-		AuthHandler:      handlers.NewAuthHandler(authService),
-		StockHandler:     handlers.NewStockHandler(stockService),
-		WatchlistHandler: handlers.NewWatchlistHandler(watchlistService),
-		PortfolioHandler: handlers.NewPortfolioHandler(portfolioService),
-		AuthService:     authService,
+		AuthHandler:        handlers.NewAuthHandler(authService),
+		StockHandler:       handlers.NewStockHandler(stockService),
+		WatchlistHandler:   handlers.NewWatchlistHandler(watchlistService),
+		PortfolioHandler:   handlers.NewPortfolioHandler(portfolioService, performanceService),
+		TransactionHandler: handlers.NewTransactionHandler(portfolioService),
+		PerformanceHandler: handlers.NewPerformanceHandler(performanceService, snapshotService),
+		AllocationHandler:  handlers.NewAllocationHandler(allocationService),
+		RebalanceHandler:   handlers.NewRebalanceHandler(rebalanceService),
+		DividendHandler:    handlers.NewDividendHandler(dividendService),
+		FeeHandler:         handlers.NewFeeHandler(feeService),
+		ImportHandler:      handlers.NewImportHandler(importService),
+		ExportHandler:      handlers.NewExportHandler(exportService),
+		TaxHandler:         handlers.NewTaxHandler(taxService),
+		FXHandler:          handlers.NewFXHandler(fxService),
+		IndexHandler:       handlers.NewIndexHandler(indexService),
+		MarketHandler:      handlers.NewMarketHandler(moversService),
+		IndicatorHandler:   handlers.NewIndicatorHandler(indicatorService),
+		ScreenerHandler:    handlers.NewScreenerHandler(screenerService),
+		CompareHandler:     handlers.NewCompareHandler(compareService),
+		AuthService:        authService,
 	}
 
 	if cfg.DBDriver == "postgres" {
@@ -0,0 +1,31 @@
+package models
+
+import "tinystock/backend/internal/decimal"
+
+// Allocation groupings
+const (
+	AllocationBySector    = "sector"
+	AllocationByAssetType = "assetType"
+	AllocationByCurrency  = "currency"
+	AllocationByExchange  = "exchange"
+	AllocationBySymbol    = "symbol"
+)
+
+// AllocationBucket is one slice of a portfolio; amounts are in the base currency
+type AllocationBucket struct {
+	Key     string          `json:"key"`
+	Value   decimal.Decimal `json:"value"`
+	Cost    decimal.Decimal `json:"cost"`
+	PnL     decimal.Decimal `json:"pnl"`
+	Weight  float64         `json:"weight"` // percent of total value, cash included
+	Symbols []string        `json:"symbols"`
+}
+
+// Allocation is a portfolio's value grouped by one attribute, largest bucket first
+type Allocation struct {
+	PortfolioID  int64              `json:"portfolioId"`
+	BaseCurrency string             `json:"baseCurrency"`
+	By           string             `json:"by"`
+	TotalValue   decimal.Decimal    `json:"totalValue"`
+	Buckets      []AllocationBucket `json:"buckets"`
+}
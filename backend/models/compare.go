@@ -0,0 +1,12 @@
+package models
+
+// Comparison is several symbols' performance on a shared date axis
+type Comparison struct {
+	Symbols     []string             `json:"symbols"`
+	Range       string               `json:"range"`
+	Dates       []string             `json:"dates"`
+	Series      map[string][]float64 `json:"series"`      // rebased to 100 at the first common date
+	Correlation [][]*float64         `json:"correlation"` // row/column order follows Symbols; null when undefined
+	Volatility  map[string]float64   `json:"volatility"`  // annualized, from each symbol's own trading days
+	Return      map[string]float64   `json:"return"`      // total return in percent over the common window
+}
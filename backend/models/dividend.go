@@ -0,0 +1,50 @@
+package models
+
+import "tinystock/backend/internal/decimal"
+
+// DividendEvent is a cash dividend reported by the market data provider. Amount is
+// per share in Currency; holders of record before ExDate receive it.
+type DividendEvent struct {
+	Symbol   string  `json:"symbol"`
+	ExDate   string  `json:"exDate"` // YYYY-MM-DD
+	Amount   float64 `json:"amount"`
+	Currency string  `json:"currency"`
+}
+
+// DividendSync lists the dividends recorded from provider events and the events
+// skipped because no shares were held or the ledger already had them
+type DividendSync struct {
+	PortfolioID int64         `json:"portfolioId"`
+	Recorded    []Transaction `json:"recorded"`
+	Skipped     int           `json:"skipped"`
+	// Errors lists symbols whose dividends could not be fetched
+	Errors []string `json:"errors"`
+}
+
+// HoldingIncome is one holding's dividend income; amounts are in the report's base
+// currency except the per-share figures, which are in the holding's currency
+type HoldingIncome struct {
+	Symbol           string          `json:"symbol"`
+	Quantity         decimal.Decimal `json:"quantity"`
+	Currency         string          `json:"currency"`
+	CostBasis        decimal.Decimal `json:"costBasis"`
+	MarketValue      decimal.Decimal `json:"marketValue"`
+	TrailingIncome   decimal.Decimal `json:"trailingIncome"`   // received in the last 12 months
+	YieldOnCost      float64         `json:"yieldOnCost"`      // percent
+	DividendPerShare decimal.Decimal `json:"dividendPerShare"` // declared over the last 12 months
+	ProjectedIncome  decimal.Decimal `json:"projectedIncome"`  // next 12 months at the current rate and share count
+	CurrentYield     float64         `json:"currentYield"`     // percent
+	LastExDate       string          `json:"lastExDate"`
+}
+
+// IncomeReport summarizes received and projected dividend income for a portfolio
+type IncomeReport struct {
+	PortfolioID     int64           `json:"portfolioId"`
+	BaseCurrency    string          `json:"baseCurrency"`
+	AsOf            string          `json:"asOf"`
+	TrailingIncome  decimal.Decimal `json:"trailingIncome"`
+	ProjectedIncome decimal.Decimal `json:"projectedIncome"`
+	YieldOnCost     float64         `json:"yieldOnCost"`
+	CurrentYield    float64         `json:"currentYield"`
+	Holdings        []HoldingIncome `json:"holdings"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// ExportFormat identifies TinyStock's JSON export; ExportVersion is its current
+// version, and imports accept any version up to it
+const (
+	ExportFormat  = "tinystock"
+	ExportVersion = 1
+)
+
+// Datasets that can be exported
+const (
+	ExportHoldings     = "holdings"
+	ExportTransactions = "transactions"
+	ExportWatchlist    = "watchlist"
+)
+
+// ExportHeader opens every JSON export. The records follow under a key named
+// after What. Portfolio and BaseCurrency are omitted for the watchlist.
+type ExportHeader struct {
+	Format       string     `json:"format"`
+	Version      int        `json:"version"`
+	What         string     `json:"what"`
+	ExportedAt   time.Time  `json:"exportedAt"`
+	Portfolio    *Portfolio `json:"portfolio,omitempty"`
+	BaseCurrency string     `json:"baseCurrency,omitempty"`
+}
+
+// TransactionExport is a JSON export of a portfolio's ledger, as read back on import
+type TransactionExport struct {
+	ExportHeader
+	Transactions []Transaction `json:"transactions"`
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"tinystock/backend/internal/decimal"
+)
+
+// Fee schedule kinds
+const (
+	FeeFlat    = "flat"    // a fixed amount per trade
+	FeePercent = "percent" // a percentage of the trade value
+	FeeTiered  = "tiered"  // marginal percentages over trade value brackets
+)
+
+// Charges are the costs of a transaction in its currency. They are added to the
+// cost basis of buys, deducted from the proceeds of sells and, for every type,
+// taken out of cash. Tax covers stamp duty, transaction taxes and dividend
+// withholding.
+type Charges struct {
+	Fee        decimal.Decimal `json:"fee"`
+	Commission decimal.Decimal `json:"commission"`
+	Tax        decimal.Decimal `json:"tax"`
+	// FeeScheduleID names the schedule the commission was computed with, if any
+	FeeScheduleID int64 `json:"feeScheduleId,omitempty"`
+}
+
+// FeeTier charges Percent on the part of a trade's value up to UpTo; the last
+// tier's UpTo is 0, meaning no upper bound
+type FeeTier struct {
+	UpTo    decimal.Decimal `json:"upTo"`
+	Percent decimal.Decimal `json:"percent"`
+}
+
+// FeeSchedule is a broker's commission rule. Flat, Minimum and Maximum are in
+// the trade's currency; a Maximum of 0 means no cap.
+type FeeSchedule struct {
+	ID        int64           `json:"id"`
+	UserID    string          `json:"-"`
+	Name      string          `json:"name"`
+	Kind      string          `json:"kind"`
+	Flat      decimal.Decimal `json:"flat"`
+	Percent   decimal.Decimal `json:"percent"`
+	Tiers     []FeeTier       `json:"tiers"`
+	Minimum   decimal.Decimal `json:"minimum"`
+	Maximum   decimal.Decimal `json:"maximum"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
@@ -0,0 +1,16 @@
+package models
+
+// FXRate represents the price of one unit of Base expressed in Quote currency
+type FXRate struct {
+	Base  string  `json:"base"`
+	Quote string  `json:"quote"`
+	Rate  float64 `json:"rate"`
+	Date  string  `json:"date,omitempty"`
+}
+
+// FXConversion is the result of converting an amount between currencies
+type FXConversion struct {
+	FXRate
+	Amount    float64 `json:"amount"`
+	Converted float64 `json:"converted"`
+}
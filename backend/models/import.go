@@ -0,0 +1,36 @@
+package models
+
+// ImportError is a problem with one row of an imported file; Row is the 1-based
+// line number, or 0 for problems that span rows such as an oversold position
+type ImportError struct {
+	Row     int    `json:"row"`
+	Message string `json:"message"`
+}
+
+// ImportRow is a parsed row. Duplicates match a transaction already in the
+// ledger and are not imported again.
+type ImportRow struct {
+	Row         int         `json:"row"`
+	Duplicate   bool        `json:"duplicate"`
+	Transaction Transaction `json:"transaction"`
+}
+
+// ImportResult is the preview of an import, or its outcome when not a dry run
+type ImportResult struct {
+	PortfolioID int64         `json:"portfolioId"`
+	Profile     string        `json:"profile"`
+	DryRun      bool          `json:"dryRun"`
+	Rows        []ImportRow   `json:"rows"`
+	Errors      []ImportError `json:"errors"`
+	Skipped     int           `json:"skipped"` // blank, summary and ignored rows
+	Duplicates  int           `json:"duplicates"`
+	Imported    int           `json:"imported"`
+}
+
+// ImportProfile describes a supported statement layout
+type ImportProfile struct {
+	Name        string              `json:"name"`
+	Description string              `json:"description"`
+	Columns     map[string][]string `json:"columns"` // field -> accepted headers
+	NewestFirst bool                `json:"newestFirst"`
+}
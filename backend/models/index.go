@@ -0,0 +1,26 @@
+package models
+
+// Index represents a market benchmark such as the S&P 500
+type Index struct {
+	ID               string `json:"id"`
+	Name             string `json:"name"`
+	Symbol           string `json:"symbol"`
+	Currency         string `json:"currency"`
+	Region           string `json:"region"`
+	ConstituentCount int    `json:"constituentCount"`
+	Quote            *Quote `json:"quote,omitempty"`
+}
+
+// Constituent is a member of an index
+type Constituent struct {
+	Symbol string `json:"symbol"`
+	Sector string `json:"sector"`
+	Quote  *Quote `json:"quote,omitempty"`
+}
+
+// IndexConstituents lists an index's members as of a bundled snapshot date
+type IndexConstituents struct {
+	Index        Index         `json:"index"`
+	AsOf         string        `json:"asOf"`
+	Constituents []Constituent `json:"constituents"`
+}
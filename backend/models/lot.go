@@ -0,0 +1,34 @@
+package models
+
+import "tinystock/backend/internal/decimal"
+
+// Lot is an open tax lot: shares acquired together that have not yet been sold
+type Lot struct {
+	Symbol        string          `json:"symbol"`
+	TransactionID int64           `json:"transactionId"`
+	OpenDate      string          `json:"openDate"`
+	Quantity      decimal.Decimal `json:"quantity"`
+	CostPerShare  decimal.Decimal `json:"costPerShare"`
+	Currency      string          `json:"currency"`
+	LongTerm      bool            `json:"longTerm"`
+	// WashSaleAdjustment is disallowed loss per share added to CostPerShare when
+	// these shares replaced shares sold at a loss
+	WashSaleAdjustment decimal.Decimal `json:"washSaleAdjustment"`
+}
+
+// RealizedGain is the part of a sale matched against one lot
+type RealizedGain struct {
+	Symbol        string          `json:"symbol"`
+	TransactionID int64           `json:"transactionId"` // the sell
+	OpenDate      string          `json:"openDate"`
+	CloseDate     string          `json:"closeDate"`
+	Quantity      decimal.Decimal `json:"quantity"`
+	Proceeds      decimal.Decimal `json:"proceeds"`
+	Cost          decimal.Decimal `json:"cost"`
+	Gain          decimal.Decimal `json:"gain"`
+	Currency      string          `json:"currency"`
+	LongTerm      bool            `json:"longTerm"`
+	// WashSaleDisallowed is the part of a loss deferred to replacement shares; it
+	// is already added back to Gain
+	WashSaleDisallowed decimal.Decimal `json:"washSaleDisallowed"`
+}
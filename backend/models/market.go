@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// MarketMovers ranks a symbol universe by daily change or volume
+type MarketMovers struct {
+	Universe     string    `json:"universe"`
+	Metric       string    `json:"metric"`
+	UniverseSize int       `json:"universeSize"`
+	Gainers      []*Quote  `json:"gainers,omitempty"`
+	Losers       []*Quote  `json:"losers,omitempty"`
+	MostActive   []*Quote  `json:"mostActive,omitempty"`
+	AsOf         time.Time `json:"asOf"`
+}
@@ -0,0 +1,73 @@
+package models
+
+// Performance is a portfolio's return over a period, separating investment results
+// from the money moved in and out
+type Performance struct {
+	PortfolioID   int64    `json:"portfolioId"`
+	BaseCurrency  string   `json:"baseCurrency"`
+	Period        string   `json:"period"`
+	From          string   `json:"from"` // first valued day; inception if the ledger starts later
+	To            string   `json:"to"`
+	StartValue    float64  `json:"startValue"`
+	EndValue      float64  `json:"endValue"`
+	NetFlows      float64  `json:"netFlows"`      // deposits less withdrawals; in-kind transfers at market value
+	Gain          float64  `json:"gain"`          // EndValue - StartValue - NetFlows
+	TWR           float64  `json:"twr"`           // time-weighted, cumulative percent
+	TWRAnnualized *float64 `json:"twrAnnualized"` // null for periods shorter than a year
+	MWR           *float64 `json:"mwr"`           // money-weighted (XIRR) percent, annualized for periods of a year or more; null when undefined
+}
+
+// BenchmarkStats compares a portfolio's daily time-weighted returns with a
+// benchmark's over the days both have data. Ratios that are undefined for the
+// period (e.g. too few observations) are null.
+type BenchmarkStats struct {
+	Symbol           string   `json:"symbol"`
+	Range            string   `json:"range"`
+	From             string   `json:"from"`
+	To               string   `json:"to"`
+	Observations     int      `json:"observations"`    // daily returns compared
+	PortfolioReturn  float64  `json:"portfolioReturn"` // cumulative percent
+	BenchmarkReturn  float64  `json:"benchmarkReturn"` // cumulative percent
+	Alpha            *float64 `json:"alpha"`           // annualized percent
+	Beta             *float64 `json:"beta"`
+	Correlation      *float64 `json:"correlation"`
+	TrackingError    float64  `json:"trackingError"` // annualized percent
+	InformationRatio *float64 `json:"informationRatio"`
+	UpCapture        *float64 `json:"upCapture"`   // percent
+	DownCapture      *float64 `json:"downCapture"` // percent
+}
+
+// RiskReport describes a portfolio's daily return distribution over a range.
+// Ratios that are undefined for the period are null.
+type RiskReport struct {
+	PortfolioID  int64       `json:"portfolioId"`
+	BaseCurrency string      `json:"baseCurrency"`
+	Range        string      `json:"range"`
+	From         string      `json:"from"`
+	To           string      `json:"to"`
+	Observations int         `json:"observations"` // daily returns
+	RiskFreeRate float64     `json:"riskFreeRate"` // annual percent
+	Volatility   float64     `json:"volatility"`   // annualized percent
+	Sharpe       *float64    `json:"sharpe"`
+	Sortino      *float64    `json:"sortino"`
+	MaxDrawdown  Drawdown    `json:"maxDrawdown"`
+	VaR          []VaRResult `json:"var"`
+}
+
+// Drawdown is the deepest peak-to-trough fall of the equity curve
+type Drawdown struct {
+	Depth    float64 `json:"depth"` // percent of the peak lost
+	Peak     string  `json:"peak"`
+	Trough   string  `json:"trough"`
+	Recovery string  `json:"recovery"` // empty while still below the peak
+}
+
+// VaRResult is one-day Value-at-Risk at a confidence level, as a percent of the
+// portfolio and as an amount of its latest value in the base currency
+type VaRResult struct {
+	Confidence       float64 `json:"confidence"` // percent, e.g. 95
+	Historical       float64 `json:"historical"`
+	Parametric       float64 `json:"parametric"`
+	HistoricalAmount float64 `json:"historicalAmount"`
+	ParametricAmount float64 `json:"parametricAmount"`
+}
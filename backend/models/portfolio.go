@@ -1,29 +1,118 @@
 package models
 
-// Holding represents a portfolio holding
+import (
+	"time"
+
+	"tinystock/backend/internal/decimal"
+)
+
+// Portfolio is a named set of transactions. Empty BaseCurrency or CostBasisMethod
+// means the server defaults apply; StrictCash rejects transactions that would
+// overdraw a cash balance.
+type Portfolio struct {
+	ID              int64     `json:"id"`
+	UserID          string    `json:"-"`
+	Name            string    `json:"name"`
+	BaseCurrency    string    `json:"baseCurrency"`
+	CostBasisMethod string    `json:"costBasisMethod"`
+	StrictCash      bool      `json:"strictCash"`
+	WashSales       bool      `json:"washSales"` // defer losses on shares repurchased within 30 days
+	CreatedAt       time.Time `json:"createdAt"`
+}
+
+// CashBalance is the cash held in one currency. When no exchange rate is
+// available FXUnavailable is set, AmountBase is zero and the balance is left out
+// of the base totals.
+type CashBalance struct {
+	Currency      string          `json:"currency"`
+	Amount        decimal.Decimal `json:"amount"`
+	FXRate        float64         `json:"fxRate"`
+	AmountBase    decimal.Decimal `json:"amountBase"`
+	FXUnavailable bool            `json:"fxUnavailable,omitempty"`
+}
+
+// Holding is an open position derived from the transaction ledger; BuyPrice is the average cost
 type Holding struct {
-	ID       int64   `json:"id"`
-	UserID   string  `json:"-"`
-	Symbol   string  `json:"symbol"`
-	Quantity float64 `json:"quantity"`
-	BuyPrice float64 `json:"buyPrice"`
+	UserID   string          `json:"-"`
+	Symbol   string          `json:"symbol"`
+	Quantity decimal.Decimal `json:"quantity"`
+	BuyPrice decimal.Decimal `json:"buyPrice"`
+	Currency string          `json:"currency"`
 }
 
-// HoldingWithQuote extends Holding with current quote data for P&L calculation
+// HoldingWithQuote extends Holding with current quote data for P&L calculation.
+// As with CashBalance, FXUnavailable marks a holding shown only in its own
+// currency and left out of the base totals.
 type HoldingWithQuote struct {
 	Holding
-	CurrentPrice float64 `json:"currentPrice"`
-	MarketValue  float64 `json:"marketValue"`
-	CostBasis    float64 `json:"costBasis"`
-	PnL          float64 `json:"pnl"`
-	PnLPercent   float64 `json:"pnlPercent"`
+	CurrentPrice    decimal.Decimal `json:"currentPrice"`
+	MarketValue     decimal.Decimal `json:"marketValue"`
+	CostBasis       decimal.Decimal `json:"costBasis"`
+	PnL             decimal.Decimal `json:"pnl"`
+	PnLPercent      float64         `json:"pnlPercent"`
+	FXRate          float64         `json:"fxRate"`
+	MarketValueBase decimal.Decimal `json:"marketValueBase"`
+	CostBasisBase   decimal.Decimal `json:"costBasisBase"`
+	// Unrealized P&L split by holding period, in the holding's currency
+	UnrealizedShortTerm decimal.Decimal `json:"unrealizedShortTerm"`
+	UnrealizedLongTerm  decimal.Decimal `json:"unrealizedLongTerm"`
+	Lots                []Lot           `json:"lots"`
+	FXUnavailable       bool            `json:"fxUnavailable,omitempty"`
 }
 
-// PortfolioSummary holds the full portfolio view
+// PortfolioSummary holds the full portfolio view; totals are in BaseCurrency.
+// TotalValue includes cash; TotalCost, TotalPnL and ReturnPct cover holdings only.
 type PortfolioSummary struct {
-	Holdings   []HoldingWithQuote `json:"holdings"`
-	TotalValue float64           `json:"totalValue"`
-	TotalCost  float64           `json:"totalCost"`
-	TotalPnL   float64           `json:"totalPnL"`
-	ReturnPct  float64           `json:"returnPct"`
+	PortfolioID   int64              `json:"portfolioId"`
+	PortfolioName string             `json:"portfolioName"`
+	Holdings      []HoldingWithQuote `json:"holdings"`
+	Cash          []CashBalance      `json:"cash"`
+	BaseCurrency  string             `json:"baseCurrency"`
+	CashValue     decimal.Decimal    `json:"cashValue"`
+	HoldingsValue decimal.Decimal    `json:"holdingsValue"`
+	TotalValue    decimal.Decimal    `json:"totalValue"`
+	TotalCost     decimal.Decimal    `json:"totalCost"`
+	TotalPnL      decimal.Decimal    `json:"totalPnL"`
+	ReturnPct     float64            `json:"returnPct"`
+	// CostBasisMethod is how sells were matched to lots (fifo, lifo, hifo or average)
+	CostBasisMethod     string          `json:"costBasisMethod"`
+	RealizedPnL         decimal.Decimal `json:"realizedPnL"`
+	RealizedShortTerm   decimal.Decimal `json:"realizedShortTerm"`
+	RealizedLongTerm    decimal.Decimal `json:"realizedLongTerm"`
+	UnrealizedShortTerm decimal.Decimal `json:"unrealizedShortTerm"`
+	UnrealizedLongTerm  decimal.Decimal `json:"unrealizedLongTerm"`
+	// Benchmark is set when the request names a benchmark symbol
+	Benchmark *BenchmarkStats `json:"benchmark,omitempty"`
+	// Unconverted lists currencies with no exchange rate to BaseCurrency; amounts
+	// in them are excluded from the totals above
+	Unconverted []string `json:"unconverted,omitempty"`
+}
+
+// ConsolidatedSummary combines every portfolio a user owns; totals are in BaseCurrency
+type ConsolidatedSummary struct {
+	BaseCurrency string             `json:"baseCurrency"`
+	Portfolios   []PortfolioSummary `json:"portfolios"`
+	CashValue    decimal.Decimal    `json:"cashValue"`
+	TotalValue   decimal.Decimal    `json:"totalValue"`
+	TotalCost    decimal.Decimal    `json:"totalCost"`
+	TotalPnL     decimal.Decimal    `json:"totalPnL"`
+	ReturnPct    float64            `json:"returnPct"`
+	RealizedPnL  decimal.Decimal    `json:"realizedPnL"`
+	Unconverted  []string           `json:"unconverted,omitempty"`
+}
+
+// RealizedReport lists gains from closed lots; totals are in BaseCurrency
+type RealizedReport struct {
+	PortfolioID     int64           `json:"portfolioId"`
+	CostBasisMethod string          `json:"costBasisMethod"`
+	BaseCurrency    string          `json:"baseCurrency"`
+	Gains           []RealizedGain  `json:"gains"`
+	ShortTerm       decimal.Decimal `json:"shortTerm"`
+	LongTerm        decimal.Decimal `json:"longTerm"`
+	Total           decimal.Decimal `json:"total"`
+	WashSales       bool            `json:"washSales"`
+	// WashSaleDisallowed is the loss deferred to replacement lots, in BaseCurrency
+	WashSaleDisallowed decimal.Decimal `json:"washSaleDisallowed"`
+	// Unconverted lists currencies with no exchange rate; their gains are left out of the totals
+	Unconverted []string `json:"unconverted,omitempty"`
 }
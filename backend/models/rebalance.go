@@ -0,0 +1,70 @@
+package models
+
+// Target kinds
+const (
+	TargetSymbol = "symbol"
+	TargetSector = "sector"
+)
+
+// Rebalancing modes and share rounding
+const (
+	RebalanceFull       = "full"      // buy and sell to reach targets
+	RebalanceCashOnly   = "cash-only" // only buy, with the cash on hand
+	RoundingWhole       = "whole"
+	RoundingFractional  = "fractional"
+	OrderBuy            = "buy"
+	OrderSell           = "sell"
+	DefaultDriftPercent = 5.0
+)
+
+// Target is a desired weight for a symbol or sector in a portfolio. Weights of
+// one portfolio sum to at most 100; the remainder is held as cash. Like other
+// percentages they are float64.
+type Target struct {
+	ID          int64   `json:"id"`
+	PortfolioID int64   `json:"portfolioId"`
+	Kind        string  `json:"kind"` // symbol or sector
+	Key         string  `json:"key"`
+	Weight      float64 `json:"weight"`    // percent
+	Threshold   float64 `json:"threshold"` // drift in percentage points that triggers rebalancing; 0 uses the default
+}
+
+// TargetDrift is how far a target's bucket sits from its weight
+type TargetDrift struct {
+	Kind          string   `json:"kind"`
+	Key           string   `json:"key"`
+	TargetWeight  float64  `json:"targetWeight"`
+	CurrentWeight float64  `json:"currentWeight"`
+	Drift         float64  `json:"drift"` // current less target, percentage points
+	Threshold     float64  `json:"threshold"`
+	Breached      bool     `json:"breached"`
+	Symbols       []string `json:"symbols"`
+}
+
+// RebalanceOrder is a suggested trade; Value is in the base currency
+type RebalanceOrder struct {
+	Symbol   string  `json:"symbol"`
+	Side     string  `json:"side"`
+	Quantity float64 `json:"quantity"`
+	Price    float64 `json:"price"`
+	Currency string  `json:"currency"`
+	Value    float64 `json:"value"`
+}
+
+// Rebalance is the set of orders that brings breached targets back to weight
+type Rebalance struct {
+	PortfolioID  int64            `json:"portfolioId"`
+	BaseCurrency string           `json:"baseCurrency"`
+	Mode         string           `json:"mode"`
+	Rounding     string           `json:"rounding"`
+	MinTrade     float64          `json:"minTrade"`
+	TotalValue   float64          `json:"totalValue"`
+	CashBefore   float64          `json:"cashBefore"`
+	CashAfter    float64          `json:"cashAfter"`
+	Drift        []TargetDrift    `json:"drift"`
+	Orders       []RebalanceOrder `json:"orders"`
+	// Unfilled lists targets that need buying but hold no symbol to buy (e.g. an empty sector)
+	Unfilled []string `json:"unfilled"`
+	// Unconverted lists currencies with no exchange rate; targets holding them get no orders
+	Unconverted []string `json:"unconverted,omitempty"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// Screen is a saved screener filter
+type Screen struct {
+	ID        int64     `json:"id"`
+	UserID    string    `json:"-"`
+	Name      string    `json:"name"`
+	Filter    string    `json:"filter"`
+	Universe  string    `json:"universe"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// ScreenRequest runs either an ad-hoc filter or a saved screen
+type ScreenRequest struct {
+	Filter   string `json:"filter"`
+	ScreenID int64  `json:"screenId"`
+	Universe string `json:"universe"`
+	SortBy   string `json:"sortBy"`
+	Order    string `json:"order"` // asc or desc
+	Page     int    `json:"page"`
+	PageSize int    `json:"pageSize"` // 25 by default, at most 100
+}
+
+// ScreenMatch is a symbol that passed the filter, with the fields the filter referenced
+type ScreenMatch struct {
+	Symbol string                 `json:"symbol"`
+	Quote  *Quote                 `json:"quote"`
+	Values map[string]interface{} `json:"values"`
+}
+
+// ScreenPage is one page of screener results
+type ScreenPage struct {
+	Filter   string        `json:"filter"`
+	Universe string        `json:"universe"`
+	Scanned  int           `json:"scanned"`
+	Total    int           `json:"total"`
+	Page     int           `json:"page"`
+	PageSize int           `json:"pageSize"`
+	Results  []ScreenMatch `json:"results"`
+}
@@ -0,0 +1,23 @@
+package models
+
+// PortfolioSnapshot is a portfolio's valuation at one day's close, in the
+// portfolio's base currency. Amounts are float64 like the valuation series they are
+// read from, which prices shares at closes and converts at that day's FX rate.
+type PortfolioSnapshot struct {
+	PortfolioID  int64   `json:"portfolioId"`
+	Date         string  `json:"date"`
+	BaseCurrency string  `json:"baseCurrency"`
+	TotalValue   float64 `json:"totalValue"` // holdings plus cash
+	TotalCost    float64 `json:"totalCost"`  // cost basis of open lots
+	Cash         float64 `json:"cash"`
+	PnL          float64 `json:"pnl"`     // unrealized: holdings value less cost
+	NetFlow      float64 `json:"netFlow"` // money deposited (+) or withdrawn (-) that day
+}
+
+// PortfolioHistory is a portfolio's equity curve
+type PortfolioHistory struct {
+	PortfolioID  int64               `json:"portfolioId"`
+	BaseCurrency string              `json:"baseCurrency"`
+	Range        string              `json:"range"`
+	Snapshots    []PortfolioSnapshot `json:"snapshots"`
+}
@@ -1,20 +1,48 @@
 package models
 
+// Asset types as reported by the market data provider
+const (
+	AssetEquity   = "EQUITY"
+	AssetETF      = "ETF"
+	AssetCrypto   = "CRYPTOCURRENCY"
+	AssetCurrency = "CURRENCY"
+	AssetIndex    = "INDEX"
+)
+
+// Change windows describe what Quote.Change is measured against
+const (
+	ChangeWindowSession = "session" // since the previous close
+	ChangeWindow24h     = "24h"     // rolling 24 hours, for instruments that trade around the clock
+)
+
 // Quote represents a stock quote
 type Quote struct {
-	Symbol    string  `json:"symbol"`
-	Name      string  `json:"name"`
-	Price     float64 `json:"price"`
-	Change    float64 `json:"change"`
-	ChangePct float64 `json:"changePercent"`
-	Volume    int64   `json:"volume"`
-	High      float64 `json:"high"`
-	Low       float64 `json:"low"`
+	Symbol       string  `json:"symbol"`
+	Name         string  `json:"name"`
+	Price        float64 `json:"price"`
+	Change       float64 `json:"change"`
+	ChangePct    float64 `json:"changePercent"`
+	Volume       int64   `json:"volume"`
+	High         float64 `json:"high"`
+	Low          float64 `json:"low"`
+	Currency     string  `json:"currency"`
+	AssetType    string  `json:"assetType"`
+	Exchange     string  `json:"exchange"` // listing venue, e.g. "NasdaqGS"; empty when unknown
+	ChangeWindow string  `json:"changeWindow"`
+	MarketOpen   bool    `json:"marketOpen"`
+	// Fundamentals are zero when the provider does not report them
+	MarketCap     int64   `json:"marketCap"`
+	PE            float64 `json:"pe"`
+	DividendYield float64 `json:"dividendYield"`
 }
 
 // HistoryPoint represents a single point in price history
 type HistoryPoint struct {
 	Date   string  `json:"date"`
+	Time   int64   `json:"time"`
+	Open   float64 `json:"open"`
+	High   float64 `json:"high"`
+	Low    float64 `json:"low"`
 	Close  float64 `json:"close"`
 	Volume int64   `json:"volume"`
 }
@@ -0,0 +1,53 @@
+package models
+
+import "tinystock/backend/internal/decimal"
+
+// TaxLot is one realized gain as reported for tax: a sale matched against one lot,
+// with amounts in the report currency
+type TaxLot struct {
+	Symbol      string          `json:"symbol"`
+	Quantity    decimal.Decimal `json:"quantity"`
+	OpenDate    string          `json:"openDate"`
+	CloseDate   string          `json:"closeDate"`
+	HoldingDays int             `json:"holdingDays"`
+	LongTerm    bool            `json:"longTerm"`
+	Currency    string          `json:"currency"` // trading currency
+	Proceeds    decimal.Decimal `json:"proceeds"`
+	Cost        decimal.Decimal `json:"cost"`
+	// Adjustment is wash sale loss disallowed on this lot, added back to Gain
+	Adjustment decimal.Decimal `json:"adjustment"`
+	Code       string          `json:"code,omitempty"` // W for a wash sale
+	Gain       decimal.Decimal `json:"gain"`
+}
+
+// TaxTotals sums a group of tax lots; Losses is negative
+type TaxTotals struct {
+	Proceeds    decimal.Decimal `json:"proceeds"`
+	Cost        decimal.Decimal `json:"cost"`
+	Adjustments decimal.Decimal `json:"adjustments"`
+	Gains       decimal.Decimal `json:"gains"`
+	Losses      decimal.Decimal `json:"losses"`
+	Net         decimal.Decimal `json:"net"`
+}
+
+// TaxReport lists a fiscal year's realized gains under a jurisdiction's rules.
+// Costs are converted at the rate on the purchase date and proceeds at the rate on
+// the sale date.
+type TaxReport struct {
+	PortfolioID      int64     `json:"portfolioId"`
+	PortfolioName    string    `json:"portfolioName"`
+	Jurisdiction     string    `json:"jurisdiction"`
+	JurisdictionName string    `json:"jurisdictionName"`
+	FiscalYear       int       `json:"fiscalYear"`
+	FiscalYearLabel  string    `json:"fiscalYearLabel"`
+	From             string    `json:"from"`
+	To               string    `json:"to"`
+	Currency         string    `json:"currency"`
+	CostBasisMethod  string    `json:"costBasisMethod"`
+	LongTermMonths   int       `json:"longTermMonths"`
+	WashSales        bool      `json:"washSales"`
+	ShortTerm        TaxTotals `json:"shortTerm"`
+	LongTerm         TaxTotals `json:"longTerm"`
+	Total            TaxTotals `json:"total"`
+	Lots             []TaxLot  `json:"lots"`
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"tinystock/backend/internal/decimal"
+)
+
+// Transaction types recorded in the ledger
+const (
+	TxBuy         = "buy"
+	TxSell        = "sell"
+	TxDividend    = "dividend"
+	TxFee         = "fee"
+	TxSplit       = "split"
+	TxTransferIn  = "transfer_in"
+	TxTransferOut = "transfer_out"
+	TxDeposit     = "deposit"
+	TxWithdrawal  = "withdrawal"
+)
+
+// Transaction is one ledger entry. Quantity is the share count for trades and
+// transfers and the split ratio for splits (2 for a 2-for-1); Amount is the
+// cash amount for dividends, fees, deposits and withdrawals; Charges are costs on
+// top of it. Deposits, withdrawals and account-level fees have no symbol.
+type Transaction struct {
+	ID          int64           `json:"id"`
+	UserID      string          `json:"-"`
+	PortfolioID int64           `json:"portfolioId"`
+	Symbol      string          `json:"symbol"`
+	Type        string          `json:"type"`
+	Quantity    decimal.Decimal `json:"quantity"`
+	Price       decimal.Decimal `json:"price"`
+	Amount      decimal.Decimal `json:"amount"`
+	Currency    string          `json:"currency"`
+	TradeDate   string          `json:"tradeDate"` // YYYY-MM-DD
+	Note        string          `json:"note"`
+	Version     int64           `json:"version"` // incremented by every edit
+	CreatedAt   time.Time       `json:"createdAt"`
+	Charges
+}
+
+// FieldChange is one field of an edited transaction, before and after
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// TransactionChange is the audit record of one edit to a transaction
+type TransactionChange struct {
+	ID            int64         `json:"id"`
+	TransactionID int64         `json:"transactionId"`
+	PortfolioID   int64         `json:"portfolioId"`
+	Version       int64         `json:"version"` // the version the edit produced
+	Changes       []FieldChange `json:"changes"`
+	ChangedAt     time.Time     `json:"changedAt"`
+}
@@ -3,6 +3,7 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 
 	"github.com/google/uuid"
@@ -55,23 +56,202 @@ func (d *DB) migrate() error {
 			symbol VARCHAR(20) NOT NULL,
 			UNIQUE(user_id, symbol)
 		)`,
-		`CREATE TABLE IF NOT EXISTS holdings (
+		`CREATE TABLE IF NOT EXISTS portfolios (
 			id SERIAL PRIMARY KEY,
 			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			name VARCHAR(100) NOT NULL,
+			base_currency VARCHAR(10) NOT NULL DEFAULT '',
+			cost_basis_method VARCHAR(20) NOT NULL DEFAULT '',
+			strict_cash BOOLEAN NOT NULL DEFAULT FALSE,
+			wash_sales BOOLEAN NOT NULL DEFAULT FALSE,
+			created_at TIMESTAMP DEFAULT NOW(),
+			UNIQUE(user_id, name)
+		)`,
+		`CREATE TABLE IF NOT EXISTS transactions (
+			id SERIAL PRIMARY KEY,
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			portfolio_id INTEGER REFERENCES portfolios(id) ON DELETE CASCADE,
 			symbol VARCHAR(20) NOT NULL,
-			quantity DECIMAL(18,6) NOT NULL,
-			buy_price DECIMAL(18,6) NOT NULL,
+			type VARCHAR(20) NOT NULL,
+			quantity DECIMAL(28,10) NOT NULL DEFAULT 0,
+			price DECIMAL(28,10) NOT NULL DEFAULT 0,
+			amount DECIMAL(28,10) NOT NULL DEFAULT 0,
+			currency VARCHAR(10) NOT NULL DEFAULT '',
+			trade_date DATE NOT NULL,
+			note TEXT NOT NULL DEFAULT '',
+			fee DECIMAL(28,10) NOT NULL DEFAULT 0,
+			commission DECIMAL(28,10) NOT NULL DEFAULT 0,
+			tax DECIMAL(28,10) NOT NULL DEFAULT 0,
+			fee_schedule_id INTEGER NOT NULL DEFAULT 0,
+			version INTEGER NOT NULL DEFAULT 1,
 			created_at TIMESTAMP DEFAULT NOW()
 		)`,
+		`CREATE INDEX IF NOT EXISTS idx_transactions_user_date ON transactions(user_id, trade_date)`,
+		`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS portfolio_id INTEGER REFERENCES portfolios(id) ON DELETE CASCADE`,
+		`ALTER TABLE portfolios ADD COLUMN IF NOT EXISTS strict_cash BOOLEAN NOT NULL DEFAULT FALSE`,
+		`ALTER TABLE portfolios ADD COLUMN IF NOT EXISTS wash_sales BOOLEAN NOT NULL DEFAULT FALSE`,
+		`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS fee DECIMAL(28,10) NOT NULL DEFAULT 0`,
+		`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS commission DECIMAL(28,10) NOT NULL DEFAULT 0`,
+		`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS tax DECIMAL(28,10) NOT NULL DEFAULT 0`,
+		`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS fee_schedule_id INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1`,
+		`CREATE TABLE IF NOT EXISTS transaction_changes (
+			id SERIAL PRIMARY KEY,
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			transaction_id INTEGER NOT NULL,
+			portfolio_id INTEGER NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+			version INTEGER NOT NULL,
+			changes JSONB NOT NULL,
+			changed_at TIMESTAMP DEFAULT NOW()
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_transaction_changes_tx ON transaction_changes(transaction_id)`,
+		`CREATE TABLE IF NOT EXISTS portfolio_snapshots (
+			id SERIAL PRIMARY KEY,
+			portfolio_id INTEGER NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+			date DATE NOT NULL,
+			base_currency VARCHAR(10) NOT NULL,
+			total_value DECIMAL(20,6) NOT NULL DEFAULT 0,
+			total_cost DECIMAL(20,6) NOT NULL DEFAULT 0,
+			cash DECIMAL(20,6) NOT NULL DEFAULT 0,
+			pnl DECIMAL(20,6) NOT NULL DEFAULT 0,
+			net_flow DECIMAL(20,6) NOT NULL DEFAULT 0,
+			created_at TIMESTAMP DEFAULT NOW(),
+			UNIQUE(portfolio_id, date)
+		)`,
+		`CREATE TABLE IF NOT EXISTS portfolio_targets (
+			id SERIAL PRIMARY KEY,
+			portfolio_id INTEGER NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+			kind VARCHAR(10) NOT NULL,
+			key VARCHAR(100) NOT NULL,
+			weight DECIMAL(9,4) NOT NULL,
+			threshold DECIMAL(9,4) NOT NULL DEFAULT 0,
+			created_at TIMESTAMP DEFAULT NOW(),
+			UNIQUE(portfolio_id, kind, key)
+		)`,
+		`CREATE TABLE IF NOT EXISTS screens (
+			id SERIAL PRIMARY KEY,
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			name VARCHAR(100) NOT NULL,
+			filter TEXT NOT NULL,
+			universe VARCHAR(20) NOT NULL DEFAULT 'watched',
+			created_at TIMESTAMP DEFAULT NOW(),
+			UNIQUE(user_id, name)
+		)`,
+		`CREATE TABLE IF NOT EXISTS fee_schedules (
+			id SERIAL PRIMARY KEY,
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			name VARCHAR(100) NOT NULL,
+			kind VARCHAR(10) NOT NULL,
+			flat DECIMAL(28,10) NOT NULL DEFAULT 0,
+			percent DECIMAL(9,6) NOT NULL DEFAULT 0,
+			tiers TEXT NOT NULL DEFAULT '[]',
+			minimum DECIMAL(28,10) NOT NULL DEFAULT 0,
+			maximum DECIMAL(28,10) NOT NULL DEFAULT 0,
+			created_at TIMESTAMP DEFAULT NOW(),
+			UNIQUE(user_id, name)
+		)`,
 	}
 	for _, q := range queries {
 		if _, err := d.conn.Exec(q); err != nil {
 			return err
 		}
 	}
+	if err := d.widenDecimalColumns(); err != nil {
+		return err
+	}
+	if err := d.migrateHoldingsToTransactions(); err != nil {
+		return err
+	}
+	return d.assignDefaultPortfolios()
+}
+
+// decimalColumns were declared with less scale before amounts became decimal
+var decimalColumns = []struct{ table, column string }{
+	{"transactions", "price"}, {"transactions", "amount"},
+	{"transactions", "fee"}, {"transactions", "commission"}, {"transactions", "tax"},
+	{"fee_schedules", "flat"}, {"fee_schedules", "minimum"}, {"fee_schedules", "maximum"},
+}
+
+// widenDecimalColumns converts decimalColumns still declared narrower to DECIMAL(28,10).
+// ALTER COLUMN TYPE rewrites the table, so columns already converted are left alone.
+func (d *DB) widenDecimalColumns() error {
+	for _, c := range decimalColumns {
+		var precision, scale sql.NullInt64
+		err := d.conn.QueryRow(
+			`SELECT numeric_precision, numeric_scale FROM information_schema.columns
+			 WHERE table_schema = current_schema() AND table_name = $1 AND column_name = $2`,
+			c.table, c.column,
+		).Scan(&precision, &scale)
+		if err != nil {
+			return fmt.Errorf("inspect %s.%s: %w", c.table, c.column, err)
+		}
+		if precision.Int64 == 28 && scale.Int64 == 10 {
+			continue
+		}
+		if _, err := d.conn.Exec(fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE DECIMAL(28,10)", c.table, c.column)); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// assignDefaultPortfolios moves transactions recorded before portfolios existed into a
+// "Default" portfolio per user
+func (d *DB) assignDefaultPortfolios() error {
+	tx, err := d.conn.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(
+		`INSERT INTO portfolios (user_id, name)
+		 SELECT DISTINCT user_id, 'Default' FROM transactions WHERE portfolio_id IS NULL
+		 ON CONFLICT (user_id, name) DO NOTHING`,
+	); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(
+		`UPDATE transactions t SET portfolio_id = p.id
+		 FROM portfolios p
+		 WHERE t.portfolio_id IS NULL AND p.user_id = t.user_id AND p.name = 'Default'`,
+	); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// migrateHoldingsToTransactions replaces the pre-ledger holdings table with one buy
+// transaction per row, dated from when the row was created.
+func (d *DB) migrateHoldingsToTransactions() error {
+	var exists bool
+	if err := d.conn.QueryRow("SELECT to_regclass('holdings') IS NOT NULL").Scan(&exists); err != nil || !exists {
+		return err
+	}
+	tx, err := d.conn.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`ALTER TABLE holdings ADD COLUMN IF NOT EXISTS currency VARCHAR(10) NOT NULL DEFAULT ''`); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(
+		`INSERT INTO transactions (user_id, symbol, type, quantity, price, currency, trade_date, created_at)
+		 SELECT user_id, symbol, 'buy', quantity, buy_price, currency,
+		        COALESCE(created_at::date, CURRENT_DATE), COALESCE(created_at, NOW())
+		 FROM holdings
+		 ORDER BY id`,
+	); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DROP TABLE holdings"); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
 // Create implements UserRepository
 func (d *DB) Create(ctx context.Context, user *models.User) error {
 	user.ID = uuid.New().String()
@@ -143,34 +323,498 @@ func (d *DB) List(ctx context.Context, userID string) ([]models.WatchlistItem, e
 	return items, rows.Err()
 }
 
-// AddHolding implements PortfolioRepository
-func (d *DB) AddHolding(ctx context.Context, userID string, symbol string, quantity, buyPrice float64) error {
-	_, err := d.conn.ExecContext(ctx, "INSERT INTO holdings (user_id, symbol, quantity, buy_price) VALUES ($1, $2, $3, $4)",
-		userID, symbol, quantity, buyPrice)
+// CreatePortfolio implements PortfolioRepository
+func (d *DB) CreatePortfolio(ctx context.Context, p *models.Portfolio) error {
+	return d.conn.QueryRowContext(ctx, "INSERT INTO portfolios (user_id, name, base_currency, cost_basis_method, strict_cash, wash_sales) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at",
+		p.UserID, p.Name, p.BaseCurrency, p.CostBasisMethod, p.StrictCash, p.WashSales).Scan(&p.ID, &p.CreatedAt)
+}
+
+// GetPortfolio implements PortfolioRepository
+func (d *DB) GetPortfolio(ctx context.Context, userID string, id int64) (*models.Portfolio, error) {
+	p := models.Portfolio{UserID: userID}
+	err := d.conn.QueryRowContext(ctx, "SELECT id, name, base_currency, cost_basis_method, strict_cash, wash_sales, created_at FROM portfolios WHERE user_id = $1 AND id = $2", userID, id).
+		Scan(&p.ID, &p.Name, &p.BaseCurrency, &p.CostBasisMethod, &p.StrictCash, &p.WashSales, &p.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// ListPortfolios implements PortfolioRepository
+func (d *DB) ListPortfolios(ctx context.Context, userID string) ([]models.Portfolio, error) {
+	rows, err := d.conn.QueryContext(ctx, "SELECT id, name, base_currency, cost_basis_method, strict_cash, wash_sales, created_at FROM portfolios WHERE user_id = $1 ORDER BY id", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var portfolios []models.Portfolio
+	for rows.Next() {
+		p := models.Portfolio{UserID: userID}
+		if err := rows.Scan(&p.ID, &p.Name, &p.BaseCurrency, &p.CostBasisMethod, &p.StrictCash, &p.WashSales, &p.CreatedAt); err != nil {
+			return nil, err
+		}
+		portfolios = append(portfolios, p)
+	}
+	return portfolios, rows.Err()
+}
+
+// ListAllPortfolios implements PortfolioRepository
+func (d *DB) ListAllPortfolios(ctx context.Context) ([]models.Portfolio, error) {
+	rows, err := d.conn.QueryContext(ctx, "SELECT id, user_id, name, base_currency, cost_basis_method, strict_cash, wash_sales, created_at FROM portfolios ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var portfolios []models.Portfolio
+	for rows.Next() {
+		var p models.Portfolio
+		if err := rows.Scan(&p.ID, &p.UserID, &p.Name, &p.BaseCurrency, &p.CostBasisMethod, &p.StrictCash, &p.WashSales, &p.CreatedAt); err != nil {
+			return nil, err
+		}
+		portfolios = append(portfolios, p)
+	}
+	return portfolios, rows.Err()
+}
+
+// UpdatePortfolio implements PortfolioRepository
+func (d *DB) UpdatePortfolio(ctx context.Context, p *models.Portfolio) error {
+	_, err := d.conn.ExecContext(ctx, "UPDATE portfolios SET name = $1, base_currency = $2, cost_basis_method = $3, strict_cash = $4, wash_sales = $5 WHERE user_id = $6 AND id = $7",
+		p.Name, p.BaseCurrency, p.CostBasisMethod, p.StrictCash, p.WashSales, p.UserID, p.ID)
+	return err
+}
+
+// DeletePortfolio implements PortfolioRepository; transactions and their audit cascade
+func (d *DB) DeletePortfolio(ctx context.Context, userID string, id int64) error {
+	_, err := d.conn.ExecContext(ctx, "DELETE FROM portfolios WHERE user_id = $1 AND id = $2", userID, id)
+	return err
+}
+
+// transactionColumns is the column list scanned by scanTransaction
+const transactionColumns = "id, portfolio_id, symbol, type, quantity, price, amount, currency, trade_date::text, note, fee, commission, tax, fee_schedule_id, version, created_at"
+
+// scanTransaction scans a row selected with transactionColumns
+func scanTransaction(row interface{ Scan(...interface{}) error }, t *models.Transaction) error {
+	return row.Scan(&t.ID, &t.PortfolioID, &t.Symbol, &t.Type, &t.Quantity, &t.Price, &t.Amount, &t.Currency, &t.TradeDate, &t.Note,
+		&t.Fee, &t.Commission, &t.Tax, &t.FeeScheduleID, &t.Version, &t.CreatedAt)
+}
+
+// AddTransaction implements TransactionRepository
+func (d *DB) AddTransaction(ctx context.Context, t *models.Transaction) error {
+	return addTransaction(ctx, d.conn, t)
+}
+
+// AddTransactions implements TransactionRepository
+func (d *DB) AddTransactions(ctx context.Context, txs []models.Transaction) error {
+	tx, err := d.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for i := range txs {
+		if err := addTransaction(ctx, tx, &txs[i]); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// rowQuerier is satisfied by both *sql.DB and *sql.Tx
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func addTransaction(ctx context.Context, q rowQuerier, t *models.Transaction) error {
+	return q.QueryRowContext(ctx,
+		"INSERT INTO transactions (user_id, portfolio_id, symbol, type, quantity, price, amount, currency, trade_date, note, fee, commission, tax, fee_schedule_id) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14) RETURNING id, version, created_at",
+		t.UserID, t.PortfolioID, t.Symbol, t.Type, t.Quantity, t.Price, t.Amount, t.Currency, t.TradeDate, t.Note, t.Fee, t.Commission, t.Tax, t.FeeScheduleID).Scan(&t.ID, &t.Version, &t.CreatedAt)
+}
+
+// GetTransaction implements TransactionRepository
+func (d *DB) GetTransaction(ctx context.Context, userID string, id int64) (*models.Transaction, error) {
+	t := models.Transaction{UserID: userID}
+	err := scanTransaction(d.conn.QueryRowContext(ctx, "SELECT "+transactionColumns+" FROM transactions WHERE user_id = $1 AND id = $2", userID, id), &t)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// ListTransactions implements TransactionRepository
+func (d *DB) ListTransactions(ctx context.Context, userID string, portfolioID int64, symbol string) ([]models.Transaction, error) {
+	rows, err := d.conn.QueryContext(ctx,
+		"SELECT "+transactionColumns+" FROM transactions WHERE user_id = $1 AND ($2::int = 0 OR portfolio_id = $2::int) AND ($3::text = '' OR symbol = $3::text) ORDER BY trade_date, id",
+		userID, portfolioID, symbol)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var txs []models.Transaction
+	for rows.Next() {
+		t := models.Transaction{UserID: userID}
+		if err := scanTransaction(rows, &t); err != nil {
+			return nil, err
+		}
+		txs = append(txs, t)
+	}
+	return txs, rows.Err()
+}
+
+// EachTransaction implements TransactionRepository
+func (d *DB) EachTransaction(ctx context.Context, userID string, portfolioID int64, fn func(models.Transaction) error) error {
+	rows, err := d.conn.QueryContext(ctx,
+		"SELECT "+transactionColumns+" FROM transactions WHERE user_id = $1 AND portfolio_id = $2 ORDER BY trade_date, id",
+		userID, portfolioID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		t := models.Transaction{UserID: userID}
+		if err := scanTransaction(rows, &t); err != nil {
+			return err
+		}
+		if err := fn(t); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// UpdateTransaction implements TransactionRepository
+func (d *DB) UpdateTransaction(ctx context.Context, t *models.Transaction, change *models.TransactionChange) (bool, error) {
+	return d.updateTransaction(ctx, t, change, "")
+}
+
+// UpdateHolding implements PortfolioRepository
+func (d *DB) UpdateHolding(ctx context.Context, t *models.Transaction, change *models.TransactionChange) (bool, error) {
+	return d.updateTransaction(ctx, t, change, models.TxBuy)
+}
+
+// updateTransaction stores t when its version still matches and, if typ is set, the
+// stored row is of that type
+func (d *DB) updateTransaction(ctx context.Context, t *models.Transaction, change *models.TransactionChange, typ string) (bool, error) {
+	changes, err := json.Marshal(change.Changes)
+	if err != nil {
+		return false, err
+	}
+	tx, err := d.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+	var version int64
+	err = tx.QueryRowContext(ctx,
+		`UPDATE transactions SET symbol = $1, type = $2, quantity = $3, price = $4, amount = $5, currency = $6, trade_date = $7, note = $8,
+			fee = $9, commission = $10, tax = $11, fee_schedule_id = $12, version = version + 1
+		 WHERE user_id = $13 AND id = $14 AND version = $15 AND ($16 = '' OR type = $16) RETURNING version`,
+		t.Symbol, t.Type, t.Quantity, t.Price, t.Amount, t.Currency, t.TradeDate, t.Note,
+		t.Fee, t.Commission, t.Tax, t.FeeScheduleID, t.UserID, t.ID, t.Version, typ).Scan(&version)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	t.Version = version
+	change.TransactionID, change.PortfolioID, change.Version = t.ID, t.PortfolioID, t.Version
+	err = tx.QueryRowContext(ctx,
+		"INSERT INTO transaction_changes (user_id, transaction_id, portfolio_id, version, changes) VALUES ($1, $2, $3, $4, $5) RETURNING id, changed_at",
+		t.UserID, t.ID, t.PortfolioID, t.Version, string(changes)).Scan(&change.ID, &change.ChangedAt)
+	if err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// ListTransactionChanges implements TransactionRepository
+func (d *DB) ListTransactionChanges(ctx context.Context, userID string, transactionID int64) ([]models.TransactionChange, error) {
+	rows, err := d.conn.QueryContext(ctx,
+		"SELECT id, transaction_id, portfolio_id, version, changes::text, changed_at FROM transaction_changes WHERE user_id = $1 AND transaction_id = $2 ORDER BY id",
+		userID, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []models.TransactionChange
+	for rows.Next() {
+		var c models.TransactionChange
+		var changes string
+		if err := rows.Scan(&c.ID, &c.TransactionID, &c.PortfolioID, &c.Version, &changes, &c.ChangedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(changes), &c.Changes); err != nil {
+			return nil, err
+		}
+		list = append(list, c)
+	}
+	return list, rows.Err()
+}
+
+// DeleteTransaction implements TransactionRepository
+func (d *DB) DeleteTransaction(ctx context.Context, userID string, id int64) error {
+	tx, err := d.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, "DELETE FROM transaction_changes WHERE user_id = $1 AND transaction_id = $2", userID, id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM transactions WHERE user_id = $1 AND id = $2", userID, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// DeleteTransactionsBySymbol implements TransactionRepository
+func (d *DB) DeleteTransactionsBySymbol(ctx context.Context, userID string, portfolioID int64, symbol string) error {
+	tx, err := d.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, `DELETE FROM transaction_changes WHERE transaction_id IN
+		(SELECT id FROM transactions WHERE user_id = $1 AND portfolio_id = $2 AND symbol = $3)`, userID, portfolioID, symbol); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM transactions WHERE user_id = $1 AND portfolio_id = $2 AND symbol = $3", userID, portfolioID, symbol); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SaveSnapshots implements SnapshotRepository
+func (d *DB) SaveSnapshots(ctx context.Context, snapshots []models.PortfolioSnapshot) error {
+	tx, err := d.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO portfolio_snapshots (portfolio_id, date, base_currency, total_value, total_cost, cash, pnl, net_flow)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (portfolio_id, date) DO UPDATE SET base_currency = EXCLUDED.base_currency, total_value = EXCLUDED.total_value,
+			total_cost = EXCLUDED.total_cost, cash = EXCLUDED.cash, pnl = EXCLUDED.pnl, net_flow = EXCLUDED.net_flow, created_at = NOW()`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, s := range snapshots {
+		if _, err := stmt.ExecContext(ctx, s.PortfolioID, s.Date, s.BaseCurrency, s.TotalValue, s.TotalCost, s.Cash, s.PnL, s.NetFlow); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// snapshotColumns is the column list scanned by scanSnapshot
+const snapshotColumns = "portfolio_id, date::text, base_currency, total_value, total_cost, cash, pnl, net_flow"
+
+// scanSnapshot scans a row selected with snapshotColumns
+func scanSnapshot(row interface{ Scan(...interface{}) error }, s *models.PortfolioSnapshot) error {
+	return row.Scan(&s.PortfolioID, &s.Date, &s.BaseCurrency, &s.TotalValue, &s.TotalCost, &s.Cash, &s.PnL, &s.NetFlow)
+}
+
+// ListSnapshots implements SnapshotRepository
+func (d *DB) ListSnapshots(ctx context.Context, portfolioID int64, from string) ([]models.PortfolioSnapshot, error) {
+	rows, err := d.conn.QueryContext(ctx, "SELECT "+snapshotColumns+" FROM portfolio_snapshots WHERE portfolio_id = $1 AND ($2::text = '' OR date >= $2::date) ORDER BY date", portfolioID, from)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	snapshots := []models.PortfolioSnapshot{}
+	for rows.Next() {
+		var s models.PortfolioSnapshot
+		if err := scanSnapshot(rows, &s); err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, s)
+	}
+	return snapshots, rows.Err()
+}
+
+// LatestSnapshot implements SnapshotRepository
+func (d *DB) LatestSnapshot(ctx context.Context, portfolioID int64) (*models.PortfolioSnapshot, error) {
+	var s models.PortfolioSnapshot
+	err := scanSnapshot(d.conn.QueryRowContext(ctx, "SELECT "+snapshotColumns+" FROM portfolio_snapshots WHERE portfolio_id = $1 ORDER BY date DESC LIMIT 1", portfolioID), &s)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// DeleteSnapshots implements SnapshotRepository
+func (d *DB) DeleteSnapshots(ctx context.Context, portfolioID int64, from string) error {
+	_, err := d.conn.ExecContext(ctx, "DELETE FROM portfolio_snapshots WHERE portfolio_id = $1 AND ($2::text = '' OR date >= $2::date)", portfolioID, from)
 	return err
 }
 
-// RemoveHolding implements PortfolioRepository
-func (d *DB) RemoveHolding(ctx context.Context, userID string, id int64) error {
-	_, err := d.conn.ExecContext(ctx, "DELETE FROM holdings WHERE user_id = $1 AND id = $2", userID, id)
+// ListTargets implements TargetRepository
+func (d *DB) ListTargets(ctx context.Context, portfolioID int64) ([]models.Target, error) {
+	rows, err := d.conn.QueryContext(ctx, "SELECT id, portfolio_id, kind, key, weight, threshold FROM portfolio_targets WHERE portfolio_id = $1 ORDER BY kind, key", portfolioID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	targets := []models.Target{}
+	for rows.Next() {
+		var t models.Target
+		if err := rows.Scan(&t.ID, &t.PortfolioID, &t.Kind, &t.Key, &t.Weight, &t.Threshold); err != nil {
+			return nil, err
+		}
+		targets = append(targets, t)
+	}
+	return targets, rows.Err()
+}
+
+// ReplaceTargets implements TargetRepository
+func (d *DB) ReplaceTargets(ctx context.Context, portfolioID int64, targets []models.Target) error {
+	tx, err := d.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, "DELETE FROM portfolio_targets WHERE portfolio_id = $1", portfolioID); err != nil {
+		return err
+	}
+	for _, t := range targets {
+		if _, err := tx.ExecContext(ctx, "INSERT INTO portfolio_targets (portfolio_id, kind, key, weight, threshold) VALUES ($1, $2, $3, $4, $5)",
+			portfolioID, t.Kind, t.Key, t.Weight, t.Threshold); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ListTrackedSymbols implements SymbolRepository
+func (d *DB) ListTrackedSymbols(ctx context.Context) ([]string, error) {
+	rows, err := d.conn.QueryContext(ctx, "SELECT symbol FROM watchlist UNION SELECT symbol FROM transactions WHERE symbol <> '' ORDER BY symbol")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var symbols []string
+	for rows.Next() {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			return nil, err
+		}
+		symbols = append(symbols, s)
+	}
+	return symbols, rows.Err()
+}
+
+// CreateScreen implements ScreenRepository
+func (d *DB) CreateScreen(ctx context.Context, screen *models.Screen) error {
+	return d.conn.QueryRowContext(ctx, "INSERT INTO screens (user_id, name, filter, universe) VALUES ($1, $2, $3, $4) RETURNING id, created_at",
+		screen.UserID, screen.Name, screen.Filter, screen.Universe).Scan(&screen.ID, &screen.CreatedAt)
+}
+
+// GetScreen implements ScreenRepository
+func (d *DB) GetScreen(ctx context.Context, userID string, id int64) (*models.Screen, error) {
+	s := models.Screen{UserID: userID}
+	err := d.conn.QueryRowContext(ctx, "SELECT id, name, filter, universe, created_at FROM screens WHERE user_id = $1 AND id = $2", userID, id).
+		Scan(&s.ID, &s.Name, &s.Filter, &s.Universe, &s.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// ListScreens implements ScreenRepository
+func (d *DB) ListScreens(ctx context.Context, userID string) ([]models.Screen, error) {
+	rows, err := d.conn.QueryContext(ctx, "SELECT id, name, filter, universe, created_at FROM screens WHERE user_id = $1 ORDER BY name", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var screens []models.Screen
+	for rows.Next() {
+		s := models.Screen{UserID: userID}
+		if err := rows.Scan(&s.ID, &s.Name, &s.Filter, &s.Universe, &s.CreatedAt); err != nil {
+			return nil, err
+		}
+		screens = append(screens, s)
+	}
+	return screens, rows.Err()
+}
+
+// DeleteScreen implements ScreenRepository
+func (d *DB) DeleteScreen(ctx context.Context, userID string, id int64) error {
+	_, err := d.conn.ExecContext(ctx, "DELETE FROM screens WHERE user_id = $1 AND id = $2", userID, id)
 	return err
 }
 
-// ListHoldings implements PortfolioRepository
-func (d *DB) ListHoldings(ctx context.Context, userID string) ([]models.Holding, error) {
-	rows, err := d.conn.QueryContext(ctx, "SELECT id, symbol, quantity, buy_price FROM holdings WHERE user_id = $1 ORDER BY symbol", userID)
+// feeScheduleColumns is the column list scanned by scanFeeSchedule
+const feeScheduleColumns = "id, name, kind, flat, percent, tiers, minimum, maximum, created_at"
+
+// scanFeeSchedule scans a row selected with feeScheduleColumns; tiers are stored as JSON
+func scanFeeSchedule(row interface{ Scan(...interface{}) error }, s *models.FeeSchedule) error {
+	var tiers string
+	if err := row.Scan(&s.ID, &s.Name, &s.Kind, &s.Flat, &s.Percent, &tiers, &s.Minimum, &s.Maximum, &s.CreatedAt); err != nil {
+		return err
+	}
+	s.Tiers = []models.FeeTier{}
+	return json.Unmarshal([]byte(tiers), &s.Tiers)
+}
+
+// CreateFeeSchedule implements FeeScheduleRepository
+func (d *DB) CreateFeeSchedule(ctx context.Context, s *models.FeeSchedule) error {
+	if s.Tiers == nil {
+		s.Tiers = []models.FeeTier{}
+	}
+	tiers, err := json.Marshal(s.Tiers)
+	if err != nil {
+		return err
+	}
+	return d.conn.QueryRowContext(ctx, "INSERT INTO fee_schedules (user_id, name, kind, flat, percent, tiers, minimum, maximum) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, created_at",
+		s.UserID, s.Name, s.Kind, s.Flat, s.Percent, string(tiers), s.Minimum, s.Maximum).Scan(&s.ID, &s.CreatedAt)
+}
+
+// GetFeeSchedule implements FeeScheduleRepository
+func (d *DB) GetFeeSchedule(ctx context.Context, userID string, id int64) (*models.FeeSchedule, error) {
+	s := models.FeeSchedule{UserID: userID}
+	err := scanFeeSchedule(d.conn.QueryRowContext(ctx, "SELECT "+feeScheduleColumns+" FROM fee_schedules WHERE user_id = $1 AND id = $2", userID, id), &s)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// ListFeeSchedules implements FeeScheduleRepository
+func (d *DB) ListFeeSchedules(ctx context.Context, userID string) ([]models.FeeSchedule, error) {
+	rows, err := d.conn.QueryContext(ctx, "SELECT "+feeScheduleColumns+" FROM fee_schedules WHERE user_id = $1 ORDER BY name", userID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var holdings []models.Holding
+	var schedules []models.FeeSchedule
 	for rows.Next() {
-		var h models.Holding
-		h.UserID = userID
-		if err := rows.Scan(&h.ID, &h.Symbol, &h.Quantity, &h.BuyPrice); err != nil {
+		s := models.FeeSchedule{UserID: userID}
+		if err := scanFeeSchedule(rows, &s); err != nil {
 			return nil, err
 		}
-		holdings = append(holdings, h)
+		schedules = append(schedules, s)
 	}
-	return holdings, rows.Err()
+	return schedules, rows.Err()
+}
+
+// DeleteFeeSchedule implements FeeScheduleRepository
+func (d *DB) DeleteFeeSchedule(ctx context.Context, userID string, id int64) error {
+	_, err := d.conn.ExecContext(ctx, "DELETE FROM fee_schedules WHERE user_id = $1 AND id = $2", userID, id)
+	return err
 }
@@ -22,9 +22,81 @@ type WatchlistRepository interface {
 
 // PortfolioRepository defines portfolio data access
 type PortfolioRepository interface {
-	AddHolding(ctx context.Context, userID string, symbol string, quantity, buyPrice float64) error
-	RemoveHolding(ctx context.Context, userID string, id int64) error
-	ListHoldings(ctx context.Context, userID string) ([]models.Holding, error)
+	CreatePortfolio(ctx context.Context, p *models.Portfolio) error
+	GetPortfolio(ctx context.Context, userID string, id int64) (*models.Portfolio, error)
+	// ListPortfolios returns a user's portfolios in creation order
+	ListPortfolios(ctx context.Context, userID string) ([]models.Portfolio, error)
+	// ListAllPortfolios returns every user's portfolios, for background jobs
+	ListAllPortfolios(ctx context.Context) ([]models.Portfolio, error)
+	UpdatePortfolio(ctx context.Context, p *models.Portfolio) error
+	// DeletePortfolio removes a portfolio with its transactions, snapshots and targets
+	DeletePortfolio(ctx context.Context, userID string, id int64) error
+	// UpdateHolding is UpdateTransaction for a holding: it also reports false, storing
+	// nothing, unless the stored row is a buy
+	UpdateHolding(ctx context.Context, t *models.Transaction, change *models.TransactionChange) (bool, error)
+}
+
+// TransactionRepository defines ledger data access; holdings are derived from transactions
+type TransactionRepository interface {
+	AddTransaction(ctx context.Context, tx *models.Transaction) error
+	// AddTransactions stores txs in one transaction, setting their IDs; on error none are stored
+	AddTransactions(ctx context.Context, txs []models.Transaction) error
+	GetTransaction(ctx context.Context, userID string, id int64) (*models.Transaction, error)
+	// ListTransactions returns a user's transactions in trade date order. A zero
+	// portfolioID lists every portfolio and an empty symbol lists every symbol.
+	ListTransactions(ctx context.Context, userID string, portfolioID int64, symbol string) ([]models.Transaction, error)
+	// EachTransaction calls fn for each of a portfolio's transactions in trade date order
+	// as they are read; an error from fn stops the iteration and is returned
+	EachTransaction(ctx context.Context, userID string, portfolioID int64, fn func(models.Transaction) error) error
+	// UpdateTransaction stores t if its stored version is still t.Version, bumping the
+	// version and recording change in the same transaction. It reports false, storing
+	// nothing, when the row was edited or removed since it was read.
+	UpdateTransaction(ctx context.Context, t *models.Transaction, change *models.TransactionChange) (bool, error)
+	// ListTransactionChanges returns a transaction's audited edits, oldest first
+	ListTransactionChanges(ctx context.Context, userID string, transactionID int64) ([]models.TransactionChange, error)
+	// DeleteTransaction and DeleteTransactionsBySymbol also delete the transactions' audited edits
+	DeleteTransaction(ctx context.Context, userID string, id int64) error
+	DeleteTransactionsBySymbol(ctx context.Context, userID string, portfolioID int64, symbol string) error
+}
+
+// SnapshotRepository stores daily portfolio valuations
+type SnapshotRepository interface {
+	// SaveSnapshots inserts snapshots, replacing any for the same portfolio and date
+	SaveSnapshots(ctx context.Context, snapshots []models.PortfolioSnapshot) error
+	// ListSnapshots returns a portfolio's snapshots on or after from (empty for all) in date order
+	ListSnapshots(ctx context.Context, portfolioID int64, from string) ([]models.PortfolioSnapshot, error)
+	// LatestSnapshot returns a portfolio's most recent snapshot, or nil if it has none
+	LatestSnapshot(ctx context.Context, portfolioID int64) (*models.PortfolioSnapshot, error)
+	// DeleteSnapshots removes a portfolio's snapshots on or after from (empty for all)
+	DeleteSnapshots(ctx context.Context, portfolioID int64, from string) error
+}
+
+// TargetRepository stores portfolio target allocations
+type TargetRepository interface {
+	ListTargets(ctx context.Context, portfolioID int64) ([]models.Target, error)
+	// ReplaceTargets swaps a portfolio's targets for targets in one transaction
+	ReplaceTargets(ctx context.Context, portfolioID int64, targets []models.Target) error
+}
+
+// FeeScheduleRepository stores a user's broker fee schedules
+type FeeScheduleRepository interface {
+	CreateFeeSchedule(ctx context.Context, schedule *models.FeeSchedule) error
+	GetFeeSchedule(ctx context.Context, userID string, id int64) (*models.FeeSchedule, error)
+	ListFeeSchedules(ctx context.Context, userID string) ([]models.FeeSchedule, error)
+	DeleteFeeSchedule(ctx context.Context, userID string, id int64) error
+}
+
+// SymbolRepository exposes symbols tracked across all users
+type SymbolRepository interface {
+	ListTrackedSymbols(ctx context.Context) ([]string, error)
+}
+
+// ScreenRepository defines saved screener data access
+type ScreenRepository interface {
+	CreateScreen(ctx context.Context, screen *models.Screen) error
+	GetScreen(ctx context.Context, userID string, id int64) (*models.Screen, error)
+	ListScreens(ctx context.Context, userID string) ([]models.Screen, error)
+	DeleteScreen(ctx context.Context, userID string, id int64) error
 }
 
 // DB wraps all repositories
@@ -32,5 +104,11 @@ type DB interface {
 	UserRepository
 	WatchlistRepository
 	PortfolioRepository
+	TransactionRepository
+	SnapshotRepository
+	TargetRepository
+	FeeScheduleRepository
+	SymbolRepository
+	ScreenRepository
 	Close() error
 }
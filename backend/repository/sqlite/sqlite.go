@@ -3,6 +3,7 @@ package sqlite
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"strings"
 
@@ -45,6 +46,45 @@ func (d *DB) Close() error {
 	return d.conn.Close()
 }
 
+// Tables that migrateDecimalColumns may rebuild; the rest are created inline in migrate
+const (
+	createTransactions = `CREATE TABLE IF NOT EXISTS transactions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id TEXT NOT NULL,
+		portfolio_id INTEGER REFERENCES portfolios(id) ON DELETE CASCADE,
+		symbol TEXT NOT NULL,
+		type TEXT NOT NULL,
+		quantity TEXT NOT NULL DEFAULT '0',
+		price TEXT NOT NULL DEFAULT '0',
+		amount TEXT NOT NULL DEFAULT '0',
+		currency TEXT NOT NULL DEFAULT '',
+		trade_date TEXT NOT NULL,
+		note TEXT NOT NULL DEFAULT '',
+		fee TEXT NOT NULL DEFAULT '0',
+		commission TEXT NOT NULL DEFAULT '0',
+		tax TEXT NOT NULL DEFAULT '0',
+		fee_schedule_id INTEGER NOT NULL DEFAULT 0,
+		version INTEGER NOT NULL DEFAULT 1,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+	)`
+	createTransactionsIndex = `CREATE INDEX IF NOT EXISTS idx_transactions_user_date ON transactions(user_id, trade_date)`
+	createFeeSchedules      = `CREATE TABLE IF NOT EXISTS fee_schedules (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id TEXT NOT NULL,
+		name TEXT NOT NULL,
+		kind TEXT NOT NULL,
+		flat TEXT NOT NULL DEFAULT '0',
+		percent TEXT NOT NULL DEFAULT '0',
+		tiers TEXT NOT NULL DEFAULT '[]',
+		minimum TEXT NOT NULL DEFAULT '0',
+		maximum TEXT NOT NULL DEFAULT '0',
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+		UNIQUE(user_id, name)
+	)`
+)
+
 func (d *DB) migrate() error {
 	queries := []string{
 		`CREATE TABLE IF NOT EXISTS users (
@@ -60,15 +100,65 @@ func (d *DB) migrate() error {
 			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
 			UNIQUE(user_id, symbol)
 		)`,
-		`CREATE TABLE IF NOT EXISTS holdings (
+		`CREATE TABLE IF NOT EXISTS portfolios (
 			id INTEGER PRIMARY KEY AUTOINCREMENT,
 			user_id TEXT NOT NULL,
-			symbol TEXT NOT NULL,
-			quantity REAL NOT NULL,
-			buy_price REAL NOT NULL,
+			name TEXT NOT NULL,
+			base_currency TEXT NOT NULL DEFAULT '',
+			cost_basis_method TEXT NOT NULL DEFAULT '',
+			strict_cash INTEGER NOT NULL DEFAULT 0,
+			wash_sales INTEGER NOT NULL DEFAULT 0,
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+			UNIQUE(user_id, name)
+		)`,
+		createTransactions,
+		createTransactionsIndex,
+		`CREATE TABLE IF NOT EXISTS transaction_changes (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id TEXT NOT NULL,
+			transaction_id INTEGER NOT NULL,
+			portfolio_id INTEGER NOT NULL,
+			version INTEGER NOT NULL,
+			changes TEXT NOT NULL,
+			changed_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
 		)`,
+		`CREATE INDEX IF NOT EXISTS idx_transaction_changes_tx ON transaction_changes(transaction_id)`,
+		`CREATE TABLE IF NOT EXISTS portfolio_snapshots (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			portfolio_id INTEGER NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+			date TEXT NOT NULL,
+			base_currency TEXT NOT NULL,
+			total_value REAL NOT NULL DEFAULT 0,
+			total_cost REAL NOT NULL DEFAULT 0,
+			cash REAL NOT NULL DEFAULT 0,
+			pnl REAL NOT NULL DEFAULT 0,
+			net_flow REAL NOT NULL DEFAULT 0,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(portfolio_id, date)
+		)`,
+		`CREATE TABLE IF NOT EXISTS portfolio_targets (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			portfolio_id INTEGER NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+			kind TEXT NOT NULL,
+			key TEXT NOT NULL,
+			weight REAL NOT NULL,
+			threshold REAL NOT NULL DEFAULT 0,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(portfolio_id, kind, key)
+		)`,
+		`CREATE TABLE IF NOT EXISTS screens (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			filter TEXT NOT NULL,
+			universe TEXT NOT NULL DEFAULT 'watched',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+			UNIQUE(user_id, name)
+		)`,
+		createFeeSchedules,
 	}
 	for _, q := range queries {
 		if _, err := d.conn.Exec(q); err != nil {
@@ -78,7 +168,164 @@ func (d *DB) migrate() error {
 	if err := d.migrateLegacyUserScopedTables(); err != nil {
 		return err
 	}
-	return nil
+	if err := d.migrateHoldingsToTransactions(); err != nil {
+		return err
+	}
+	if err := d.addColumnIfMissing("transactions", "portfolio_id", "INTEGER REFERENCES portfolios(id) ON DELETE CASCADE"); err != nil {
+		return err
+	}
+	if err := d.addColumnIfMissing("portfolios", "strict_cash", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	if err := d.addColumnIfMissing("portfolios", "wash_sales", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	for _, column := range []string{"fee", "commission", "tax"} {
+		if err := d.addColumnIfMissing("transactions", column, "TEXT NOT NULL DEFAULT '0'"); err != nil {
+			return err
+		}
+	}
+	if err := d.addColumnIfMissing("transactions", "fee_schedule_id", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	if err := d.addColumnIfMissing("transactions", "version", "INTEGER NOT NULL DEFAULT 1"); err != nil {
+		return err
+	}
+	if err := d.migrateDecimalColumns("transactions", []string{"quantity", "price", "amount", "fee", "commission", "tax"}, createTransactions, createTransactionsIndex); err != nil {
+		return err
+	}
+	if err := d.migrateDecimalColumns("fee_schedules", []string{"flat", "percent", "minimum", "maximum"}, createFeeSchedules); err != nil {
+		return err
+	}
+	return d.assignDefaultPortfolios()
+}
+
+// tableExists reports whether a table is present in the schema
+func (d *DB) tableExists(table string) (bool, error) {
+	var count int
+	err := d.conn.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", table).Scan(&count)
+	return count > 0, err
+}
+
+// migrateHoldingsToTransactions replaces the pre-ledger holdings table with one buy
+// transaction per row, dated from when the row was created.
+func (d *DB) migrateHoldingsToTransactions() error {
+	hasHoldings, err := d.tableExists("holdings")
+	if err != nil || !hasHoldings {
+		return err
+	}
+	if err := d.addColumnIfMissing("holdings", "currency", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	tx, err := d.conn.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(
+		`INSERT INTO transactions (user_id, symbol, type, quantity, price, currency, trade_date, created_at)
+		 SELECT user_id, symbol, 'buy', quantity, buy_price, currency,
+		        COALESCE(DATE(created_at), DATE('now')), COALESCE(created_at, CURRENT_TIMESTAMP)
+		 FROM holdings
+		 ORDER BY id`,
+	); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DROP TABLE holdings"); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// assignDefaultPortfolios moves transactions recorded before portfolios existed into a
+// "Default" portfolio per user
+func (d *DB) assignDefaultPortfolios() error {
+	tx, err := d.conn.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(
+		`INSERT OR IGNORE INTO portfolios (user_id, name)
+		 SELECT DISTINCT user_id, 'Default' FROM transactions WHERE portfolio_id IS NULL`,
+	); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(
+		`UPDATE transactions SET portfolio_id = (
+			SELECT p.id FROM portfolios p WHERE p.user_id = transactions.user_id AND p.name = 'Default'
+		 ) WHERE portfolio_id IS NULL`,
+	); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// migrateDecimalColumns rebuilds a table whose money and quantity columns an older
+// schema declared REAL, so they hold exact decimal text. SQLite cannot change a
+// column's type in place: the table is renamed, recreated from its CREATE
+// statement and copied back, and indexes are recreated once the old table is gone.
+func (d *DB) migrateDecimalColumns(table string, columns []string, create string, indexes ...string) error {
+	colType, err := d.columnType(table, columns[0])
+	if err != nil || !strings.EqualFold(colType, "REAL") {
+		return err
+	}
+	tx, err := d.conn.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	legacy := table + "_real"
+	if _, err := tx.Exec("DROP TABLE IF EXISTS " + legacy); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(fmt.Sprintf("ALTER TABLE %s RENAME TO %s", table, legacy)); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(create); err != nil {
+		return err
+	}
+	names, err := tableColumns(tx, table)
+	if err != nil {
+		return err
+	}
+	selects := make([]string, len(names))
+	for i, name := range names {
+		selects[i] = name
+		for _, c := range columns {
+			if name == c {
+				// CAST prints the double to 15 significant digits, which recovers
+				// the decimal it was entered as
+				selects[i] = fmt.Sprintf("CAST(%s AS TEXT)", name)
+			}
+		}
+	}
+	if _, err := tx.Exec(fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s",
+		table, strings.Join(names, ", "), strings.Join(selects, ", "), legacy)); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DROP TABLE " + legacy); err != nil {
+		return err
+	}
+	for _, q := range indexes {
+		if _, err := tx.Exec(q); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// addColumnIfMissing adds a column to an existing table created by an older schema version.
+func (d *DB) addColumnIfMissing(table, column, definition string) error {
+	has, err := d.tableHasColumn(table, column)
+	if err != nil || has {
+		return err
+	}
+	_, err = d.conn.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	return err
 }
 
 // migrateLegacyUserScopedTables upgrades older single-user schemas that did not include user_id.
@@ -87,9 +334,14 @@ func (d *DB) migrateLegacyUserScopedTables() error {
 	if err != nil {
 		return err
 	}
-	holdingsHasUserID, err := d.tableHasColumn("holdings", "user_id")
-	if err != nil {
+	// Databases already on the transaction ledger no longer have a holdings table
+	holdingsHasUserID := true
+	if hasHoldings, err := d.tableExists("holdings"); err != nil {
 		return err
+	} else if hasHoldings {
+		if holdingsHasUserID, err = d.tableHasColumn("holdings", "user_id"); err != nil {
+			return err
+		}
 	}
 	if watchlistHasUserID && holdingsHasUserID {
 		return nil
@@ -114,9 +366,15 @@ func (d *DB) migrateLegacyUserScopedTables() error {
 }
 
 func (d *DB) tableHasColumn(table, column string) (bool, error) {
+	colType, err := d.columnType(table, column)
+	return colType != "", err
+}
+
+// columnType returns a column's declared type, or "" when the table lacks it
+func (d *DB) columnType(table, column string) (string, error) {
 	rows, err := d.conn.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
 	if err != nil {
-		return false, err
+		return "", err
 	}
 	defer rows.Close()
 
@@ -130,13 +388,32 @@ func (d *DB) tableHasColumn(table, column string) (bool, error) {
 			primaryK int
 		)
 		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultV, &primaryK); err != nil {
-			return false, err
+			return "", err
 		}
 		if strings.EqualFold(name, column) {
-			return true, nil
+			return colType, nil
+		}
+	}
+	return "", rows.Err()
+}
+
+// tableColumns lists a table's column names in order
+func tableColumns(tx *sql.Tx, table string) ([]string, error) {
+	rows, err := tx.Query(fmt.Sprintf("SELECT name FROM pragma_table_info('%s')", table))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
 		}
+		names = append(names, name)
 	}
-	return false, rows.Err()
+	return names, rows.Err()
 }
 
 func (d *DB) ensureDefaultUserID() (string, error) {
@@ -223,6 +500,7 @@ func (d *DB) migrateLegacyHoldings(defaultUserID string) error {
 		symbol TEXT NOT NULL,
 		quantity REAL NOT NULL,
 		buy_price REAL NOT NULL,
+		currency TEXT NOT NULL DEFAULT '',
 		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
 	)`); err != nil {
@@ -261,14 +539,23 @@ func (d *DB) seedIfEmpty() error {
 	for _, s := range []string{"AAPL", "MSFT", "GOOGL", "AMZN", "NVDA"} {
 		_, _ = d.conn.Exec("INSERT OR IGNORE INTO watchlist (user_id, symbol) VALUES (?, ?)", demoID, s)
 	}
+	res, err := d.conn.Exec("INSERT INTO portfolios (user_id, name) VALUES (?, 'Default')", demoID)
+	if err != nil {
+		return err
+	}
+	portfolioID, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
 	for _, h := range []struct {
 		sym        string
-		qty, price float64
+		qty, price string
+		date       string
 	}{
-		{"AAPL", 10, 175.50}, {"MSFT", 5, 380.00}, {"GOOGL", 3, 140.00},
+		{"AAPL", "10", "175.50", "2024-01-16"}, {"MSFT", "5", "380.00", "2024-01-16"}, {"GOOGL", "3", "140.00", "2024-02-01"},
 	} {
-		_, _ = d.conn.Exec("INSERT INTO holdings (user_id, symbol, quantity, buy_price) VALUES (?, ?, ?, ?)",
-			demoID, h.sym, h.qty, h.price)
+		_, _ = d.conn.Exec("INSERT INTO transactions (user_id, portfolio_id, symbol, type, quantity, price, currency, trade_date) VALUES (?, ?, ?, 'buy', ?, ?, 'USD', ?)",
+			demoID, portfolioID, h.sym, h.qty, h.price, h.date)
 	}
 	return nil
 }
@@ -344,34 +631,556 @@ func (d *DB) List(ctx context.Context, userID string) ([]models.WatchlistItem, e
 	return items, rows.Err()
 }
 
-// Add implements PortfolioRepository
-func (d *DB) AddHolding(ctx context.Context, userID string, symbol string, quantity, buyPrice float64) error {
-	_, err := d.conn.ExecContext(ctx, "INSERT INTO holdings (user_id, symbol, quantity, buy_price) VALUES (?, ?, ?, ?)",
-		userID, symbol, quantity, buyPrice)
+// CreatePortfolio implements PortfolioRepository
+func (d *DB) CreatePortfolio(ctx context.Context, p *models.Portfolio) error {
+	res, err := d.conn.ExecContext(ctx, "INSERT INTO portfolios (user_id, name, base_currency, cost_basis_method, strict_cash, wash_sales) VALUES (?, ?, ?, ?, ?, ?)",
+		p.UserID, p.Name, p.BaseCurrency, p.CostBasisMethod, p.StrictCash, p.WashSales)
+	if err != nil {
+		return err
+	}
+	if p.ID, err = res.LastInsertId(); err != nil {
+		return err
+	}
+	return d.conn.QueryRowContext(ctx, "SELECT created_at FROM portfolios WHERE id = ?", p.ID).Scan(&p.CreatedAt)
+}
+
+// GetPortfolio implements PortfolioRepository
+func (d *DB) GetPortfolio(ctx context.Context, userID string, id int64) (*models.Portfolio, error) {
+	p := models.Portfolio{UserID: userID}
+	err := d.conn.QueryRowContext(ctx, "SELECT id, name, base_currency, cost_basis_method, strict_cash, wash_sales, created_at FROM portfolios WHERE user_id = ? AND id = ?", userID, id).
+		Scan(&p.ID, &p.Name, &p.BaseCurrency, &p.CostBasisMethod, &p.StrictCash, &p.WashSales, &p.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// ListPortfolios implements PortfolioRepository
+func (d *DB) ListPortfolios(ctx context.Context, userID string) ([]models.Portfolio, error) {
+	rows, err := d.conn.QueryContext(ctx, "SELECT id, name, base_currency, cost_basis_method, strict_cash, wash_sales, created_at FROM portfolios WHERE user_id = ? ORDER BY id", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var portfolios []models.Portfolio
+	for rows.Next() {
+		p := models.Portfolio{UserID: userID}
+		if err := rows.Scan(&p.ID, &p.Name, &p.BaseCurrency, &p.CostBasisMethod, &p.StrictCash, &p.WashSales, &p.CreatedAt); err != nil {
+			return nil, err
+		}
+		portfolios = append(portfolios, p)
+	}
+	return portfolios, rows.Err()
+}
+
+// ListAllPortfolios implements PortfolioRepository
+func (d *DB) ListAllPortfolios(ctx context.Context) ([]models.Portfolio, error) {
+	rows, err := d.conn.QueryContext(ctx, "SELECT id, user_id, name, base_currency, cost_basis_method, strict_cash, wash_sales, created_at FROM portfolios ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var portfolios []models.Portfolio
+	for rows.Next() {
+		var p models.Portfolio
+		if err := rows.Scan(&p.ID, &p.UserID, &p.Name, &p.BaseCurrency, &p.CostBasisMethod, &p.StrictCash, &p.WashSales, &p.CreatedAt); err != nil {
+			return nil, err
+		}
+		portfolios = append(portfolios, p)
+	}
+	return portfolios, rows.Err()
+}
+
+// UpdatePortfolio implements PortfolioRepository
+func (d *DB) UpdatePortfolio(ctx context.Context, p *models.Portfolio) error {
+	_, err := d.conn.ExecContext(ctx, "UPDATE portfolios SET name = ?, base_currency = ?, cost_basis_method = ?, strict_cash = ?, wash_sales = ? WHERE user_id = ? AND id = ?",
+		p.Name, p.BaseCurrency, p.CostBasisMethod, p.StrictCash, p.WashSales, p.UserID, p.ID)
 	return err
 }
 
-// Remove implements PortfolioRepository
-func (d *DB) RemoveHolding(ctx context.Context, userID string, id int64) error {
-	_, err := d.conn.ExecContext(ctx, "DELETE FROM holdings WHERE user_id = ? AND id = ?", userID, id)
+// DeletePortfolio implements PortfolioRepository. Foreign keys are not enforced by
+// default in SQLite, so the portfolio's transactions, their audit, snapshots and targets
+// are removed explicitly.
+func (d *DB) DeletePortfolio(ctx context.Context, userID string, id int64) error {
+	tx, err := d.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	res, err := tx.ExecContext(ctx, "DELETE FROM portfolios WHERE user_id = ? AND id = ?", userID, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM transactions WHERE user_id = ? AND portfolio_id = ?", userID, id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM portfolio_snapshots WHERE portfolio_id = ?", id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM portfolio_targets WHERE portfolio_id = ?", id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM transaction_changes WHERE portfolio_id = ?", id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// transactionColumns is the column list scanned by scanTransaction
+const transactionColumns = "id, portfolio_id, symbol, type, quantity, price, amount, currency, trade_date, note, fee, commission, tax, fee_schedule_id, version, created_at"
+
+// scanTransaction scans a row selected with transactionColumns
+func scanTransaction(row interface{ Scan(...interface{}) error }, t *models.Transaction) error {
+	return row.Scan(&t.ID, &t.PortfolioID, &t.Symbol, &t.Type, &t.Quantity, &t.Price, &t.Amount, &t.Currency, &t.TradeDate, &t.Note,
+		&t.Fee, &t.Commission, &t.Tax, &t.FeeScheduleID, &t.Version, &t.CreatedAt)
+}
+
+// execer is satisfied by both *sql.DB and *sql.Tx
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// AddTransaction implements TransactionRepository
+func (d *DB) AddTransaction(ctx context.Context, t *models.Transaction) error {
+	return addTransaction(ctx, d.conn, t)
+}
+
+// AddTransactions implements TransactionRepository
+func (d *DB) AddTransactions(ctx context.Context, txs []models.Transaction) error {
+	tx, err := d.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for i := range txs {
+		if err := addTransaction(ctx, tx, &txs[i]); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func addTransaction(ctx context.Context, q execer, t *models.Transaction) error {
+	res, err := q.ExecContext(ctx,
+		"INSERT INTO transactions (user_id, portfolio_id, symbol, type, quantity, price, amount, currency, trade_date, note, fee, commission, tax, fee_schedule_id) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		t.UserID, t.PortfolioID, t.Symbol, t.Type, t.Quantity, t.Price, t.Amount, t.Currency, t.TradeDate, t.Note, t.Fee, t.Commission, t.Tax, t.FeeScheduleID)
+	if err != nil {
+		return err
+	}
+	if t.ID, err = res.LastInsertId(); err != nil {
+		return err
+	}
+	return q.QueryRowContext(ctx, "SELECT version, created_at FROM transactions WHERE id = ?", t.ID).Scan(&t.Version, &t.CreatedAt)
+}
+
+// GetTransaction implements TransactionRepository
+func (d *DB) GetTransaction(ctx context.Context, userID string, id int64) (*models.Transaction, error) {
+	t := models.Transaction{UserID: userID}
+	err := scanTransaction(d.conn.QueryRowContext(ctx, "SELECT "+transactionColumns+" FROM transactions WHERE user_id = ? AND id = ?", userID, id), &t)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// ListTransactions implements TransactionRepository
+func (d *DB) ListTransactions(ctx context.Context, userID string, portfolioID int64, symbol string) ([]models.Transaction, error) {
+	rows, err := d.conn.QueryContext(ctx,
+		"SELECT "+transactionColumns+" FROM transactions WHERE user_id = ? AND (? = 0 OR portfolio_id = ?) AND (? = '' OR symbol = ?) ORDER BY trade_date, id",
+		userID, portfolioID, portfolioID, symbol, symbol)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var txs []models.Transaction
+	for rows.Next() {
+		t := models.Transaction{UserID: userID}
+		if err := scanTransaction(rows, &t); err != nil {
+			return nil, err
+		}
+		txs = append(txs, t)
+	}
+	return txs, rows.Err()
+}
+
+// EachTransaction implements TransactionRepository
+func (d *DB) EachTransaction(ctx context.Context, userID string, portfolioID int64, fn func(models.Transaction) error) error {
+	rows, err := d.conn.QueryContext(ctx,
+		"SELECT "+transactionColumns+" FROM transactions WHERE user_id = ? AND portfolio_id = ? ORDER BY trade_date, id",
+		userID, portfolioID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		t := models.Transaction{UserID: userID}
+		if err := scanTransaction(rows, &t); err != nil {
+			return err
+		}
+		if err := fn(t); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// UpdateTransaction implements TransactionRepository
+func (d *DB) UpdateTransaction(ctx context.Context, t *models.Transaction, change *models.TransactionChange) (bool, error) {
+	return d.updateTransaction(ctx, t, change, "")
+}
+
+// UpdateHolding implements PortfolioRepository
+func (d *DB) UpdateHolding(ctx context.Context, t *models.Transaction, change *models.TransactionChange) (bool, error) {
+	return d.updateTransaction(ctx, t, change, models.TxBuy)
+}
+
+// updateTransaction stores t when its version still matches and, if typ is set, the
+// stored row is of that type
+func (d *DB) updateTransaction(ctx context.Context, t *models.Transaction, change *models.TransactionChange, typ string) (bool, error) {
+	changes, err := json.Marshal(change.Changes)
+	if err != nil {
+		return false, err
+	}
+	tx, err := d.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+	res, err := tx.ExecContext(ctx,
+		`UPDATE transactions SET symbol = ?, type = ?, quantity = ?, price = ?, amount = ?, currency = ?, trade_date = ?, note = ?,
+			fee = ?, commission = ?, tax = ?, fee_schedule_id = ?, version = version + 1
+		 WHERE user_id = ? AND id = ? AND version = ? AND (? = '' OR type = ?)`,
+		t.Symbol, t.Type, t.Quantity, t.Price, t.Amount, t.Currency, t.TradeDate, t.Note,
+		t.Fee, t.Commission, t.Tax, t.FeeScheduleID, t.UserID, t.ID, t.Version, typ, typ)
+	if err != nil {
+		return false, err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return false, err
+	}
+	t.Version++
+	change.TransactionID, change.PortfolioID, change.Version = t.ID, t.PortfolioID, t.Version
+	res, err = tx.ExecContext(ctx, "INSERT INTO transaction_changes (user_id, transaction_id, portfolio_id, version, changes) VALUES (?, ?, ?, ?, ?)",
+		t.UserID, t.ID, t.PortfolioID, t.Version, string(changes))
+	if err != nil {
+		return false, err
+	}
+	if change.ID, err = res.LastInsertId(); err != nil {
+		return false, err
+	}
+	if err := tx.QueryRowContext(ctx, "SELECT changed_at FROM transaction_changes WHERE id = ?", change.ID).Scan(&change.ChangedAt); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// ListTransactionChanges implements TransactionRepository
+func (d *DB) ListTransactionChanges(ctx context.Context, userID string, transactionID int64) ([]models.TransactionChange, error) {
+	rows, err := d.conn.QueryContext(ctx,
+		"SELECT id, transaction_id, portfolio_id, version, changes, changed_at FROM transaction_changes WHERE user_id = ? AND transaction_id = ? ORDER BY id",
+		userID, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []models.TransactionChange
+	for rows.Next() {
+		var c models.TransactionChange
+		var changes string
+		if err := rows.Scan(&c.ID, &c.TransactionID, &c.PortfolioID, &c.Version, &changes, &c.ChangedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(changes), &c.Changes); err != nil {
+			return nil, err
+		}
+		list = append(list, c)
+	}
+	return list, rows.Err()
+}
+
+// DeleteTransaction implements TransactionRepository
+func (d *DB) DeleteTransaction(ctx context.Context, userID string, id int64) error {
+	tx, err := d.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, "DELETE FROM transaction_changes WHERE user_id = ? AND transaction_id = ?", userID, id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM transactions WHERE user_id = ? AND id = ?", userID, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// DeleteTransactionsBySymbol implements TransactionRepository
+func (d *DB) DeleteTransactionsBySymbol(ctx context.Context, userID string, portfolioID int64, symbol string) error {
+	tx, err := d.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, `DELETE FROM transaction_changes WHERE transaction_id IN
+		(SELECT id FROM transactions WHERE user_id = ? AND portfolio_id = ? AND symbol = ?)`, userID, portfolioID, symbol); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM transactions WHERE user_id = ? AND portfolio_id = ? AND symbol = ?", userID, portfolioID, symbol); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SaveSnapshots implements SnapshotRepository
+func (d *DB) SaveSnapshots(ctx context.Context, snapshots []models.PortfolioSnapshot) error {
+	tx, err := d.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO portfolio_snapshots (portfolio_id, date, base_currency, total_value, total_cost, cash, pnl, net_flow)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(portfolio_id, date) DO UPDATE SET base_currency = excluded.base_currency, total_value = excluded.total_value,
+			total_cost = excluded.total_cost, cash = excluded.cash, pnl = excluded.pnl, net_flow = excluded.net_flow, created_at = CURRENT_TIMESTAMP`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, s := range snapshots {
+		if _, err := stmt.ExecContext(ctx, s.PortfolioID, s.Date, s.BaseCurrency, s.TotalValue, s.TotalCost, s.Cash, s.PnL, s.NetFlow); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// snapshotColumns is the column list scanned by scanSnapshot
+const snapshotColumns = "portfolio_id, date, base_currency, total_value, total_cost, cash, pnl, net_flow"
+
+// scanSnapshot scans a row selected with snapshotColumns
+func scanSnapshot(row interface{ Scan(...interface{}) error }, s *models.PortfolioSnapshot) error {
+	return row.Scan(&s.PortfolioID, &s.Date, &s.BaseCurrency, &s.TotalValue, &s.TotalCost, &s.Cash, &s.PnL, &s.NetFlow)
+}
+
+// ListSnapshots implements SnapshotRepository
+func (d *DB) ListSnapshots(ctx context.Context, portfolioID int64, from string) ([]models.PortfolioSnapshot, error) {
+	rows, err := d.conn.QueryContext(ctx, "SELECT "+snapshotColumns+" FROM portfolio_snapshots WHERE portfolio_id = ? AND date >= ? ORDER BY date", portfolioID, from)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	snapshots := []models.PortfolioSnapshot{}
+	for rows.Next() {
+		var s models.PortfolioSnapshot
+		if err := scanSnapshot(rows, &s); err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, s)
+	}
+	return snapshots, rows.Err()
+}
+
+// LatestSnapshot implements SnapshotRepository
+func (d *DB) LatestSnapshot(ctx context.Context, portfolioID int64) (*models.PortfolioSnapshot, error) {
+	var s models.PortfolioSnapshot
+	err := scanSnapshot(d.conn.QueryRowContext(ctx, "SELECT "+snapshotColumns+" FROM portfolio_snapshots WHERE portfolio_id = ? ORDER BY date DESC LIMIT 1", portfolioID), &s)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// DeleteSnapshots implements SnapshotRepository
+func (d *DB) DeleteSnapshots(ctx context.Context, portfolioID int64, from string) error {
+	_, err := d.conn.ExecContext(ctx, "DELETE FROM portfolio_snapshots WHERE portfolio_id = ? AND date >= ?", portfolioID, from)
+	return err
+}
+
+// ListTargets implements TargetRepository
+func (d *DB) ListTargets(ctx context.Context, portfolioID int64) ([]models.Target, error) {
+	rows, err := d.conn.QueryContext(ctx, "SELECT id, portfolio_id, kind, key, weight, threshold FROM portfolio_targets WHERE portfolio_id = ? ORDER BY kind, key", portfolioID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	targets := []models.Target{}
+	for rows.Next() {
+		var t models.Target
+		if err := rows.Scan(&t.ID, &t.PortfolioID, &t.Kind, &t.Key, &t.Weight, &t.Threshold); err != nil {
+			return nil, err
+		}
+		targets = append(targets, t)
+	}
+	return targets, rows.Err()
+}
+
+// ReplaceTargets implements TargetRepository
+func (d *DB) ReplaceTargets(ctx context.Context, portfolioID int64, targets []models.Target) error {
+	tx, err := d.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, "DELETE FROM portfolio_targets WHERE portfolio_id = ?", portfolioID); err != nil {
+		return err
+	}
+	for _, t := range targets {
+		if _, err := tx.ExecContext(ctx, "INSERT INTO portfolio_targets (portfolio_id, kind, key, weight, threshold) VALUES (?, ?, ?, ?, ?)",
+			portfolioID, t.Kind, t.Key, t.Weight, t.Threshold); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ListTrackedSymbols implements SymbolRepository
+func (d *DB) ListTrackedSymbols(ctx context.Context) ([]string, error) {
+	rows, err := d.conn.QueryContext(ctx, "SELECT symbol FROM watchlist UNION SELECT symbol FROM transactions WHERE symbol <> '' ORDER BY symbol")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var symbols []string
+	for rows.Next() {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			return nil, err
+		}
+		symbols = append(symbols, s)
+	}
+	return symbols, rows.Err()
+}
+
+// CreateScreen implements ScreenRepository
+func (d *DB) CreateScreen(ctx context.Context, screen *models.Screen) error {
+	res, err := d.conn.ExecContext(ctx, "INSERT INTO screens (user_id, name, filter, universe) VALUES (?, ?, ?, ?)",
+		screen.UserID, screen.Name, screen.Filter, screen.Universe)
+	if err != nil {
+		return err
+	}
+	if screen.ID, err = res.LastInsertId(); err != nil {
+		return err
+	}
+	return d.conn.QueryRowContext(ctx, "SELECT created_at FROM screens WHERE id = ?", screen.ID).Scan(&screen.CreatedAt)
+}
+
+// GetScreen implements ScreenRepository
+func (d *DB) GetScreen(ctx context.Context, userID string, id int64) (*models.Screen, error) {
+	s := models.Screen{UserID: userID}
+	err := d.conn.QueryRowContext(ctx, "SELECT id, name, filter, universe, created_at FROM screens WHERE user_id = ? AND id = ?", userID, id).
+		Scan(&s.ID, &s.Name, &s.Filter, &s.Universe, &s.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// ListScreens implements ScreenRepository
+func (d *DB) ListScreens(ctx context.Context, userID string) ([]models.Screen, error) {
+	rows, err := d.conn.QueryContext(ctx, "SELECT id, name, filter, universe, created_at FROM screens WHERE user_id = ? ORDER BY name", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var screens []models.Screen
+	for rows.Next() {
+		s := models.Screen{UserID: userID}
+		if err := rows.Scan(&s.ID, &s.Name, &s.Filter, &s.Universe, &s.CreatedAt); err != nil {
+			return nil, err
+		}
+		screens = append(screens, s)
+	}
+	return screens, rows.Err()
+}
+
+// DeleteScreen implements ScreenRepository
+func (d *DB) DeleteScreen(ctx context.Context, userID string, id int64) error {
+	_, err := d.conn.ExecContext(ctx, "DELETE FROM screens WHERE user_id = ? AND id = ?", userID, id)
 	return err
 }
 
-// List implements PortfolioRepository
-func (d *DB) ListHoldings(ctx context.Context, userID string) ([]models.Holding, error) {
-	rows, err := d.conn.QueryContext(ctx, "SELECT id, symbol, quantity, buy_price FROM holdings WHERE user_id = ? ORDER BY symbol", userID)
+// feeScheduleColumns is the column list scanned by scanFeeSchedule
+const feeScheduleColumns = "id, name, kind, flat, percent, tiers, minimum, maximum, created_at"
+
+// scanFeeSchedule scans a row selected with feeScheduleColumns; tiers are stored as JSON
+func scanFeeSchedule(row interface{ Scan(...interface{}) error }, s *models.FeeSchedule) error {
+	var tiers string
+	if err := row.Scan(&s.ID, &s.Name, &s.Kind, &s.Flat, &s.Percent, &tiers, &s.Minimum, &s.Maximum, &s.CreatedAt); err != nil {
+		return err
+	}
+	s.Tiers = []models.FeeTier{}
+	return json.Unmarshal([]byte(tiers), &s.Tiers)
+}
+
+// CreateFeeSchedule implements FeeScheduleRepository
+func (d *DB) CreateFeeSchedule(ctx context.Context, s *models.FeeSchedule) error {
+	if s.Tiers == nil {
+		s.Tiers = []models.FeeTier{}
+	}
+	tiers, err := json.Marshal(s.Tiers)
+	if err != nil {
+		return err
+	}
+	res, err := d.conn.ExecContext(ctx, "INSERT INTO fee_schedules (user_id, name, kind, flat, percent, tiers, minimum, maximum) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		s.UserID, s.Name, s.Kind, s.Flat, s.Percent, string(tiers), s.Minimum, s.Maximum)
+	if err != nil {
+		return err
+	}
+	if s.ID, err = res.LastInsertId(); err != nil {
+		return err
+	}
+	return d.conn.QueryRowContext(ctx, "SELECT created_at FROM fee_schedules WHERE id = ?", s.ID).Scan(&s.CreatedAt)
+}
+
+// GetFeeSchedule implements FeeScheduleRepository
+func (d *DB) GetFeeSchedule(ctx context.Context, userID string, id int64) (*models.FeeSchedule, error) {
+	s := models.FeeSchedule{UserID: userID}
+	err := scanFeeSchedule(d.conn.QueryRowContext(ctx, "SELECT "+feeScheduleColumns+" FROM fee_schedules WHERE user_id = ? AND id = ?", userID, id), &s)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// ListFeeSchedules implements FeeScheduleRepository
+func (d *DB) ListFeeSchedules(ctx context.Context, userID string) ([]models.FeeSchedule, error) {
+	rows, err := d.conn.QueryContext(ctx, "SELECT "+feeScheduleColumns+" FROM fee_schedules WHERE user_id = ? ORDER BY name", userID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var holdings []models.Holding
+	var schedules []models.FeeSchedule
 	for rows.Next() {
-		var h models.Holding
-		h.UserID = userID
-		if err := rows.Scan(&h.ID, &h.Symbol, &h.Quantity, &h.BuyPrice); err != nil {
+		s := models.FeeSchedule{UserID: userID}
+		if err := scanFeeSchedule(rows, &s); err != nil {
 			return nil, err
 		}
-		holdings = append(holdings, h)
+		schedules = append(schedules, s)
 	}
-	return holdings, rows.Err()
+	return schedules, rows.Err()
+}
+
+// DeleteFeeSchedule implements FeeScheduleRepository
+func (d *DB) DeleteFeeSchedule(ctx context.Context, userID string, id int64) error {
+	_, err := d.conn.ExecContext(ctx, "DELETE FROM fee_schedules WHERE user_id = ? AND id = ?", userID, id)
+	return err
 }
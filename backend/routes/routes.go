@@ -26,7 +26,18 @@ func Setup(r *gin.Engine, cfg *config.Config, deps *Dependencies) {
 		// Stock (public - proxy through backend only)
 		api.GET("/quote/:symbol", deps.StockHandler.GetQuote)
 		api.GET("/history/:symbol", deps.StockHandler.GetHistory)
+		api.GET("/dividends/:symbol", deps.StockHandler.GetDividends)
+		api.GET("/indicators/:symbol", deps.IndicatorHandler.Get)
 		api.GET("/search", deps.StockHandler.Search)
+		api.GET("/compare", deps.CompareHandler.Compare)
+
+		// Currency conversion
+		api.GET("/fx", deps.FXHandler.Convert)
+
+		// Benchmarks
+		api.GET("/indices", deps.IndexHandler.List)
+		api.GET("/indices/:id/constituents", deps.IndexHandler.Constituents)
+		api.GET("/market/movers", deps.MarketHandler.Movers)
 	}
 
 	// Protected API (JWT required)
@@ -38,16 +49,97 @@ func Setup(r *gin.Engine, cfg *config.Config, deps *Dependencies) {
 		protected.DELETE("/watchlist/:symbol", deps.WatchlistHandler.Remove)
 
 		protected.GET("/portfolio", deps.PortfolioHandler.List)
+		protected.GET("/portfolio/realized", deps.PortfolioHandler.Realized)
+		protected.GET("/portfolio/tax-report", deps.TaxHandler.Report)
+		protected.GET("/portfolio/performance", deps.PerformanceHandler.Performance)
+		protected.GET("/portfolio/history", deps.PerformanceHandler.History)
+		protected.GET("/portfolio/benchmark", deps.PerformanceHandler.Benchmark)
+		protected.GET("/portfolio/risk", deps.PerformanceHandler.Risk)
+		protected.GET("/portfolio/allocation", deps.AllocationHandler.Get)
+		protected.GET("/portfolio/income", deps.DividendHandler.Income)
+		protected.POST("/portfolio/dividends/sync", deps.DividendHandler.Sync)
+		protected.GET("/portfolio/targets", deps.RebalanceHandler.GetTargets)
+		protected.PUT("/portfolio/targets", deps.RebalanceHandler.SetTargets)
+		protected.GET("/portfolio/rebalance", deps.RebalanceHandler.Rebalance)
+		protected.POST("/portfolio/history/backfill", deps.PerformanceHandler.Backfill)
+		protected.POST("/portfolio/import", deps.ImportHandler.Import)
 		protected.POST("/portfolio", deps.PortfolioHandler.Add)
-		protected.DELETE("/portfolio/:id", deps.PortfolioHandler.Remove)
+		protected.PATCH("/portfolio/:id", deps.PortfolioHandler.UpdateHolding)
+		protected.DELETE("/portfolio/:id", deps.PortfolioHandler.DeleteHolding)
+		protected.DELETE("/portfolio/positions/:symbol", deps.PortfolioHandler.RemovePosition)
+
+		protected.GET("/transactions", deps.TransactionHandler.List)
+		protected.POST("/transactions", deps.TransactionHandler.Create)
+		protected.GET("/transactions/:id", deps.TransactionHandler.Get)
+		protected.GET("/transactions/:id/history", deps.TransactionHandler.History)
+		protected.PATCH("/transactions/:id", deps.TransactionHandler.Update)
+		protected.DELETE("/transactions/:id", deps.TransactionHandler.Delete)
+
+		protected.GET("/portfolios", deps.PortfolioHandler.ListPortfolios)
+		protected.POST("/portfolios", deps.PortfolioHandler.CreatePortfolio)
+		protected.GET("/portfolios/consolidated", deps.PortfolioHandler.Consolidated)
+		protected.GET("/portfolios/:pid", deps.PortfolioHandler.List)
+		protected.PUT("/portfolios/:pid", deps.PortfolioHandler.UpdatePortfolio)
+		protected.DELETE("/portfolios/:pid", deps.PortfolioHandler.DeletePortfolio)
+		protected.GET("/portfolios/:pid/realized", deps.PortfolioHandler.Realized)
+		protected.GET("/portfolios/:pid/tax-report", deps.TaxHandler.Report)
+		protected.GET("/portfolios/:pid/performance", deps.PerformanceHandler.Performance)
+		protected.GET("/portfolios/:pid/history", deps.PerformanceHandler.History)
+		protected.GET("/portfolios/:pid/benchmark", deps.PerformanceHandler.Benchmark)
+		protected.GET("/portfolios/:pid/risk", deps.PerformanceHandler.Risk)
+		protected.GET("/portfolios/:pid/allocation", deps.AllocationHandler.Get)
+		protected.GET("/portfolios/:pid/income", deps.DividendHandler.Income)
+		protected.POST("/portfolios/:pid/dividends/sync", deps.DividendHandler.Sync)
+		protected.GET("/portfolios/:pid/targets", deps.RebalanceHandler.GetTargets)
+		protected.PUT("/portfolios/:pid/targets", deps.RebalanceHandler.SetTargets)
+		protected.GET("/portfolios/:pid/rebalance", deps.RebalanceHandler.Rebalance)
+		protected.POST("/portfolios/:pid/history/backfill", deps.PerformanceHandler.Backfill)
+		protected.POST("/portfolios/:pid/import", deps.ImportHandler.Import)
+		protected.POST("/portfolios/:pid/holdings", deps.PortfolioHandler.Add)
+		protected.PATCH("/portfolios/:pid/holdings/:id", deps.PortfolioHandler.UpdateHolding)
+		protected.DELETE("/portfolios/:pid/holdings/:id", deps.PortfolioHandler.DeleteHolding)
+		protected.DELETE("/portfolios/:pid/positions/:symbol", deps.PortfolioHandler.RemovePosition)
+		protected.GET("/portfolios/:pid/transactions", deps.TransactionHandler.List)
+		protected.POST("/portfolios/:pid/transactions", deps.TransactionHandler.Create)
+		protected.GET("/portfolios/:pid/transactions/:id", deps.TransactionHandler.Get)
+		protected.GET("/portfolios/:pid/transactions/:id/history", deps.TransactionHandler.History)
+		protected.PATCH("/portfolios/:pid/transactions/:id", deps.TransactionHandler.Update)
+		protected.DELETE("/portfolios/:pid/transactions/:id", deps.TransactionHandler.Delete)
+
+		protected.GET("/fee-schedules", deps.FeeHandler.List)
+		protected.POST("/fee-schedules", deps.FeeHandler.Create)
+		protected.DELETE("/fee-schedules/:id", deps.FeeHandler.Delete)
+
+		protected.GET("/import/profiles", deps.ImportHandler.Profiles)
+		protected.GET("/export", deps.ExportHandler.Export)
+
+		protected.POST("/screener", deps.ScreenerHandler.Run)
+		protected.GET("/screener/screens", deps.ScreenerHandler.ListScreens)
+		protected.POST("/screener/screens", deps.ScreenerHandler.CreateScreen)
+		protected.DELETE("/screener/screens/:id", deps.ScreenerHandler.DeleteScreen)
 	}
 }
 
 // Dependencies holds all route dependencies
 type Dependencies struct {
-	AuthHandler     *handlers.AuthHandler
-	StockHandler    *handlers.StockHandler
-	WatchlistHandler *handlers.WatchlistHandler
-	PortfolioHandler *handlers.PortfolioHandler
-	AuthService     *services.AuthService
+	AuthHandler        *handlers.AuthHandler
+	StockHandler       *handlers.StockHandler
+	WatchlistHandler   *handlers.WatchlistHandler
+	PortfolioHandler   *handlers.PortfolioHandler
+	TransactionHandler *handlers.TransactionHandler
+	PerformanceHandler *handlers.PerformanceHandler
+	AllocationHandler  *handlers.AllocationHandler
+	RebalanceHandler   *handlers.RebalanceHandler
+	DividendHandler    *handlers.DividendHandler
+	FeeHandler         *handlers.FeeHandler
+	ImportHandler      *handlers.ImportHandler
+	ExportHandler      *handlers.ExportHandler
+	TaxHandler         *handlers.TaxHandler
+	FXHandler          *handlers.FXHandler
+	IndexHandler       *handlers.IndexHandler
+	MarketHandler      *handlers.MarketHandler
+	IndicatorHandler   *handlers.IndicatorHandler
+	ScreenerHandler    *handlers.ScreenerHandler
+	CompareHandler     *handlers.CompareHandler
+	AuthService        *services.AuthService
 }
@@ -0,0 +1,165 @@
+package screener
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNoValue is returned by an Env when a known field has no value for the current symbol
+// (e.g. an indicator without enough history). Comparisons against it are unknown rather
+// than false: NOT leaves them unknown, AND and OR only settle them when the other side
+// decides the result, and an expression that stays unknown does not match.
+var ErrNoValue = errors.New("no value")
+
+// ValueKind distinguishes numeric, string and boolean values
+type ValueKind int
+
+const (
+	KindNumber ValueKind = iota
+	KindString
+	KindBool
+)
+
+// Value is a field or literal value
+type Value struct {
+	Kind ValueKind
+	Num  float64
+	Str  string
+	Bool bool
+}
+
+// Number creates a numeric value
+func Number(n float64) Value { return Value{Kind: KindNumber, Num: n} }
+
+// String creates a string value
+func String(s string) Value { return Value{Kind: KindString, Str: s} }
+
+// Bool creates a boolean value
+func Bool(b bool) Value { return Value{Kind: KindBool, Bool: b} }
+
+// Interface returns the value as a plain Go value for serialization
+func (v Value) Interface() interface{} {
+	switch v.Kind {
+	case KindString:
+		return v.Str
+	case KindBool:
+		return v.Bool
+	}
+	return v.Num
+}
+
+// Env resolves field names for one symbol
+type Env interface {
+	// Lookup returns the field's value, ErrNoValue when it is unavailable for
+	// this symbol, or another error for unknown fields
+	Lookup(field string) (Value, error)
+}
+
+// Eval evaluates expr against env
+func Eval(expr Expr, env Env) (bool, error) {
+	ok, err := expr.eval(env)
+	if errors.Is(err, ErrNoValue) {
+		return false, nil
+	}
+	return ok, err
+}
+
+// eval methods report an unknown result as ErrNoValue
+func (e *logicalExpr) eval(env Env) (bool, error) {
+	l, err := e.left.eval(env)
+	unknown := errors.Is(err, ErrNoValue)
+	if err != nil && !unknown {
+		return false, err
+	}
+	// A known left side that decides the result skips the right one
+	if !unknown && e.and != l {
+		return l, nil
+	}
+	r, err := e.right.eval(env)
+	if err != nil && !errors.Is(err, ErrNoValue) {
+		return false, err
+	}
+	if err == nil && (e.and != r || !unknown) {
+		return r, nil
+	}
+	return false, ErrNoValue
+}
+
+func (e *notExpr) eval(env Env) (bool, error) {
+	x, err := e.x.eval(env)
+	if err != nil {
+		return false, err
+	}
+	return !x, nil
+}
+
+func (e *compareExpr) eval(env Env) (bool, error) {
+	l, err := e.left.resolve(env)
+	if err != nil {
+		return false, err
+	}
+	r, err := e.right.resolve(env)
+	if err != nil {
+		return false, err
+	}
+	return compare(e.op, l, r)
+}
+
+func (o operand) resolve(env Env) (Value, error) {
+	if o.field == "" {
+		return o.value, nil
+	}
+	return env.Lookup(o.field)
+}
+
+func compare(op string, l, r Value) (bool, error) {
+	if l.Kind != r.Kind {
+		return false, fmt.Errorf("cannot compare %s with %s", kindName(l.Kind), kindName(r.Kind))
+	}
+	switch l.Kind {
+	case KindNumber:
+		switch op {
+		case "<":
+			return l.Num < r.Num, nil
+		case "<=":
+			return l.Num <= r.Num, nil
+		case ">":
+			return l.Num > r.Num, nil
+		case ">=":
+			return l.Num >= r.Num, nil
+		case "=":
+			return l.Num == r.Num, nil
+		case "!=":
+			return l.Num != r.Num, nil
+		}
+	case KindString:
+		// Strings compare case-insensitively so sector = "technology" matches
+		switch op {
+		case "=":
+			return strings.EqualFold(l.Str, r.Str), nil
+		case "!=":
+			return !strings.EqualFold(l.Str, r.Str), nil
+		}
+		return false, fmt.Errorf("operator %s is not supported for strings", op)
+	case KindBool:
+		switch op {
+		case "=":
+			return l.Bool == r.Bool, nil
+		case "!=":
+			return l.Bool != r.Bool, nil
+		}
+		return false, fmt.Errorf("operator %s is not supported for booleans", op)
+	}
+	return false, fmt.Errorf("unknown operator %s", op)
+}
+
+func kindName(k ValueKind) string {
+	switch k {
+	case KindString:
+		return "string"
+	case KindBool:
+		return "boolean"
+	}
+	return "number"
+}
@@ -0,0 +1,90 @@
+package screener
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"tinystock/backend/indicators"
+	"tinystock/backend/models"
+)
+
+// FieldSector is resolved from instrument metadata rather than the quote
+const FieldSector = "sector"
+
+// quoteFields maps lower-cased field names to quote accessors
+var quoteFields = map[string]func(q *models.Quote) Value{
+	"symbol":        func(q *models.Quote) Value { return String(q.Symbol) },
+	"name":          func(q *models.Quote) Value { return String(q.Name) },
+	"price":         func(q *models.Quote) Value { return Number(q.Price) },
+	"change":        func(q *models.Quote) Value { return Number(q.Change) },
+	"changepercent": func(q *models.Quote) Value { return Number(q.ChangePct) },
+	"volume":        func(q *models.Quote) Value { return Number(float64(q.Volume)) },
+	"high":          func(q *models.Quote) Value { return Number(q.High) },
+	"low":           func(q *models.Quote) Value { return Number(q.Low) },
+	"marketcap":     func(q *models.Quote) Value { return Number(float64(q.MarketCap)) },
+	"pe":            func(q *models.Quote) Value { return Number(q.PE) },
+	"dividendyield": func(q *models.Quote) Value { return Number(q.DividendYield) },
+	"currency":      func(q *models.Quote) Value { return String(q.Currency) },
+	"assettype":     func(q *models.Quote) Value { return String(q.AssetType) },
+	"marketopen":    func(q *models.Quote) Value { return Bool(q.MarketOpen) },
+}
+
+// fundamentalFields are zero when the provider has no data, which must not match "pe < 15"
+var fundamentalFields = map[string]bool{"marketcap": true, "pe": true, "dividendyield": true}
+
+// indicatorField matches indicator fields such as rsi14, sma50, ema20 or atr14
+var indicatorField = regexp.MustCompile(`^(sma|ema|rsi|atr)(\d+)$`)
+
+// QuoteField returns the named quote field; ok is false for non-quote fields
+func QuoteField(q *models.Quote, field string) (v Value, ok bool, err error) {
+	name := strings.ToLower(field)
+	get, ok := quoteFields[name]
+	if !ok {
+		return Value{}, false, nil
+	}
+	v = get(q)
+	if fundamentalFields[name] && v.Num == 0 {
+		return v, true, ErrNoValue
+	}
+	return v, true, nil
+}
+
+// IndicatorSpec maps an indicator field (e.g. "rsi14", "vwap") to its indicator spec
+func IndicatorSpec(field string) (indicators.Spec, bool) {
+	name := strings.ToLower(field)
+	switch name {
+	case "vwap", "obv":
+		return indicators.Spec{Kind: name}, true
+	}
+	m := indicatorField.FindStringSubmatch(name)
+	if m == nil {
+		return indicators.Spec{}, false
+	}
+	period, err := strconv.Atoi(m[2])
+	if err != nil || period <= 0 || period > indicators.MaxPeriod {
+		return indicators.Spec{}, false
+	}
+	return indicators.Spec{Kind: m[1], Params: []float64{float64(period)}}, true
+}
+
+// KnownField reports whether field can be resolved by the screener
+func KnownField(field string) bool {
+	name := strings.ToLower(field)
+	if _, ok := quoteFields[name]; ok || name == FieldSector {
+		return true
+	}
+	_, ok := IndicatorSpec(name)
+	return ok
+}
+
+// Validate checks that every field in expr is known
+func Validate(expr Expr) error {
+	for _, f := range Fields(expr) {
+		if !KnownField(f) {
+			return fmt.Errorf("unknown field %q", f)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package screener
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokNumber
+	tokString
+	tokOp // comparison operator
+	tokAnd
+	tokOr
+	tokNot
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// lex splits a filter expression into tokens
+func lex(input string) ([]token, error) {
+	var tokens []token
+	i := 0
+	for i < len(input) {
+		c := rune(input[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '(':
+			tokens = append(tokens, token{tokLParen, "(", i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{tokRParen, ")", i})
+			i++
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(input[i+1:], byte(c))
+			if end < 0 {
+				return nil, &SyntaxError{Pos: i, Msg: "unterminated string"}
+			}
+			tokens = append(tokens, token{tokString, input[i+1 : i+1+end], i})
+			i += end + 2
+		case strings.ContainsRune("<>=!", c):
+			start := i
+			i++
+			if i < len(input) && input[i] == '=' {
+				i++
+			}
+			op := input[start:i]
+			switch op {
+			case "<", ">", "<=", ">=", "=", "==", "!=":
+			default:
+				return nil, &SyntaxError{Pos: start, Msg: fmt.Sprintf("unknown operator %q", op)}
+			}
+			if op == "==" {
+				op = "="
+			}
+			tokens = append(tokens, token{tokOp, op, start})
+		case unicode.IsDigit(c) || c == '.' || (c == '-' && i+1 < len(input) && (unicode.IsDigit(rune(input[i+1])) || input[i+1] == '.')):
+			start := i
+			i++
+			for i < len(input) && (unicode.IsDigit(rune(input[i])) || input[i] == '.' || input[i] == 'e' || input[i] == 'E' ||
+				// an exponent may carry a sign, as in 1e-5
+				((input[i] == '-' || input[i] == '+') && (input[i-1] == 'e' || input[i-1] == 'E'))) {
+				i++
+			}
+			tokens = append(tokens, token{tokNumber, input[start:i], start})
+		case unicode.IsLetter(c) || c == '_':
+			start := i
+			for i < len(input) && (unicode.IsLetter(rune(input[i])) || unicode.IsDigit(rune(input[i])) || input[i] == '_') {
+				i++
+			}
+			word := input[start:i]
+			switch strings.ToUpper(word) {
+			case "AND":
+				tokens = append(tokens, token{tokAnd, word, start})
+			case "OR":
+				tokens = append(tokens, token{tokOr, word, start})
+			case "NOT":
+				tokens = append(tokens, token{tokNot, word, start})
+			default:
+				tokens = append(tokens, token{tokIdent, word, start})
+			}
+		default:
+			return nil, &SyntaxError{Pos: i, Msg: fmt.Sprintf("unexpected character %q", c)}
+		}
+	}
+	tokens = append(tokens, token{tokEOF, "", len(input)})
+	return tokens, nil
+}
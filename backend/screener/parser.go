@@ -0,0 +1,212 @@
+// Package screener parses and evaluates stock filter expressions such as
+//
+//	price > 100 AND changePercent < -3 AND (rsi14 < 30 OR sector = "Technology")
+//
+// Comparisons take a field or literal on either side. AND binds tighter than OR,
+// NOT negates the following term, and AND/OR short-circuit so that expensive
+// fields are only resolved when they can affect the result.
+package screener
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SyntaxError reports a malformed expression and the byte offset of the problem
+type SyntaxError struct {
+	Pos int
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at position %d: %s", e.Pos, e.Msg)
+}
+
+// Expr is a parsed filter expression
+type Expr interface {
+	eval(env Env) (bool, error)
+	fields(seen map[string]bool)
+}
+
+type logicalExpr struct {
+	and         bool
+	left, right Expr
+}
+
+type notExpr struct {
+	x Expr
+}
+
+// operand is a field reference or a literal
+type operand struct {
+	field string
+	value Value
+}
+
+type compareExpr struct {
+	op          string
+	left, right operand
+}
+
+// Parse parses a filter expression
+func Parse(input string) (Expr, error) {
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	if p.peek().kind == tokEOF {
+		return nil, &SyntaxError{Pos: 0, Msg: "empty expression"}
+	}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, &SyntaxError{Pos: t.pos, Msg: fmt.Sprintf("unexpected %q", t.text)}
+	}
+	return expr, nil
+}
+
+// Fields returns the distinct field names an expression references, sorted
+func Fields(expr Expr) []string {
+	seen := make(map[string]bool)
+	expr.fields(seen)
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token { return p.tokens[p.pos] }
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokOr {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalExpr{and: false, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokAnd {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalExpr{and: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (Expr, error) {
+	switch p.peek().kind {
+	case tokNot:
+		p.next()
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &notExpr{x: x}, nil
+	case tokLParen:
+		p.next()
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if t := p.next(); t.kind != tokRParen {
+			return nil, &SyntaxError{Pos: t.pos, Msg: "expected )"}
+		}
+		return x, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (Expr, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	opTok := p.next()
+	if opTok.kind != tokOp {
+		return nil, &SyntaxError{Pos: opTok.pos, Msg: "expected comparison operator"}
+	}
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	if left.field == "" && right.field == "" {
+		return nil, &SyntaxError{Pos: opTok.pos, Msg: "comparison needs at least one field"}
+	}
+	return &compareExpr{op: opTok.text, left: left, right: right}, nil
+}
+
+func (p *parser) parseOperand() (operand, error) {
+	t := p.next()
+	switch t.kind {
+	case tokIdent:
+		switch strings.ToLower(t.text) {
+		case "true":
+			return operand{value: Bool(true)}, nil
+		case "false":
+			return operand{value: Bool(false)}, nil
+		}
+		return operand{field: t.text}, nil
+	case tokNumber:
+		n, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return operand{}, &SyntaxError{Pos: t.pos, Msg: fmt.Sprintf("invalid number %q", t.text)}
+		}
+		return operand{value: Number(n)}, nil
+	case tokString:
+		return operand{value: String(t.text)}, nil
+	case tokEOF:
+		return operand{}, &SyntaxError{Pos: t.pos, Msg: "unexpected end of expression"}
+	}
+	return operand{}, &SyntaxError{Pos: t.pos, Msg: fmt.Sprintf("unexpected %q", t.text)}
+}
+
+func (e *logicalExpr) fields(seen map[string]bool) {
+	e.left.fields(seen)
+	e.right.fields(seen)
+}
+
+func (e *notExpr) fields(seen map[string]bool) { e.x.fields(seen) }
+
+func (e *compareExpr) fields(seen map[string]bool) {
+	for _, o := range []operand{e.left, e.right} {
+		if o.field != "" {
+			seen[o.field] = true
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package screener
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// mapEnv resolves fields from a map; a missing field has no value
+type mapEnv map[string]Value
+
+func (m mapEnv) Lookup(field string) (Value, error) {
+	v, ok := m[field]
+	if !ok {
+		return Value{}, ErrNoValue
+	}
+	return v, nil
+}
+
+// env is a loss-making technology stock with no P/E
+var env = mapEnv{
+	"price":         Number(50),
+	"changePercent": Number(-4),
+	"rsi14":         Number(25),
+	"sector":        String("Technology"),
+}
+
+func TestLex(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"changePercent < -3", []string{"changePercent", "<", "-3"}},
+		{"price>=1e-5", []string{"price", ">=", "1e-5"}},
+		{"volume > 2.5E+6 AND x == .5", []string{"volume", ">", "2.5E+6", "AND", "x", "=", ".5"}},
+		{"sector = 'Consumer Cyclical'", []string{"sector", "=", "Consumer Cyclical"}},
+		{"NOT(a!=b)", []string{"NOT", "(", "a", "!=", "b", ")"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tokens, err := lex(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, tok := range tokens[:len(tokens)-1] {
+				got = append(got, tok.text)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tokens = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEval(t *testing.T) {
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		// AND binds tighter than OR: true OR (true AND false)
+		{`price < 60 OR price > 100 AND sector = "Energy"`, true},
+		{`(price < 60 OR price > 100) AND sector = "Energy"`, false},
+		{`NOT price > 100`, true},
+		{`NOT (price < 60 AND sector = "technology")`, false},
+		{`NOT NOT price < 60`, true},
+		{`changePercent < -3`, true},
+		{`changePercent < -5`, false},
+		{`-3 > changePercent`, true},
+		{`sector = 'Technology' AND sector != "Energy"`, true},
+		{`price > 1e-5 AND price = 5e1`, true},
+		{`price < 5E+1`, false},
+		{`price > 100 AND rsi14 < 30 OR sector = "Technology"`, true},
+		// pe has no value: its comparisons are unknown, and NOT keeps them so
+		{`pe < 15`, false},
+		{`pe >= 15`, false},
+		{`NOT pe < 15`, false},
+		{`NOT pe < 15 OR price < 60`, true},
+		{`pe < 15 OR price < 60`, true},
+		{`pe < 15 AND price > 100`, false},
+		{`NOT (pe < 15 AND price > 100)`, true},
+		{`NOT (pe < 15 OR price < 60)`, false},
+		{`NOT (pe < 15 OR price > 100)`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			expr, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := Eval(expr, env)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{`sector > 5`, "cannot compare string with number"},
+		{`price = "50"`, "cannot compare number with string"},
+		{`sector < "M"`, "operator < is not supported for strings"},
+		// An unknown left side does not hide an error on the right
+		{`pe < 15 OR sector > 5`, "cannot compare string with number"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			expr, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := Eval(expr, env); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		pos  int
+		msg  string
+	}{
+		{``, 0, "empty expression"},
+		{`price >`, 7, "unexpected end of expression"},
+		{`price > 100 AND`, 15, "unexpected end of expression"},
+		{`(price > 100`, 12, "expected )"},
+		{`price > 100)`, 11, `unexpected ")"`},
+		{`price > 100 pe < 5`, 12, `unexpected "pe"`},
+		{`sector = "Tech`, 9, "unterminated string"},
+		{`price ! 5`, 6, `unknown operator "!"`},
+		{`price # 5`, 6, "unexpected character '#'"},
+		{`price 5`, 6, "expected comparison operator"},
+		{`1 < 2`, 2, "comparison needs at least one field"},
+		{`price > 1e`, 8, `invalid number "1e"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := Parse(tt.expr)
+			var se *SyntaxError
+			if !errors.As(err, &se) {
+				t.Fatalf("err = %v, want a SyntaxError", err)
+			}
+			if se.Pos != tt.pos || se.Msg != tt.msg {
+				t.Errorf("got %d %q, want %d %q", se.Pos, se.Msg, tt.pos, tt.msg)
+			}
+		})
+	}
+}
+
+func TestFields(t *testing.T) {
+	expr, err := Parse(`NOT (rsi14 < 30 OR price > 10) AND price < rsi14 AND "Energy" = sector`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := Fields(expr), []string{"price", "rsi14", "sector"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Fields = %q, want %q", got, want)
+	}
+}
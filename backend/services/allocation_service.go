@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"tinystock/backend/models"
+)
+
+const (
+	// unknownBucket collects holdings whose metadata the provider doesn't report
+	unknownBucket = "Unknown"
+	// cashBucket holds cash balances when grouping by anything but currency
+	cashBucket = "Cash"
+)
+
+var ErrInvalidAllocation = errors.New("invalid allocation")
+
+// AllocationService groups portfolio holdings by instrument metadata
+type AllocationService struct {
+	portfolio *PortfolioService
+	stock     *StockService
+	indices   *IndexService
+}
+
+// NewAllocationService creates a new AllocationService
+func NewAllocationService(portfolio *PortfolioService, stock *StockService, indices *IndexService) *AllocationService {
+	return &AllocationService{portfolio: portfolio, stock: stock, indices: indices}
+}
+
+// parseAllocationBy accepts a grouping name case-insensitively
+func parseAllocationBy(by string) (string, error) {
+	for _, b := range []string{models.AllocationBySector, models.AllocationByAssetType, models.AllocationByCurrency, models.AllocationByExchange, models.AllocationBySymbol} {
+		if strings.EqualFold(by, b) {
+			return b, nil
+		}
+	}
+	if by == "" {
+		return models.AllocationBySector, nil
+	}
+	return "", fmt.Errorf("%w: by must be sector, assetType, currency, exchange or symbol", ErrInvalidAllocation)
+}
+
+// GetAllocation returns a portfolio's value (0 for the default portfolio) grouped
+// by sector, assetType, currency, exchange or symbol, in baseCurrency
+func (s *AllocationService) GetAllocation(ctx context.Context, userID string, portfolioID int64, by, baseCurrency string) (*models.Allocation, error) {
+	by, err := parseAllocationBy(by)
+	if err != nil {
+		return nil, err
+	}
+	summary, err := s.portfolio.GetPortfolio(ctx, userID, portfolioID, baseCurrency, "")
+	if err != nil {
+		return nil, err
+	}
+
+	quotes := make(map[string]*models.Quote)
+	if by == models.AllocationByAssetType || by == models.AllocationByExchange {
+		symbols := make([]string, len(summary.Holdings))
+		for i, h := range summary.Holdings {
+			symbols[i] = h.Symbol
+		}
+		// Metadata is best-effort: without quotes every holding lands in Unknown
+		list, _ := s.stock.GetQuotes(ctx, symbols)
+		for _, q := range list {
+			quotes[strings.ToUpper(q.Symbol)] = q
+		}
+	}
+
+	buckets := make(map[string]*models.AllocationBucket)
+	bucket := func(key string) *models.AllocationBucket {
+		if strings.TrimSpace(key) == "" {
+			key = unknownBucket
+		}
+		b, ok := buckets[key]
+		if !ok {
+			b = &models.AllocationBucket{Key: key, Symbols: []string{}}
+			buckets[key] = b
+		}
+		return b
+	}
+
+	for _, h := range summary.Holdings {
+		var key string
+		switch by {
+		case models.AllocationBySector:
+			key, _ = s.indices.Sector(h.Symbol)
+		case models.AllocationByAssetType:
+			if q := quotes[h.Symbol]; q != nil {
+				key = q.AssetType
+			}
+		case models.AllocationByCurrency:
+			key, _ = NormalizeCurrency(h.Currency)
+		case models.AllocationByExchange:
+			if q := quotes[h.Symbol]; q != nil {
+				key = q.Exchange
+			}
+		case models.AllocationBySymbol:
+			key = h.Symbol
+		}
+		b := bucket(key)
+		b.Value = b.Value.Add(h.MarketValueBase)
+		b.Cost = b.Cost.Add(h.CostBasisBase)
+		b.PnL = b.PnL.Add(h.MarketValueBase.Sub(h.CostBasisBase))
+		b.Symbols = append(b.Symbols, h.Symbol)
+	}
+	for _, c := range summary.Cash {
+		key := cashBucket
+		if by == models.AllocationByCurrency {
+			key = c.Currency
+		}
+		b := bucket(key)
+		b.Value = b.Value.Add(c.AmountBase)
+		b.Cost = b.Cost.Add(c.AmountBase)
+	}
+
+	result := &models.Allocation{
+		PortfolioID:  summary.PortfolioID,
+		BaseCurrency: summary.BaseCurrency,
+		By:           by,
+		TotalValue:   summary.TotalValue,
+		Buckets:      make([]models.AllocationBucket, 0, len(buckets)),
+	}
+	for _, b := range buckets {
+		b.Weight = percentOf(b.Value, summary.TotalValue)
+		result.Buckets = append(result.Buckets, *b)
+	}
+	sort.Slice(result.Buckets, func(i, j int) bool {
+		if c := result.Buckets[i].Value.Cmp(result.Buckets[j].Value); c != 0 {
+			return c > 0
+		}
+		return result.Buckets[i].Key < result.Buckets[j].Key
+	})
+	return result, nil
+}
@@ -38,8 +38,13 @@ func (c *MemoryCache) Get(key string) (interface{}, bool) {
 
 // Set stores a value with TTL
 func (c *MemoryCache) Set(key string, value interface{}) {
+	c.SetWithTTL(key, value, c.ttl)
+}
+
+// SetWithTTL stores a value with a per-item TTL overriding the cache default
+func (c *MemoryCache) SetWithTTL(key string, value interface{}, ttl time.Duration) {
 	c.mu.Lock()
-	c.items[key] = CacheItem{Value: value, ExpiresAt: time.Now().Add(c.ttl)}
+	c.items[key] = CacheItem{Value: value, ExpiresAt: time.Now().Add(ttl)}
 	c.mu.Unlock()
 }
 
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"tinystock/backend/analytics"
+	"tinystock/backend/models"
+)
+
+const (
+	minCompareSymbols = 2
+	maxCompareSymbols = 10
+)
+
+var ErrInvalidComparison = errors.New("invalid comparison")
+
+// CompareService lines up several symbols' history for side-by-side analysis
+type CompareService struct {
+	stock *StockService
+}
+
+// NewCompareService creates a new CompareService
+func NewCompareService(stock *StockService) *CompareService {
+	return &CompareService{stock: stock}
+}
+
+// Compare rebases symbols to 100 from their first common date and computes pairwise
+// return correlation and annualized volatility over range_
+func (s *CompareService) Compare(ctx context.Context, symbols []string, range_ string) (*models.Comparison, error) {
+	symbols = dedupeSymbols(symbols)
+	if len(symbols) < minCompareSymbols || len(symbols) > maxCompareSymbols {
+		return nil, fmt.Errorf("%w: between %d and %d symbols are required", ErrInvalidComparison, minCompareSymbols, maxCompareSymbols)
+	}
+
+	series, err := s.stock.GetCloses(ctx, symbols, range_)
+	if err != nil {
+		return nil, err
+	}
+	dates, aligned := analytics.Align(series)
+	if len(dates) == 0 {
+		return nil, fmt.Errorf("%w: symbols have no overlapping history", ErrInvalidComparison)
+	}
+	start := dates[0]
+
+	result := &models.Comparison{
+		Symbols:     symbols,
+		Range:       range_,
+		Dates:       dates,
+		Series:      make(map[string][]float64, len(symbols)),
+		Correlation: make([][]*float64, len(symbols)),
+		Volatility:  make(map[string]float64, len(symbols)),
+		Return:      make(map[string]float64, len(symbols)),
+	}
+	for _, sym := range symbols {
+		rebased := analytics.Rebase(aligned[sym], 100)
+		result.Series[sym] = rebased
+		result.Return[sym] = rebased[len(rebased)-1] - 100
+
+		own := series[sym].Since(start)
+		result.Volatility[sym] = analytics.AnnualizedVolatility(analytics.Returns(own.Values), analytics.PeriodsPerYear(own.Dates))
+	}
+
+	// Correlate returns only across dates both symbols traded, so forward-filled
+	// holidays do not register as zero-return days
+	for i, a := range symbols {
+		result.Correlation[i] = make([]*float64, len(symbols))
+		for j, b := range symbols {
+			if i == j {
+				one := 1.0
+				result.Correlation[i][j] = &one
+				continue
+			}
+			if j < i {
+				result.Correlation[i][j] = result.Correlation[j][i]
+				continue
+			}
+			xs, ys, _ := analytics.Intersect(series[a].Since(start), series[b].Since(start))
+			if r, ok := analytics.Correlation(analytics.Returns(xs), analytics.Returns(ys)); ok {
+				result.Correlation[i][j] = &r
+			}
+		}
+	}
+	return result, nil
+}
+
+// dedupeSymbols upper-cases and trims symbols, dropping blanks and repeats
+func dedupeSymbols(symbols []string) []string {
+	seen := make(map[string]bool, len(symbols))
+	out := make([]string, 0, len(symbols))
+	for _, sym := range symbols {
+		sym = strings.ToUpper(strings.TrimSpace(sym))
+		if sym == "" || seen[sym] {
+			continue
+		}
+		seen[sym] = true
+		out = append(out, sym)
+	}
+	return out
+}
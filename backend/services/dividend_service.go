@@ -0,0 +1,222 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"tinystock/backend/internal/decimal"
+	"tinystock/backend/ledger"
+	"tinystock/backend/models"
+)
+
+// DividendService records provider dividends in the ledger and reports income
+type DividendService struct {
+	portfolio *PortfolioService
+	stock     *StockService
+}
+
+// NewDividendService creates a new DividendService
+func NewDividendService(portfolio *PortfolioService, stock *StockService) *DividendService {
+	return &DividendService{portfolio: portfolio, stock: stock}
+}
+
+// dividendRange is the shortest provider range that reaches back to since
+func dividendRange(since string, now time.Time) string {
+	start, err := time.Parse("2006-01-02", since)
+	if err != nil {
+		return "max"
+	}
+	for _, r := range []struct {
+		years int
+		name  string
+	}{{1, "1y"}, {2, "2y"}, {5, "5y"}, {10, "10y"}} {
+		if !start.Before(now.AddDate(-r.years, 0, 0)) {
+			return r.name
+		}
+	}
+	return "max"
+}
+
+// Sync records a dividend transaction for every provider dividend paid on shares the
+// portfolio held at the close before the ex-date. The transaction is dated on the
+// ex-date; an existing dividend for the symbol on that date counts as already recorded,
+// so running Sync again is harmless.
+func (s *DividendService) Sync(ctx context.Context, userID string, portfolioID int64) (*models.DividendSync, error) {
+	p, err := s.portfolio.portfolio(ctx, userID, portfolioID)
+	if err != nil {
+		return nil, err
+	}
+	txs, err := s.portfolio.txs.ListTransactions(ctx, userID, p.ID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	first := make(map[string]string)    // first trade date per symbol
+	currency := make(map[string]string) // trading currency per symbol
+	recorded := make(map[string]bool)   // symbol + date of existing dividends
+	for _, t := range txs {
+		if t.Symbol == "" {
+			continue
+		}
+		if d, ok := first[t.Symbol]; !ok || t.TradeDate < d {
+			first[t.Symbol] = t.TradeDate
+		}
+		if currency[t.Symbol] == "" {
+			currency[t.Symbol] = t.Currency
+		}
+		if t.Type == models.TxDividend {
+			recorded[t.Symbol+":"+t.TradeDate] = true
+		}
+	}
+	symbols := make([]string, 0, len(first))
+	for sym := range first {
+		symbols = append(symbols, sym)
+	}
+	sort.Strings(symbols)
+
+	now := time.Now().UTC()
+	today := now.Format("2006-01-02")
+	result := &models.DividendSync{PortfolioID: p.ID, Recorded: []models.Transaction{}, Errors: []string{}}
+	for _, sym := range symbols {
+		events, err := s.stock.GetDividends(ctx, sym, dividendRange(first[sym], now))
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", sym, err))
+			continue
+		}
+		for _, ev := range events {
+			if ev.ExDate <= first[sym] || ev.ExDate > today {
+				continue
+			}
+			shares := ledger.SharesBefore(txs, sym, ev.ExDate)
+			if recorded[sym+":"+ev.ExDate] || shares.Sign() <= 0 {
+				result.Skipped++
+				continue
+			}
+			cur := ev.Currency
+			if cur == "" {
+				cur = currency[sym]
+			}
+			// Minor units such as GBp are booked in the major currency
+			iso, factor := NormalizeCurrency(cur)
+			perShare := decimal.NewFromFloat(ev.Amount).Mul(decimal.NewFromFloat(factor))
+			tx := models.Transaction{
+				UserID:      userID,
+				PortfolioID: p.ID,
+				Symbol:      sym,
+				Type:        models.TxDividend,
+				Amount:      shares.Mul(perShare).RoundCurrency(iso),
+				Currency:    iso,
+				TradeDate:   ev.ExDate,
+				Note:        fmt.Sprintf("%g %s per share on %s shares", ev.Amount, cur, shares),
+			}
+			if err := s.portfolio.RecordTransaction(ctx, &tx); err != nil {
+				return nil, fmt.Errorf("record %s dividend on %s: %w", sym, ev.ExDate, err)
+			}
+			recorded[sym+":"+ev.ExDate] = true
+			result.Recorded = append(result.Recorded, tx)
+		}
+	}
+	return result, nil
+}
+
+// Income reports dividend income received over the last 12 months per symbol, yield
+// on cost, and a 12-month projection from each holding's current share count and the
+// dividends declared per share over the last year. Symbols sold since still report
+// the income they paid. Amounts are in baseCurrency.
+func (s *DividendService) Income(ctx context.Context, userID string, portfolioID int64, baseCurrency string) (*models.IncomeReport, error) {
+	summary, err := s.portfolio.GetPortfolio(ctx, userID, portfolioID, baseCurrency, "")
+	if err != nil {
+		return nil, err
+	}
+	txs, err := s.portfolio.txs.ListTransactions(ctx, userID, summary.PortfolioID, "")
+	if err != nil {
+		return nil, err
+	}
+	base := summary.BaseCurrency
+	now := time.Now().UTC()
+	today := now.Format("2006-01-02")
+	cutoff := now.AddDate(-1, 0, 0).Format("2006-01-02")
+
+	report := &models.IncomeReport{
+		PortfolioID:  summary.PortfolioID,
+		BaseCurrency: base,
+		AsOf:         today,
+		Holdings:     []models.HoldingIncome{},
+	}
+	rows := make(map[string]*models.HoldingIncome)
+	var order []string
+	row := func(symbol string) *models.HoldingIncome {
+		r, ok := rows[symbol]
+		if !ok {
+			r = &models.HoldingIncome{Symbol: symbol}
+			rows[symbol] = r
+			order = append(order, symbol)
+		}
+		return r
+	}
+
+	for _, h := range summary.Holdings {
+		r := row(h.Symbol)
+		r.Quantity = h.Quantity
+		r.Currency = h.Currency
+		r.CostBasis = h.CostBasisBase
+		r.MarketValue = h.MarketValueBase
+
+		// Projections are best-effort: a symbol the provider can't answer for projects nothing
+		events, err := s.stock.GetDividends(ctx, h.Symbol, "1y")
+		if err != nil {
+			continue
+		}
+		for _, ev := range events {
+			// Declared dividends not yet ex are left out of the trailing rate
+			if ev.ExDate <= cutoff || ev.ExDate > today {
+				continue
+			}
+			iso, factor := NormalizeCurrency(ev.Currency)
+			if ev.Currency == "" {
+				iso, factor = NormalizeCurrency(h.Currency)
+			}
+			perShare := decimal.NewFromFloat(ev.Amount).Mul(decimal.NewFromFloat(factor))
+			amount, err := s.portfolio.baseAmount(ctx, h.Quantity.Mul(perShare), iso, base)
+			if err != nil {
+				return nil, fmt.Errorf("convert %s: %w", h.Symbol, err)
+			}
+			r.DividendPerShare = r.DividendPerShare.Add(perShare)
+			r.ProjectedIncome = r.ProjectedIncome.Add(amount)
+			r.LastExDate = ev.ExDate
+		}
+	}
+
+	for _, t := range txs {
+		if t.Type != models.TxDividend || t.Symbol == "" || t.TradeDate <= cutoff {
+			continue
+		}
+		amount, err := s.portfolio.baseAmount(ctx, t.Amount, t.Currency, base)
+		if err != nil {
+			return nil, fmt.Errorf("convert %s: %w", t.Symbol, err)
+		}
+		r := row(t.Symbol)
+		if r.Currency == "" {
+			r.Currency = t.Currency
+		}
+		r.TrailingIncome = r.TrailingIncome.Add(amount)
+	}
+
+	for _, sym := range order {
+		r := rows[sym]
+		r.YieldOnCost = percentOf(r.TrailingIncome, r.CostBasis)
+		r.CurrentYield = percentOf(r.ProjectedIncome, r.MarketValue)
+		report.TrailingIncome = report.TrailingIncome.Add(r.TrailingIncome)
+		report.ProjectedIncome = report.ProjectedIncome.Add(r.ProjectedIncome)
+		report.Holdings = append(report.Holdings, *r)
+	}
+	report.YieldOnCost = percentOf(report.TrailingIncome, summary.TotalCost)
+	report.CurrentYield = percentOf(report.ProjectedIncome, summary.HoldingsValue)
+	sort.SliceStable(report.Holdings, func(i, j int) bool {
+		return report.Holdings[i].ProjectedIncome.Add(report.Holdings[i].TrailingIncome).Cmp(
+			report.Holdings[j].ProjectedIncome.Add(report.Holdings[j].TrailingIncome)) > 0
+	})
+	return report, nil
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"tinystock/backend/exporter"
+	"tinystock/backend/models"
+	"tinystock/backend/repository"
+)
+
+var ErrInvalidExport = errors.New("invalid export")
+
+// ExportService streams a user's holdings, ledger or watchlist as CSV, JSON or OFX
+type ExportService struct {
+	portfolio *PortfolioService
+	watchlist repository.WatchlistRepository
+	fx        *FXService
+}
+
+// NewExportService creates a new ExportService
+func NewExportService(portfolio *PortfolioService, watchlist repository.WatchlistRepository, fx *FXService) *ExportService {
+	return &ExportService{portfolio: portfolio, watchlist: watchlist, fx: fx}
+}
+
+// Export writes the dataset what of a portfolio (0 for the default) in format.
+// Everything that can fail up front is checked before open is called with the
+// content type and a file name; open returns the destination. Transactions are
+// read from the repository as they are written.
+func (s *ExportService) Export(ctx context.Context, userID string, portfolioID int64, format, what string, open func(contentType, filename string) io.Writer) error {
+	if err := exporter.Supported(format, what); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidExport, err)
+	}
+	now := time.Now().UTC()
+	header := models.ExportHeader{What: what, ExportedAt: now}
+
+	var records []interface{}
+	switch what {
+	case models.ExportWatchlist:
+		items, err := s.watchlist.List(ctx, userID)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			records = append(records, item)
+		}
+	default:
+		p, err := s.portfolio.portfolio(ctx, userID, portfolioID)
+		if err != nil {
+			return err
+		}
+		header.Portfolio = p
+		if header.BaseCurrency, _, err = s.portfolio.resolve(p, "", ""); err != nil {
+			return err
+		}
+		if what == models.ExportHoldings {
+			summary, err := s.portfolio.GetPortfolio(ctx, userID, p.ID, "", "")
+			if err != nil {
+				return err
+			}
+			for _, h := range summary.Holdings {
+				records = append(records, h)
+			}
+		}
+	}
+
+	contentType, ext := exporter.ContentType(format)
+	rate := func(currency, date string) (float64, error) {
+		if date == "" {
+			r, err := s.fx.GetRate(ctx, currency, header.BaseCurrency)
+			if err != nil {
+				return 0, err
+			}
+			return r.Rate, nil
+		}
+		day, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			return 0, err
+		}
+		r, err := s.fx.GetRateOn(ctx, currency, header.BaseCurrency, day)
+		if err != nil {
+			return 0, err
+		}
+		return r.Rate, nil
+	}
+	w, err := exporter.New(open(contentType, fmt.Sprintf("tinystock-%s-%s.%s", what, now.Format("2006-01-02"), ext)), format, header, rate)
+	if err != nil {
+		return err
+	}
+	if what == models.ExportTransactions {
+		err = s.portfolio.txs.EachTransaction(ctx, userID, header.Portfolio.ID, func(t models.Transaction) error {
+			return w.Write(t)
+		})
+	} else {
+		for _, r := range records {
+			if err = w.Write(r); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return w.Close()
+}
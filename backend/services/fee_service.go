@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"tinystock/backend/internal/decimal"
+	"tinystock/backend/models"
+	"tinystock/backend/repository"
+)
+
+var (
+	ErrInvalidFeeSchedule  = errors.New("invalid fee schedule")
+	ErrFeeScheduleNotFound = errors.New("fee schedule not found")
+)
+
+// FeeService manages a user's broker fee schedules
+type FeeService struct {
+	repo repository.FeeScheduleRepository
+}
+
+// NewFeeService creates a new FeeService
+func NewFeeService(repo repository.FeeScheduleRepository) *FeeService {
+	return &FeeService{repo: repo}
+}
+
+// ComputeFee returns the commission a schedule charges on a trade worth notional,
+// clamped to the schedule's minimum and maximum. The result is exact; callers round
+// it to the trade currency.
+func ComputeFee(s models.FeeSchedule, notional decimal.Decimal) decimal.Decimal {
+	notional = notional.Abs()
+	hundred := decimal.NewFromInt(100)
+	fee := decimal.Zero
+	switch s.Kind {
+	case models.FeeFlat:
+		fee = s.Flat
+	case models.FeePercent:
+		fee = notional.Mul(s.Percent).Div(hundred, decimal.Precision)
+	case models.FeeTiered:
+		lower := decimal.Zero
+		for _, t := range s.Tiers {
+			upper := t.UpTo
+			if upper.IsZero() || upper.Cmp(notional) > 0 {
+				upper = notional
+			}
+			if upper.Cmp(lower) > 0 {
+				fee = fee.Add(upper.Sub(lower).Mul(t.Percent).Div(hundred, decimal.Precision))
+			}
+			if t.UpTo.IsZero() || t.UpTo.Cmp(notional) >= 0 {
+				break
+			}
+			lower = t.UpTo
+		}
+	}
+	fee = decimal.Max(fee, s.Minimum)
+	if s.Maximum.Sign() > 0 {
+		fee = decimal.Min(fee, s.Maximum)
+	}
+	return fee
+}
+
+// validateFeeSchedule normalizes the name and kind and checks the rates
+func validateFeeSchedule(s *models.FeeSchedule) error {
+	s.Name = strings.TrimSpace(s.Name)
+	if s.Name == "" || len(s.Name) > 100 {
+		return fmt.Errorf("%w: name is required (max 100 characters)", ErrInvalidFeeSchedule)
+	}
+	s.Kind = strings.ToLower(strings.TrimSpace(s.Kind))
+	if s.Flat.Sign() < 0 || s.Minimum.Sign() < 0 || s.Maximum.Sign() < 0 {
+		return fmt.Errorf("%w: flat, minimum and maximum must not be negative", ErrInvalidFeeSchedule)
+	}
+	if s.Maximum.Sign() > 0 && s.Maximum.Cmp(s.Minimum) < 0 {
+		return fmt.Errorf("%w: maximum is below minimum", ErrInvalidFeeSchedule)
+	}
+	if !validPercent(s.Percent) {
+		return fmt.Errorf("%w: percent must be between 0 and 100", ErrInvalidFeeSchedule)
+	}
+	switch s.Kind {
+	case models.FeeFlat, models.FeePercent:
+		s.Tiers = []models.FeeTier{}
+	case models.FeeTiered:
+		if len(s.Tiers) == 0 {
+			return fmt.Errorf("%w: tiered schedules need at least one tier", ErrInvalidFeeSchedule)
+		}
+		prev := decimal.Zero
+		for i, t := range s.Tiers {
+			if !validPercent(t.Percent) {
+				return fmt.Errorf("%w: tier percent must be between 0 and 100", ErrInvalidFeeSchedule)
+			}
+			last := i == len(s.Tiers)-1
+			if (t.UpTo.IsZero() && !last) || (!t.UpTo.IsZero() && t.UpTo.Cmp(prev) <= 0) {
+				return fmt.Errorf("%w: tier upTo values must increase, with 0 (unbounded) only on the last tier", ErrInvalidFeeSchedule)
+			}
+			prev = t.UpTo
+		}
+	default:
+		return fmt.Errorf("%w: kind must be flat, percent or tiered", ErrInvalidFeeSchedule)
+	}
+	return nil
+}
+
+// validPercent reports whether p is a percentage from 0 to 100
+func validPercent(p decimal.Decimal) bool {
+	return p.Sign() >= 0 && p.Cmp(decimal.NewFromInt(100)) <= 0
+}
+
+// CreateFeeSchedule validates and saves a fee schedule
+func (s *FeeService) CreateFeeSchedule(ctx context.Context, schedule *models.FeeSchedule) error {
+	if err := validateFeeSchedule(schedule); err != nil {
+		return err
+	}
+	return s.repo.CreateFeeSchedule(ctx, schedule)
+}
+
+// ListFeeSchedules returns a user's fee schedules
+func (s *FeeService) ListFeeSchedules(ctx context.Context, userID string) ([]models.FeeSchedule, error) {
+	schedules, err := s.repo.ListFeeSchedules(ctx, userID)
+	if schedules == nil && err == nil {
+		schedules = []models.FeeSchedule{}
+	}
+	return schedules, err
+}
+
+// DeleteFeeSchedule removes a fee schedule; transactions keep the charges computed with it
+func (s *FeeService) DeleteFeeSchedule(ctx context.Context, userID string, id int64) error {
+	return s.repo.DeleteFeeSchedule(ctx, userID, id)
+}
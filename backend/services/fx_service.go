@@ -0,0 +1,207 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"tinystock/backend/analytics"
+	"tinystock/backend/models"
+)
+
+// DefaultBaseCurrency is used when no base currency is configured or requested
+const DefaultBaseCurrency = "USD"
+
+var (
+	ErrInvalidCurrency = errors.New("invalid currency")
+	ErrFXUnavailable   = errors.New("exchange rate unavailable")
+)
+
+// minorUnits maps Yahoo minor-unit currency codes (e.g. LSE prices in pence) to their major currency
+var minorUnits = map[string]struct {
+	major  string
+	factor float64
+}{
+	"GBp": {"GBP", 0.01},
+	"GBX": {"GBP", 0.01},
+	"ZAc": {"ZAR", 0.01},
+	"ILA": {"ILS", 0.01},
+}
+
+// FXService fetches and caches currency-pair rates through the stock data provider
+type FXService struct {
+	stock *StockService
+	cache *MemoryCache
+}
+
+// NewFXService creates a new FXService
+func NewFXService(stock *StockService) *FXService {
+	return &FXService{
+		stock: stock,
+		cache: NewMemoryCache(10 * time.Minute), // FX moves slowly relative to quotes
+	}
+}
+
+// NormalizeCurrency returns the ISO code for a currency and the factor that converts
+// amounts quoted in it to that ISO currency (1 unless it is a minor unit such as GBp)
+func NormalizeCurrency(currency string) (string, float64) {
+	currency = strings.TrimSpace(currency)
+	if m, ok := minorUnits[currency]; ok {
+		return m.major, m.factor
+	}
+	return strings.ToUpper(currency), 1
+}
+
+// GetRate returns the latest rate to convert one unit of from into to
+func (s *FXService) GetRate(ctx context.Context, from, to string) (*models.FXRate, error) {
+	return s.getRate(ctx, from, to, time.Time{})
+}
+
+// GetRateOn returns the closing rate on date, or the most recent close before it
+func (s *FXService) GetRateOn(ctx context.Context, from, to string, date time.Time) (*models.FXRate, error) {
+	if date.IsZero() {
+		return nil, fmt.Errorf("date is required")
+	}
+	return s.getRate(ctx, from, to, date)
+}
+
+// Convert converts amount from one currency to another at the latest rate
+func (s *FXService) Convert(ctx context.Context, amount float64, from, to string) (float64, *models.FXRate, error) {
+	rate, err := s.GetRate(ctx, from, to)
+	if err != nil {
+		return 0, nil, err
+	}
+	return amount * rate.Rate, rate, nil
+}
+
+// ConvertOn converts amount from one currency to another at the rate on date
+func (s *FXService) ConvertOn(ctx context.Context, amount float64, from, to string, date time.Time) (float64, *models.FXRate, error) {
+	rate, err := s.GetRateOn(ctx, from, to, date)
+	if err != nil {
+		return 0, nil, err
+	}
+	return amount * rate.Rate, rate, nil
+}
+
+// RateSeries returns daily closing rates to convert one unit of from into to over
+// a Yahoo history range, for valuing holdings on past dates
+func (s *FXService) RateSeries(ctx context.Context, from, to, range_ string) (analytics.Series, error) {
+	base, baseFactor := NormalizeCurrency(from)
+	quote, quoteFactor := NormalizeCurrency(to)
+	if len(base) != 3 || len(quote) != 3 {
+		return analytics.Series{}, ErrInvalidCurrency
+	}
+	if base == quote {
+		return analytics.Series{}, fmt.Errorf("fx series %s/%s: same currency", base, quote)
+	}
+	scale := baseFactor / quoteFactor
+
+	invert := false
+	history, err := s.stock.GetHistory(ctx, base+quote+"=X", range_, "1d")
+	if err != nil {
+		var invErr error
+		if history, invErr = s.stock.GetHistory(ctx, quote+base+"=X", range_, "1d"); invErr != nil {
+			return analytics.Series{}, fmt.Errorf("fx series %s/%s: %w", base, quote, err)
+		}
+		invert = true
+	}
+	series := analytics.FromHistory(history)
+	for i, v := range series.Values {
+		if invert {
+			v = 1 / v
+		}
+		series.Values[i] = v * scale
+	}
+	return series, nil
+}
+
+func (s *FXService) getRate(ctx context.Context, from, to string, date time.Time) (*models.FXRate, error) {
+	base, baseFactor := NormalizeCurrency(from)
+	quote, quoteFactor := NormalizeCurrency(to)
+	if len(base) != 3 || len(quote) != 3 {
+		return nil, ErrInvalidCurrency
+	}
+	// Minor units scale the major-currency rate, e.g. GBp->USD is 0.01 * GBPUSD
+	scale := baseFactor / quoteFactor
+
+	day := ""
+	if !date.IsZero() {
+		day = date.Format("2006-01-02")
+	}
+	if base == quote {
+		return &models.FXRate{Base: from, Quote: to, Rate: scale, Date: day}, nil
+	}
+
+	cacheKey := fmt.Sprintf("fx:%s%s:%s", base, quote, day)
+	if v, ok := s.cache.Get(cacheKey); ok {
+		r := v.(float64)
+		return &models.FXRate{Base: from, Quote: to, Rate: r * scale, Date: day}, nil
+	}
+
+	r, err := s.fetchPair(ctx, base, quote, day)
+	if err != nil {
+		// Not every pair is listed; fall back to the inverse pair
+		inv, invErr := s.fetchPair(ctx, quote, base, day)
+		if invErr != nil || inv == 0 {
+			return nil, fmt.Errorf("fx rate %s/%s: %w", base, quote, err)
+		}
+		r = 1 / inv
+	}
+	s.cache.Set(cacheKey, r)
+	return &models.FXRate{Base: from, Quote: to, Rate: r * scale, Date: day}, nil
+}
+
+// fetchPair looks up a Yahoo FX pair symbol such as USDINR=X
+func (s *FXService) fetchPair(ctx context.Context, base, quote, day string) (float64, error) {
+	symbol := base + quote + "=X"
+	if day == "" {
+		q, err := s.stock.GetQuote(ctx, symbol)
+		if err != nil {
+			return 0, err
+		}
+		if q.Price <= 0 {
+			return 0, fmt.Errorf("no price for %s", symbol)
+		}
+		return q.Price, nil
+	}
+
+	history, err := s.stock.GetHistory(ctx, symbol, historyRangeFor(day), "1d")
+	if err != nil {
+		return 0, err
+	}
+	// History is ascending by date; take the last close on or before the requested day
+	rate := 0.0
+	for _, p := range history {
+		if p.Date > day {
+			break
+		}
+		if p.Close > 0 {
+			rate = p.Close
+		}
+	}
+	if rate == 0 {
+		return 0, fmt.Errorf("no %s rate on or before %s", symbol, day)
+	}
+	return rate, nil
+}
+
+// historyRangeFor picks the smallest Yahoo range that still covers day
+func historyRangeFor(day string) string {
+	t, err := time.Parse("2006-01-02", day)
+	if err != nil {
+		return "max"
+	}
+	age := time.Since(t)
+	switch {
+	case age <= 25*24*time.Hour:
+		return "1mo"
+	case age <= 360*24*time.Hour:
+		return "1y"
+	case age <= 5*360*24*time.Hour:
+		return "5y"
+	default:
+		return "max"
+	}
+}
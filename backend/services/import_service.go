@@ -0,0 +1,201 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"tinystock/backend/importer"
+	"tinystock/backend/models"
+)
+
+var (
+	ErrInvalidImport  = errors.New("invalid import")
+	ErrImportRejected = errors.New("import rejected")
+)
+
+// ImportService loads broker statements into a portfolio's ledger
+type ImportService struct {
+	portfolio *PortfolioService
+}
+
+// NewImportService creates a new ImportService
+func NewImportService(portfolio *PortfolioService) *ImportService {
+	return &ImportService{portfolio: portfolio}
+}
+
+// ImportOptions selects the statement layout. Mapping overrides the profile's
+// columns (field -> header); DryRun previews without writing.
+type ImportOptions struct {
+	Profile string
+	Mapping map[string]string
+	DryRun  bool
+}
+
+// Profiles describes the built-in statement layouts
+func (s *ImportService) Profiles() []models.ImportProfile {
+	var list []models.ImportProfile
+	for _, p := range importer.Profiles() {
+		list = append(list, models.ImportProfile{Name: p.Name, Description: p.Description, Columns: p.Columns, NewestFirst: p.NewestFirst})
+	}
+	return list
+}
+
+// importKey identifies a transaction for duplicate detection
+func importKey(t models.Transaction) string {
+	return fmt.Sprintf("%s|%s|%s|%s|%s|%s", t.TradeDate, t.Type, t.Symbol,
+		t.Quantity.StringFixed(6), t.Price.StringFixed(6), t.Amount.StringFixed(6))
+}
+
+// Import parses a statement (a JSON export when no profile is named and the body
+// is a JSON object) and checks every row as RecordTransaction would. Rows
+// matching a transaction already in the ledger (same date, type, symbol, quantity,
+// price and amount) are marked duplicate and left out, so importing a file twice
+// is harmless. Unless DryRun is set, the remaining rows are stored in one
+// repository transaction, and only if no row has an error.
+func (s *ImportService) Import(ctx context.Context, userID string, portfolioID int64, r io.Reader, opts ImportOptions) (*models.ImportResult, error) {
+	p, err := s.portfolio.portfolio(ctx, userID, portfolioID)
+	if err != nil {
+		return nil, err
+	}
+	br := bufio.NewReader(r)
+	if opts.Profile == "" && importer.IsJSON(br) {
+		opts.Profile = importer.JSON
+	}
+	profile, ok := importer.Lookup(opts.Profile)
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown profile %q", ErrInvalidImport, opts.Profile)
+	}
+	if len(opts.Mapping) > 0 {
+		if profile, err = profile.WithMapping(opts.Mapping); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+		}
+	}
+	result, err := importer.Parse(br, profile)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+	}
+	result.PortfolioID = p.ID
+	result.DryRun = opts.DryRun
+
+	existing, err := s.portfolio.txs.ListTransactions(ctx, userID, p.ID, "")
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]int)
+	for _, t := range existing {
+		seen[importKey(t)]++
+	}
+	var fresh []models.Transaction
+	symbols := make(map[string]bool)
+	rows := result.Rows[:0]
+	for _, row := range result.Rows {
+		tx := row.Transaction
+		tx.UserID = userID
+		// A fee schedule deleted since the export leaves its commission behind
+		if tx.FeeScheduleID != 0 {
+			schedule, err := s.portfolio.fees.GetFeeSchedule(ctx, userID, tx.FeeScheduleID)
+			if err != nil {
+				return nil, err
+			}
+			if schedule == nil {
+				tx.FeeScheduleID = 0
+			}
+		}
+		if err := s.portfolio.prepareTransaction(ctx, p, &tx); err != nil {
+			result.Errors = append(result.Errors, models.ImportError{Row: row.Row, Message: err.Error()})
+			continue
+		}
+		row.Transaction = tx
+		if key := importKey(tx); seen[key] > 0 {
+			seen[key]--
+			row.Duplicate = true
+			result.Duplicates++
+		} else {
+			fresh = append(fresh, tx)
+			symbols[tx.Symbol] = true
+		}
+		rows = append(rows, row)
+	}
+	result.Rows = rows
+
+	// New rows are checked and stored oldest first, so a day's sell follows the buy
+	// it closes whichever way the statement lists them
+	ordered, from := chronological(fresh, profile.NewestFirst)
+
+	// The ledger must replay with the new rows in place
+	all := append(existing, ordered...)
+	order := make([]string, 0, len(symbols))
+	for sym := range symbols {
+		order = append(order, sym)
+	}
+	sort.Strings(order)
+	reported := make(map[string]bool)
+	for _, sym := range order {
+		if err := checkLedger(p, all, sym); err != nil && !reported[err.Error()] {
+			reported[err.Error()] = true
+			result.Errors = append(result.Errors, models.ImportError{Message: err.Error()})
+		}
+	}
+	sort.SliceStable(result.Errors, func(i, j int) bool { return result.Errors[i].Row < result.Errors[j].Row })
+
+	if opts.DryRun || len(fresh) == 0 && len(result.Errors) == 0 {
+		return result, nil
+	}
+	if len(result.Errors) > 0 {
+		first := result.Errors[0].Message
+		if row := result.Errors[0].Row; row > 0 {
+			first = fmt.Sprintf("row %d: %s", row, first)
+		}
+		return result, fmt.Errorf("%w: %d problem(s), nothing was imported; first: %s", ErrImportRejected, len(result.Errors), first)
+	}
+	if err := s.portfolio.txs.AddTransactions(ctx, ordered); err != nil {
+		return nil, err
+	}
+	for k, t := range ordered {
+		fresh[from[k]] = t
+	}
+	earliest := fresh[0].TradeDate
+	next := 0
+	for i := range result.Rows {
+		if result.Rows[i].Duplicate {
+			continue
+		}
+		result.Rows[i].Transaction = fresh[next]
+		if fresh[next].TradeDate < earliest {
+			earliest = fresh[next].TradeDate
+		}
+		next++
+	}
+	result.Imported = len(fresh)
+	// Snapshots from the earliest imported trade on no longer match the ledger
+	return result, s.portfolio.snapshots.DeleteSnapshots(ctx, p.ID, earliest)
+}
+
+// chronological returns txs sorted by trade date, and for each the index it came
+// from. Rows on one date keep their statement order, reversed for statements that
+// list the newest activity first.
+func chronological(txs []models.Transaction, newestFirst bool) ([]models.Transaction, []int) {
+	from := make([]int, len(txs))
+	for i := range from {
+		from[i] = i
+	}
+	sort.SliceStable(from, func(a, b int) bool {
+		i, j := from[a], from[b]
+		if txs[i].TradeDate != txs[j].TradeDate {
+			return txs[i].TradeDate < txs[j].TradeDate
+		}
+		if newestFirst {
+			return i > j
+		}
+		return i < j
+	})
+	ordered := make([]models.Transaction, len(txs))
+	for k, i := range from {
+		ordered[k] = txs[i]
+	}
+	return ordered, from
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"tinystock/backend/models"
+)
+
+var ErrIndexNotFound = errors.New("index not found")
+
+//go:embed data/indices.json
+var indicesJSON []byte
+
+// indexFile is the layout of the bundled constituents file
+type indexFile struct {
+	AsOf    string `json:"asOf"`
+	Indices []struct {
+		models.Index
+		Constituents []models.Constituent `json:"constituents"`
+	} `json:"indices"`
+}
+
+// IndexService serves benchmark quotes and constituent lists
+type IndexService struct {
+	stock        *StockService
+	asOf         string
+	indices      []models.Index
+	constituents map[string][]models.Constituent
+	sectors      map[string]string
+}
+
+// NewIndexService creates an IndexService from the bundled constituents file
+func NewIndexService(stock *StockService) (*IndexService, error) {
+	var f indexFile
+	if err := json.Unmarshal(indicesJSON, &f); err != nil {
+		return nil, fmt.Errorf("parse indices: %w", err)
+	}
+	s := &IndexService{
+		stock:        stock,
+		asOf:         f.AsOf,
+		constituents: make(map[string][]models.Constituent),
+		sectors:      make(map[string]string),
+	}
+	for _, ix := range f.Indices {
+		idx := ix.Index
+		idx.ConstituentCount = len(ix.Constituents)
+		s.indices = append(s.indices, idx)
+		s.constituents[idx.ID] = ix.Constituents
+		for _, c := range ix.Constituents {
+			s.sectors[c.Symbol] = c.Sector
+		}
+	}
+	return s, nil
+}
+
+// ListIndices returns all benchmarks with their current quotes
+func (s *IndexService) ListIndices(ctx context.Context) ([]models.Index, error) {
+	symbols := make([]string, len(s.indices))
+	for i, idx := range s.indices {
+		symbols[i] = idx.Symbol
+	}
+	quotes, err := s.stock.GetQuotes(ctx, symbols)
+	if err != nil {
+		return nil, err
+	}
+	quoteMap := make(map[string]*models.Quote, len(quotes))
+	for _, q := range quotes {
+		quoteMap[q.Symbol] = q
+	}
+
+	result := make([]models.Index, len(s.indices))
+	for i, idx := range s.indices {
+		idx.Quote = quoteMap[idx.Symbol]
+		result[i] = idx
+	}
+	return result, nil
+}
+
+// GetIndex returns the benchmark definition for id (e.g. "sp500")
+func (s *IndexService) GetIndex(id string) (models.Index, error) {
+	id = strings.ToLower(strings.TrimSpace(id))
+	for _, idx := range s.indices {
+		if idx.ID == id {
+			return idx, nil
+		}
+	}
+	return models.Index{}, ErrIndexNotFound
+}
+
+// Symbols returns the constituent symbols of an index
+func (s *IndexService) Symbols(id string) ([]string, error) {
+	idx, err := s.GetIndex(id)
+	if err != nil {
+		return nil, err
+	}
+	members := s.constituents[idx.ID]
+	symbols := make([]string, len(members))
+	for i, c := range members {
+		symbols[i] = c.Symbol
+	}
+	return symbols, nil
+}
+
+// GetConstituents returns an index's members, optionally with current quotes
+func (s *IndexService) GetConstituents(ctx context.Context, id string, withQuotes bool) (*models.IndexConstituents, error) {
+	idx, err := s.GetIndex(id)
+	if err != nil {
+		return nil, err
+	}
+	members := make([]models.Constituent, len(s.constituents[idx.ID]))
+	copy(members, s.constituents[idx.ID])
+
+	if withQuotes {
+		symbols := make([]string, len(members))
+		for i, c := range members {
+			symbols[i] = c.Symbol
+		}
+		quotes, err := s.stock.GetQuotesBatched(ctx, symbols, quoteBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		quoteMap := make(map[string]*models.Quote, len(quotes))
+		for _, q := range quotes {
+			quoteMap[q.Symbol] = q
+		}
+		for i := range members {
+			members[i].Quote = quoteMap[members[i].Symbol]
+		}
+	}
+
+	return &models.IndexConstituents{Index: idx, AsOf: s.asOf, Constituents: members}, nil
+}
+
+// Sector returns a symbol's sector from the bundled constituents, if it belongs to any index
+func (s *IndexService) Sector(symbol string) (string, bool) {
+	sector, ok := s.sectors[strings.ToUpper(symbol)]
+	return sector, ok
+}
+
+// NormalizeSector matches name case-insensitively against the bundled sectors and
+// returns its canonical spelling
+func (s *IndexService) NormalizeSector(name string) (string, bool) {
+	name = strings.TrimSpace(name)
+	for _, sector := range s.sectors {
+		if sector != "" && strings.EqualFold(sector, name) {
+			return sector, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"context"
+
+	"tinystock/backend/indicators"
+)
+
+// IndicatorService computes technical indicators over StockService history
+type IndicatorService struct {
+	stock *StockService
+}
+
+// NewIndicatorService creates a new IndicatorService
+func NewIndicatorService(stock *StockService) *IndicatorService {
+	return &IndicatorService{stock: stock}
+}
+
+// Compute returns each requested indicator's series for symbol, keyed by canonical spec (e.g. "rsi:14")
+func (s *IndicatorService) Compute(ctx context.Context, symbol string, specs []indicators.Spec, range_, interval string) (map[string][]indicators.Point, error) {
+	history, err := s.stock.GetHistory(ctx, symbol, range_, interval)
+	if err != nil {
+		return nil, err
+	}
+	return indicators.Compute(indicators.FromHistory(history), specs)
+}
+
+// Latest returns the most recent value of each requested indicator; indicators still warming up are omitted
+func (s *IndicatorService) Latest(ctx context.Context, symbol string, specs []indicators.Spec, range_, interval string) (map[string]indicators.Point, error) {
+	series, err := s.Compute(ctx, symbol, specs, range_, interval)
+	if err != nil {
+		return nil, err
+	}
+	latest := make(map[string]indicators.Point, len(series))
+	for name, points := range series {
+		if len(points) > 0 {
+			latest[name] = points[len(points)-1]
+		}
+	}
+	return latest, nil
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"regexp"
+	"strings"
+	"time"
+	_ "time/tzdata" // exchange time zones must resolve in minimal containers
+
+	"tinystock/backend/models"
+)
+
+// tradingSession describes regular weekday trading hours in an exchange's local time
+type tradingSession struct {
+	location   *time.Location
+	open, stop int // minutes after local midnight
+}
+
+var (
+	sessionUS    = newTradingSession("America/New_York", 9*60+30, 16*60)
+	sessionIndia = newTradingSession("Asia/Kolkata", 9*60+15, 15*60+30)
+	sessionLSE   = newTradingSession("Europe/London", 8*60, 16*60+30)
+
+	// sessionsBySuffix maps Yahoo exchange suffixes to their session; no suffix means a US listing
+	sessionsBySuffix = map[string]tradingSession{
+		".NS": sessionIndia,
+		".BO": sessionIndia,
+		".L":  sessionLSE,
+	}
+
+	// cryptoSymbolPattern matches Yahoo crypto pairs such as BTC-USD or ETH-INR
+	cryptoSymbolPattern = regexp.MustCompile(`^[A-Z0-9]{2,10}-(USD|USDT|USDC|EUR|GBP|INR|JPY|BTC|ETH)$`)
+)
+
+func newTradingSession(tz string, open, stop int) tradingSession {
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		loc = time.UTC
+	}
+	return tradingSession{location: loc, open: open, stop: stop}
+}
+
+// IsCrypto reports whether a quote is a crypto instrument, using the provider's
+// asset type when known and the symbol shape otherwise
+func IsCrypto(symbol, assetType string) bool {
+	if assetType != "" {
+		return strings.EqualFold(assetType, models.AssetCrypto)
+	}
+	return cryptoSymbolPattern.MatchString(strings.ToUpper(symbol))
+}
+
+func isFX(symbol, assetType string) bool {
+	return strings.EqualFold(assetType, models.AssetCurrency) || strings.HasSuffix(symbol, "=X")
+}
+
+// IsMarketOpen reports whether symbol is in its regular trading session at t.
+// Crypto never closes and FX trades around the clock on weekdays; exchange holidays are not modelled.
+func IsMarketOpen(symbol, assetType string, t time.Time) bool {
+	if IsCrypto(symbol, assetType) {
+		return true
+	}
+	if isFX(symbol, assetType) {
+		wd := t.UTC().Weekday()
+		return wd != time.Saturday && wd != time.Sunday
+	}
+	session := sessionUS
+	if i := strings.LastIndex(symbol, "."); i > 0 {
+		if s, ok := sessionsBySuffix[strings.ToUpper(symbol[i:])]; ok {
+			session = s
+		}
+	}
+	local := t.In(session.location)
+	if local.Weekday() == time.Saturday || local.Weekday() == time.Sunday {
+		return false
+	}
+	minute := local.Hour()*60 + local.Minute()
+	return minute >= session.open && minute < session.stop
+}
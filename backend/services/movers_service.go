@@ -0,0 +1,128 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"tinystock/backend/models"
+)
+
+// Movers ranking metrics
+const (
+	MoversMetricChange = "changePct"
+	MoversMetricVolume = "volume"
+)
+
+const (
+	defaultMoversLimit  = 10
+	maxMoversLimit      = 50
+	moversSnapshotTTL   = time.Minute
+	moversSnapshotCache = "movers:"
+)
+
+var ErrInvalidMetric = errors.New("invalid metric")
+
+// moversSnapshot is the set of quotes a universe was ranked from
+type moversSnapshot struct {
+	quotes []*models.Quote
+	asOf   time.Time
+}
+
+// MoversService ranks top gainers, losers and most active symbols
+type MoversService struct {
+	universe *UniverseService
+	stock    *StockService
+	cache    *MemoryCache
+}
+
+// NewMoversService creates a new MoversService
+func NewMoversService(universe *UniverseService, stock *StockService) *MoversService {
+	return &MoversService{
+		universe: universe,
+		stock:    stock,
+		cache:    NewMemoryCache(moversSnapshotTTL),
+	}
+}
+
+// GetMovers ranks universe ("watched" or an index id such as "sp500") by metric
+func (s *MoversService) GetMovers(ctx context.Context, universe, metric string, limit int) (*models.MarketMovers, error) {
+	if universe == "" {
+		universe = UniverseWatched
+	}
+	universe = strings.ToLower(universe)
+	if metric == "" {
+		metric = MoversMetricChange
+	}
+	if metric != MoversMetricChange && metric != MoversMetricVolume {
+		return nil, ErrInvalidMetric
+	}
+	if limit <= 0 {
+		limit = defaultMoversLimit
+	}
+	if limit > maxMoversLimit {
+		limit = maxMoversLimit
+	}
+
+	snap, err := s.snapshot(ctx, universe)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.MarketMovers{
+		Universe:     universe,
+		Metric:       metric,
+		UniverseSize: len(snap.quotes),
+		AsOf:         snap.asOf,
+	}
+	ranked := make([]*models.Quote, len(snap.quotes))
+	copy(ranked, snap.quotes)
+
+	switch metric {
+	case MoversMetricVolume:
+		sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].Volume > ranked[j].Volume })
+		result.MostActive = head(ranked, limit, func(q *models.Quote) bool { return q.Volume > 0 })
+	default:
+		sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].ChangePct > ranked[j].ChangePct })
+		result.Gainers = head(ranked, limit, func(q *models.Quote) bool { return q.ChangePct > 0 })
+		for i, j := 0, len(ranked)-1; i < j; i, j = i+1, j-1 {
+			ranked[i], ranked[j] = ranked[j], ranked[i]
+		}
+		result.Losers = head(ranked, limit, func(q *models.Quote) bool { return q.ChangePct < 0 })
+	}
+	return result, nil
+}
+
+// snapshot returns quotes for every symbol in the universe, reusing a recent snapshot
+func (s *MoversService) snapshot(ctx context.Context, universe string) (*moversSnapshot, error) {
+	if v, ok := s.cache.Get(moversSnapshotCache + universe); ok {
+		return v.(*moversSnapshot), nil
+	}
+	symbols, err := s.universe.Symbols(ctx, universe)
+	if err != nil {
+		return nil, err
+	}
+	quotes, err := s.stock.GetQuotesBatched(ctx, symbols, quoteBatchSize)
+	if err != nil {
+		return nil, err
+	}
+	snap := &moversSnapshot{quotes: quotes, asOf: time.Now().UTC()}
+	s.cache.Set(moversSnapshotCache+universe, snap)
+	return snap, nil
+}
+
+// head returns up to n leading quotes that satisfy keep
+func head(quotes []*models.Quote, n int, keep func(*models.Quote) bool) []*models.Quote {
+	out := make([]*models.Quote, 0, n)
+	for _, q := range quotes {
+		if len(out) == n {
+			break
+		}
+		if keep(q) {
+			out = append(out, q)
+		}
+	}
+	return out
+}
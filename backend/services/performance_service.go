@@ -0,0 +1,314 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"tinystock/backend/analytics"
+	"tinystock/backend/ledger"
+	"tinystock/backend/models"
+)
+
+var (
+	ErrInvalidPeriod    = errors.New("invalid period")
+	ErrInvalidBenchmark = errors.New("invalid benchmark")
+)
+
+// PerformanceService measures portfolio returns over time by valuing the
+// transaction ledger against historical prices
+type PerformanceService struct {
+	portfolio    *PortfolioService
+	snapshots    *SnapshotService
+	stock        *StockService
+	fx           *FXService
+	riskFreeRate float64
+}
+
+// NewPerformanceService creates a new PerformanceService. riskFreeRate is the
+// default annual rate (e.g. 0.04) for risk-adjusted ratios.
+func NewPerformanceService(portfolio *PortfolioService, snapshots *SnapshotService, stock *StockService, fx *FXService, riskFreeRate float64) *PerformanceService {
+	return &PerformanceService{portfolio: portfolio, snapshots: snapshots, stock: stock, fx: fx, riskFreeRate: riskFreeRate}
+}
+
+// periodStart returns the first day of a named period ending on to; "all" (or an
+// empty period) has no start and runs from inception
+func periodStart(period string, to time.Time) (string, error) {
+	var start time.Time
+	switch strings.ToLower(period) {
+	case "", "all", "max", "inception":
+		return "", nil
+	case "1m":
+		start = to.AddDate(0, -1, 0)
+	case "3m":
+		start = to.AddDate(0, -3, 0)
+	case "6m":
+		start = to.AddDate(0, -6, 0)
+	case "ytd":
+		start = time.Date(to.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	case "1y":
+		start = to.AddDate(-1, 0, 0)
+	case "3y":
+		start = to.AddDate(-3, 0, 0)
+	case "5y":
+		start = to.AddDate(-5, 0, 0)
+	default:
+		return "", fmt.Errorf("%w: %q (1m, 3m, 6m, ytd, 1y, 3y, 5y or all)", ErrInvalidPeriod, period)
+	}
+	return start.Format("2006-01-02"), nil
+}
+
+// window resolves a named period, or "custom" with an explicit from, into a date
+// range (YYYY-MM-DD); to defaults to today and cannot be in the future. An empty
+// from means since inception.
+func window(period, from, to string) (string, string, error) {
+	today := time.Now().UTC().Format("2006-01-02")
+	if to == "" || to > today {
+		to = today
+	}
+	end, err := time.Parse("2006-01-02", to)
+	if err != nil {
+		return "", "", fmt.Errorf("%w: to must be YYYY-MM-DD", ErrInvalidPeriod)
+	}
+	if period != "custom" {
+		from, err = periodStart(period, end)
+		return from, to, err
+	}
+	if _, err := time.Parse("2006-01-02", from); err != nil {
+		return "", "", fmt.Errorf("%w: from must be YYYY-MM-DD", ErrInvalidPeriod)
+	}
+	if from > to {
+		return "", "", fmt.Errorf("%w: from is after to", ErrInvalidPeriod)
+	}
+	return from, to, nil
+}
+
+// GetPerformance returns time- and money-weighted returns for a portfolio (0 for
+// the default) over period or from/to, in baseCurrency (empty for the portfolio's)
+func (s *PerformanceService) GetPerformance(ctx context.Context, userID string, portfolioID int64, baseCurrency, period, from, to string) (*models.Performance, error) {
+	p, err := s.portfolio.portfolio(ctx, userID, portfolioID)
+	if err != nil {
+		return nil, err
+	}
+	baseCurrency, method, err := s.portfolio.resolve(p, baseCurrency, "")
+	if err != nil {
+		return nil, err
+	}
+	period = strings.ToLower(period)
+	switch {
+	case from != "":
+		period = "custom"
+	case period == "":
+		period = "all"
+	}
+	from, to, err = window(period, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	txs, err := s.portfolio.txs.ListTransactions(ctx, userID, p.ID, "")
+	if err != nil {
+		return nil, err
+	}
+	ledger.Sort(txs)
+
+	result := &models.Performance{PortfolioID: p.ID, BaseCurrency: baseCurrency, Period: period, From: from, To: to}
+	if len(txs) == 0 || txs[0].TradeDate > to {
+		return result, nil
+	}
+	if inception := txs[0].TradeDate; from < inception {
+		result.From = inception
+	}
+
+	v, err := s.portfolio.value(ctx, txs, baseCurrency, ledger.Options{Method: method, WashSales: p.WashSales}, result.From, to)
+	if err != nil {
+		return nil, err
+	}
+	last := v.Len() - 1
+	result.StartValue = v.Values[0]
+	result.EndValue = v.Values[last]
+	for _, f := range v.Flows {
+		result.NetFlows += f
+	}
+	result.Gain = result.EndValue - result.StartValue - result.NetFlows
+
+	twr := analytics.TWR(v.Values, v.Flows)
+	result.TWR = twr * 100
+	days := daysBetween(v.Dates[0], v.Dates[last])
+	if days >= 365 {
+		annualized := analytics.Annualize(twr, days) * 100
+		result.TWRAnnualized = &annualized
+	}
+
+	flows := make([]analytics.CashFlow, 0, v.Len()+1)
+	if v.Values[0] > 0 {
+		flows = append(flows, analytics.CashFlow{Date: v.Dates[0], Amount: -v.Values[0]})
+	}
+	for i := 1; i <= last; i++ {
+		if v.Flows[i] != 0 {
+			flows = append(flows, analytics.CashFlow{Date: v.Dates[i], Amount: -v.Flows[i]})
+		}
+	}
+	flows = append(flows, analytics.CashFlow{Date: v.Dates[last], Amount: result.EndValue})
+	if mwr, ok := analytics.XIRR(flows); ok {
+		if span := daysBetween(flows[0].Date, v.Dates[last]); span < 365 {
+			// Like TWR, short periods report the return over the period itself
+			mwr = math.Pow(1+mwr, span/365.25) - 1
+		}
+		mwr *= 100
+		result.MWR = &mwr
+	}
+	return result, nil
+}
+
+// equityIndex chains a snapshot series' flow-adjusted daily returns into a growth
+// index starting at 1, so it can be compared with a price series
+func equityIndex(snapshots []models.PortfolioSnapshot) analytics.Series {
+	values := make([]float64, len(snapshots))
+	flows := make([]float64, len(snapshots))
+	index := analytics.Series{Dates: make([]string, len(snapshots)), Values: make([]float64, len(snapshots))}
+	for i, snap := range snapshots {
+		values[i], flows[i] = snap.TotalValue, snap.NetFlow
+		index.Dates[i] = snap.Date
+	}
+	growth := 1.0
+	for i, r := range append([]float64{0}, analytics.FlowReturns(values, flows)...) {
+		growth *= 1 + r
+		index.Values[i] = growth
+	}
+	return index
+}
+
+// Benchmark compares a portfolio's equity curve over range_ (see SnapshotService.History)
+// with a benchmark symbol's daily closes
+func (s *PerformanceService) Benchmark(ctx context.Context, userID string, portfolioID int64, symbol, range_ string) (*models.BenchmarkStats, error) {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	if symbol == "" {
+		return nil, fmt.Errorf("%w: symbol is required", ErrInvalidBenchmark)
+	}
+	history, err := s.snapshots.History(ctx, userID, portfolioID, range_)
+	if err != nil {
+		return nil, err
+	}
+	from, _ := periodStart(history.Range, time.Now().UTC())
+	yahooRange := "max"
+	if from != "" {
+		yahooRange = historyRangeFor(from)
+	}
+	points, err := s.stock.GetHistory(ctx, symbol, yahooRange, "1d")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidBenchmark, symbol, err)
+	}
+
+	portfolio, benchmark, dates := analytics.Intersect(equityIndex(history.Snapshots), analytics.FromHistory(points))
+	result := &models.BenchmarkStats{Symbol: symbol, Range: history.Range}
+	if len(dates) < 2 {
+		return result, nil
+	}
+	last := len(dates) - 1
+	result.From, result.To = dates[0], dates[last]
+	result.PortfolioReturn = (portfolio[last]/portfolio[0] - 1) * 100
+	result.BenchmarkReturn = (benchmark[last]/benchmark[0] - 1) * 100
+
+	rp, rb := analytics.Returns(portfolio), analytics.Returns(benchmark)
+	result.Observations = len(rp)
+	perYear := analytics.PeriodsPerYear(dates)
+	ptr := func(v float64) *float64 { return &v }
+
+	if beta, ok := analytics.Beta(rp, rb); ok {
+		result.Beta = ptr(beta)
+		result.Alpha = ptr((analytics.Mean(rp) - beta*analytics.Mean(rb)) * perYear * 100)
+	}
+	if corr, ok := analytics.Correlation(rp, rb); ok {
+		result.Correlation = ptr(corr)
+	}
+	te := analytics.TrackingError(rp, rb, perYear)
+	result.TrackingError = te * 100
+	if te > 0 {
+		result.InformationRatio = ptr(analytics.Mean(analytics.Active(rp, rb)) * perYear / te)
+	}
+	if up, ok := analytics.Capture(rp, rb, true); ok {
+		result.UpCapture = ptr(up * 100)
+	}
+	if down, ok := analytics.Capture(rp, rb, false); ok {
+		result.DownCapture = ptr(down * 100)
+	}
+	return result, nil
+}
+
+// varConfidences are the levels Risk reports Value-at-Risk at
+var varConfidences = []float64{0.95, 0.99}
+
+// Risk computes volatility, Sharpe and Sortino ratios, maximum drawdown and
+// one-day VaR from a portfolio's daily time-weighted returns over range_.
+// riskFreeRate is an annual rate; nil uses the configured default.
+func (s *PerformanceService) Risk(ctx context.Context, userID string, portfolioID int64, range_ string, riskFreeRate *float64) (*models.RiskReport, error) {
+	rf := s.riskFreeRate
+	if riskFreeRate != nil {
+		rf = *riskFreeRate
+	}
+	history, err := s.snapshots.History(ctx, userID, portfolioID, range_)
+	if err != nil {
+		return nil, err
+	}
+	result := &models.RiskReport{
+		PortfolioID:  history.PortfolioID,
+		BaseCurrency: history.BaseCurrency,
+		Range:        history.Range,
+		RiskFreeRate: rf * 100,
+		VaR:          []models.VaRResult{},
+	}
+	index := equityIndex(history.Snapshots)
+	if index.Len() < 2 {
+		return result, nil
+	}
+	last := index.Len() - 1
+	result.From, result.To = index.Dates[0], index.Dates[last]
+
+	returns := analytics.Returns(index.Values)
+	result.Observations = len(returns)
+	perYear := analytics.PeriodsPerYear(index.Dates)
+	ptr := func(v float64) *float64 { return &v }
+
+	result.Volatility = analytics.AnnualizedVolatility(returns, perYear) * 100
+	if sharpe, ok := analytics.Sharpe(returns, rf, perYear); ok {
+		result.Sharpe = ptr(sharpe)
+	}
+	if sortino, ok := analytics.Sortino(returns, rf, perYear); ok {
+		result.Sortino = ptr(sortino)
+	}
+
+	if dd := analytics.MaxDrawdown(index.Values); dd.Depth > 0 {
+		result.MaxDrawdown = models.Drawdown{Depth: dd.Depth * 100, Peak: index.Dates[dd.Peak], Trough: index.Dates[dd.Trough]}
+		if dd.Recovery >= 0 {
+			result.MaxDrawdown.Recovery = index.Dates[dd.Recovery]
+		}
+	}
+
+	value := history.Snapshots[len(history.Snapshots)-1].TotalValue
+	for _, c := range varConfidences {
+		historical := analytics.HistoricalVaR(returns, c)
+		parametric := analytics.ParametricVaR(returns, c)
+		result.VaR = append(result.VaR, models.VaRResult{
+			Confidence:       c * 100,
+			Historical:       historical * 100,
+			Parametric:       parametric * 100,
+			HistoricalAmount: historical * value,
+			ParametricAmount: parametric * value,
+		})
+	}
+	return result, nil
+}
+
+// daysBetween returns the calendar days from a to b (YYYY-MM-DD)
+func daysBetween(a, b string) float64 {
+	from, err1 := time.Parse("2006-01-02", a)
+	to, err2 := time.Parse("2006-01-02", b)
+	if err1 != nil || err2 != nil {
+		return 0
+	}
+	return to.Sub(from).Hours() / 24
+}
@@ -3,33 +3,236 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sort"
 	"strings"
+	"time"
 
+	"tinystock/backend/internal/decimal"
+	"tinystock/backend/ledger"
 	"tinystock/backend/models"
 	"tinystock/backend/repository"
 )
 
-var ErrInvalidSymbol = errors.New("invalid symbol")
+// DefaultPortfolioName is created for users who have no portfolio yet
+const DefaultPortfolioName = "Default"
+
+var (
+	ErrInvalidSymbol       = errors.New("invalid symbol")
+	ErrTransactionNotFound = errors.New("transaction not found")
+	ErrPositionNotFound    = errors.New("no transactions for symbol")
+	ErrPortfolioNotFound   = errors.New("portfolio not found")
+	ErrInvalidPortfolio    = errors.New("invalid portfolio")
+	ErrVersionConflict     = errors.New("transaction was changed since it was read")
+	ErrVersionRequired     = errors.New("send the version being edited in If-Match or the body")
+)
 
 // PortfolioService handles portfolio business logic and P&L calculations
 type PortfolioService struct {
-	repo  repository.PortfolioRepository
-	stock *StockService
+	portfolios      repository.PortfolioRepository
+	txs             repository.TransactionRepository
+	snapshots       repository.SnapshotRepository
+	fees            repository.FeeScheduleRepository
+	stock           *StockService
+	fx              *FXService
+	baseCurrency    string
+	costBasisMethod string
 }
 
-// NewPortfolioService creates a new PortfolioService
-func NewPortfolioService(repo repository.PortfolioRepository, stock *StockService) *PortfolioService {
-	return &PortfolioService{repo: repo, stock: stock}
+// NewPortfolioService creates a new PortfolioService. Portfolios without their own
+// settings report in baseCurrency and match lots with costBasisMethod.
+func NewPortfolioService(portfolios repository.PortfolioRepository, txs repository.TransactionRepository, snapshots repository.SnapshotRepository, fees repository.FeeScheduleRepository, stock *StockService, fx *FXService, baseCurrency, costBasisMethod string) *PortfolioService {
+	if baseCurrency == "" {
+		baseCurrency = DefaultBaseCurrency
+	}
+	if m, err := ledger.ParseMethod(costBasisMethod); err == nil {
+		costBasisMethod = m
+	} else {
+		costBasisMethod = ledger.MethodFIFO
+	}
+	return &PortfolioService{portfolios: portfolios, txs: txs, snapshots: snapshots, fees: fees, stock: stock, fx: fx, baseCurrency: baseCurrency, costBasisMethod: costBasisMethod}
 }
 
-// GetPortfolio returns full portfolio with real-time P&L for a user
-func (s *PortfolioService) GetPortfolio(ctx context.Context, userID string) (*models.PortfolioSummary, error) {
-	holdings, err := s.repo.ListHoldings(ctx, userID)
+// portfolio loads one of a user's portfolios; id 0 selects the default portfolio
+func (s *PortfolioService) portfolio(ctx context.Context, userID string, id int64) (*models.Portfolio, error) {
+	if id == 0 {
+		return s.defaultPortfolio(ctx, userID)
+	}
+	p, err := s.portfolios.GetPortfolio(ctx, userID, id)
 	if err != nil {
 		return nil, err
 	}
+	if p == nil {
+		return nil, ErrPortfolioNotFound
+	}
+	return p, nil
+}
+
+// defaultPortfolio returns the user's oldest portfolio, creating one if they have none
+func (s *PortfolioService) defaultPortfolio(ctx context.Context, userID string) (*models.Portfolio, error) {
+	list, err := s.portfolios.ListPortfolios(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) > 0 {
+		return &list[0], nil
+	}
+	p := &models.Portfolio{UserID: userID, Name: DefaultPortfolioName}
+	if err := s.portfolios.CreatePortfolio(ctx, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// ListPortfolios returns a user's portfolios, creating the default one on first use
+func (s *PortfolioService) ListPortfolios(ctx context.Context, userID string) ([]models.Portfolio, error) {
+	if _, err := s.defaultPortfolio(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.portfolios.ListPortfolios(ctx, userID)
+}
+
+// normalizePortfolio validates a portfolio's name and optional settings
+func normalizePortfolio(p *models.Portfolio) error {
+	p.Name = strings.TrimSpace(p.Name)
+	if p.Name == "" || len(p.Name) > 100 {
+		return fmt.Errorf("%w: name is required (max 100 characters)", ErrInvalidPortfolio)
+	}
+	if p.BaseCurrency != "" {
+		p.BaseCurrency, _ = NormalizeCurrency(p.BaseCurrency)
+		if len(p.BaseCurrency) != 3 {
+			return ErrInvalidCurrency
+		}
+	}
+	if p.CostBasisMethod != "" {
+		m, err := ledger.ParseMethod(p.CostBasisMethod)
+		if err != nil {
+			return err
+		}
+		p.CostBasisMethod = m
+	}
+	return nil
+}
+
+// CreatePortfolio adds a named portfolio for a user
+func (s *PortfolioService) CreatePortfolio(ctx context.Context, p *models.Portfolio) error {
+	if err := normalizePortfolio(p); err != nil {
+		return err
+	}
+	return s.portfolios.CreatePortfolio(ctx, p)
+}
+
+// UpdatePortfolio renames a portfolio or changes its settings
+func (s *PortfolioService) UpdatePortfolio(ctx context.Context, p *models.Portfolio) error {
+	current, err := s.portfolio(ctx, p.UserID, p.ID)
+	if err != nil {
+		return err
+	}
+	if err := normalizePortfolio(p); err != nil {
+		return err
+	}
+	if p.StrictCash {
+		txs, err := s.txs.ListTransactions(ctx, p.UserID, p.ID, "")
+		if err != nil {
+			return err
+		}
+		if err := ledger.CheckCash(txs); err != nil {
+			return err
+		}
+	}
+	if err := s.portfolios.UpdatePortfolio(ctx, p); err != nil {
+		return err
+	}
+	if p.BaseCurrency != current.BaseCurrency || p.CostBasisMethod != current.CostBasisMethod || p.WashSales != current.WashSales {
+		// Snapshots were valued under the old settings
+		if err := s.snapshots.DeleteSnapshots(ctx, p.ID, ""); err != nil {
+			return err
+		}
+	}
+	updated, err := s.portfolio(ctx, p.UserID, p.ID)
+	if err != nil {
+		return err
+	}
+	*p = *updated
+	return nil
+}
+
+// DeletePortfolio removes a portfolio and all of its transactions
+func (s *PortfolioService) DeletePortfolio(ctx context.Context, userID string, id int64) error {
+	if _, err := s.portfolio(ctx, userID, id); err != nil {
+		return err
+	}
+	return s.portfolios.DeletePortfolio(ctx, userID, id)
+}
+
+// resolve picks the base currency and cost basis method for a request: explicit
+// values first, then the portfolio's settings, then the service defaults
+func (s *PortfolioService) resolve(p *models.Portfolio, baseCurrency, method string) (string, string, error) {
+	if baseCurrency == "" {
+		baseCurrency = p.BaseCurrency
+	}
+	if baseCurrency == "" {
+		baseCurrency = s.baseCurrency
+	}
+	baseCurrency, _ = NormalizeCurrency(baseCurrency)
+	if len(baseCurrency) != 3 {
+		return "", "", ErrInvalidCurrency
+	}
+	if method == "" {
+		method = p.CostBasisMethod
+	}
+	if method == "" {
+		method = s.costBasisMethod
+	}
+	method, err := ledger.ParseMethod(method)
+	return baseCurrency, method, err
+}
+
+// book replays a portfolio's ledger with the given cost basis method, applying wash
+// sale rules when the portfolio has them enabled
+func (s *PortfolioService) book(ctx context.Context, p *models.Portfolio, method string) (*ledger.Book, error) {
+	txs, err := s.txs.ListTransactions(ctx, p.UserID, p.ID, "")
+	if err != nil {
+		return nil, err
+	}
+	return ledger.ReplayWith(txs, ledger.Options{Method: method, WashSales: p.WashSales})
+}
+
+// GetPortfolio returns a portfolio (0 for the default) with real-time P&L.
+// Totals are expressed in baseCurrency and lots are matched with method;
+// either falls back to the portfolio's settings when empty.
+func (s *PortfolioService) GetPortfolio(ctx context.Context, userID string, portfolioID int64, baseCurrency, method string) (*models.PortfolioSummary, error) {
+	p, err := s.portfolio(ctx, userID, portfolioID)
+	if err != nil {
+		return nil, err
+	}
+	baseCurrency, method, err = s.resolve(p, baseCurrency, method)
+	if err != nil {
+		return nil, err
+	}
+	book, err := s.book(ctx, p, method)
+	if err != nil {
+		return nil, err
+	}
+	summary := &models.PortfolioSummary{
+		PortfolioID:     p.ID,
+		PortfolioName:   p.Name,
+		Holdings:        []models.HoldingWithQuote{},
+		BaseCurrency:    baseCurrency,
+		CostBasisMethod: method,
+	}
+	// A missing exchange rate, like a missing quote, degrades the view rather than
+	// failing it: the amount is shown in its own currency and left out of the totals
+	gaps := fxGaps{}
+	summary.RealizedShortTerm, summary.RealizedLongTerm = s.realizedTotals(ctx, book.Realized, baseCurrency, gaps)
+	summary.RealizedPnL = summary.RealizedShortTerm.Add(summary.RealizedLongTerm)
+	summary.Cash, summary.CashValue = s.cashBalances(ctx, book.Cash, baseCurrency, gaps)
+	summary.TotalValue = summary.CashValue
+
+	holdings := book.Holdings()
 	if len(holdings) == 0 {
-		return &models.PortfolioSummary{Holdings: []models.HoldingWithQuote{}}, nil
+		summary.Unconverted = gaps.list()
+		return summary, nil
 	}
 
 	symbols := make([]string, len(holdings))
@@ -40,64 +243,457 @@ func (s *PortfolioService) GetPortfolio(ctx context.Context, userID string) (*mo
 	if err != nil {
 		quotes = nil
 	}
-	quoteMap := make(map[string]float64)
+	quoteMap := make(map[string]*models.Quote)
 	for _, q := range quotes {
-		quoteMap[q.Symbol] = q.Price
+		quoteMap[q.Symbol] = q
 	}
 
-	var totalValue, totalCost float64
-	withQuotes := make([]models.HoldingWithQuote, len(holdings))
-	for i, h := range holdings {
-		currentPrice := quoteMap[h.Symbol]
-		if currentPrice == 0 {
+	// Money is rounded to its currency per holding, and totals are sums of the
+	// rounded figures so they match the lines they summarize
+	today := time.Now().UTC().Format("2006-01-02")
+	var totalValue, totalCost decimal.Decimal
+	for _, h := range holdings {
+		currentPrice := decimal.Zero
+		if q, ok := quoteMap[h.Symbol]; ok {
+			currentPrice = decimal.NewFromFloat(q.Price)
+			if h.Currency == "" {
+				h.Currency = q.Currency
+			}
+		}
+		if currentPrice.IsZero() {
 			currentPrice = h.BuyPrice
 		}
-		marketValue := h.Quantity * currentPrice
-		costBasis := h.Quantity * h.BuyPrice
-		pnl := marketValue - costBasis
-		pnlPercent := 0.0
-		if costBasis > 0 {
-			pnlPercent = (pnl / costBasis) * 100
+		if h.Currency == "" {
+			h.Currency = baseCurrency
+		}
+		rate, converted := s.rate(ctx, h.Currency, baseCurrency, gaps)
+		fxRate := decimal.NewFromFloat(rate)
+		// Cost comes from the lots rather than quantity × average price, which is rounded
+		marketValue := h.Quantity.Mul(currentPrice).RoundCurrency(h.Currency)
+		lots := book.OpenLots(h.Symbol, today)
+		costBasis := decimal.Zero
+		for _, l := range lots {
+			costBasis = costBasis.Add(l.Quantity.Mul(l.CostPerShare))
 		}
-		withQuotes[i] = models.HoldingWithQuote{
-			Holding:      h,
-			CurrentPrice: currentPrice,
-			MarketValue:  marketValue,
-			CostBasis:    costBasis,
-			PnL:          pnl,
-			PnLPercent:   pnlPercent,
+		costBasis = costBasis.RoundCurrency(h.Currency)
+		pnl := marketValue.Sub(costBasis)
+		hq := models.HoldingWithQuote{
+			Holding:         h,
+			CurrentPrice:    currentPrice,
+			MarketValue:     marketValue,
+			CostBasis:       costBasis,
+			PnL:             pnl,
+			PnLPercent:      percentOf(pnl, costBasis),
+			FXRate:          rate,
+			MarketValueBase: marketValue.Mul(fxRate).RoundCurrency(baseCurrency),
+			CostBasisBase:   costBasis.Mul(fxRate).RoundCurrency(baseCurrency),
+			Lots:            lots,
+			FXUnavailable:   !converted,
 		}
-		totalValue += marketValue
-		totalCost += costBasis
+		// The long-term part takes the rounding difference so the split adds up to PnL
+		shortTerm, anyLongTerm := decimal.Zero, false
+		for _, l := range lots {
+			if l.LongTerm {
+				anyLongTerm = true
+			} else {
+				shortTerm = shortTerm.Add(l.Quantity.Mul(currentPrice.Sub(l.CostPerShare)))
+			}
+		}
+		hq.UnrealizedShortTerm = pnl
+		if anyLongTerm {
+			hq.UnrealizedShortTerm = shortTerm.RoundCurrency(h.Currency)
+			hq.UnrealizedLongTerm = pnl.Sub(hq.UnrealizedShortTerm)
+		}
+		summary.Holdings = append(summary.Holdings, hq)
+		summary.UnrealizedShortTerm = summary.UnrealizedShortTerm.Add(hq.UnrealizedShortTerm.Mul(fxRate).RoundCurrency(baseCurrency))
+		summary.UnrealizedLongTerm = summary.UnrealizedLongTerm.Add(hq.UnrealizedLongTerm.Mul(fxRate).RoundCurrency(baseCurrency))
+		totalValue = totalValue.Add(hq.MarketValueBase)
+		totalCost = totalCost.Add(hq.CostBasisBase)
 	}
 
-	returnPct := 0.0
-	if totalCost > 0 {
-		returnPct = ((totalValue - totalCost) / totalCost) * 100
+	summary.HoldingsValue = totalValue
+	summary.TotalValue = summary.TotalValue.Add(totalValue)
+	summary.TotalCost = totalCost
+	summary.TotalPnL = totalValue.Sub(totalCost)
+	summary.ReturnPct = percentOf(summary.TotalPnL, totalCost)
+	summary.Unconverted = gaps.list()
+	return summary, nil
+}
+
+// percentOf returns part as a percentage of whole, or 0 when whole is not positive
+func percentOf(part, whole decimal.Decimal) float64 {
+	if whole.Sign() <= 0 {
+		return 0
 	}
+	return part.Mul(decimal.NewFromInt(100)).Div(whole, decimal.Precision).Float64()
+}
+
+// fxGaps collects currencies that had no exchange rate to the base currency
+type fxGaps map[string]bool
 
-	return &models.PortfolioSummary{
-		Holdings:   withQuotes,
-		TotalValue: totalValue,
-		TotalCost:  totalCost,
-		TotalPnL:   totalValue - totalCost,
-		ReturnPct:  returnPct,
+// list returns the currencies in order, or nil when every amount converted
+func (g fxGaps) list() []string {
+	if len(g) == 0 {
+		return nil
+	}
+	currencies := make([]string, 0, len(g))
+	for c := range g {
+		currencies = append(currencies, c)
+	}
+	sort.Strings(currencies)
+	return currencies
+}
+
+// rate returns the latest currency to baseCurrency rate. When none is available it
+// records currency in gaps and returns 0, so converted amounts drop out of totals.
+func (s *PortfolioService) rate(ctx context.Context, currency, baseCurrency string, gaps fxGaps) (float64, bool) {
+	rate, err := s.fxRate(ctx, currency, baseCurrency)
+	if err != nil {
+		gaps[currency] = true
+		return 0, false
+	}
+	return rate, true
+}
+
+// fxRate is the latest rate from currency (empty means base) to baseCurrency
+func (s *PortfolioService) fxRate(ctx context.Context, currency, baseCurrency string) (float64, error) {
+	if currency == "" || currency == baseCurrency {
+		return 1, nil
+	}
+	rate, err := s.fx.GetRate(ctx, currency, baseCurrency)
+	if err != nil {
+		return 0, err
+	}
+	return rate.Rate, nil
+}
+
+// cashBalances converts per-currency cash to baseCurrency; an empty currency
+// (ledgers that predate currency tracking) is taken as baseCurrency
+func (s *PortfolioService) cashBalances(ctx context.Context, cash map[string]decimal.Decimal, baseCurrency string, gaps fxGaps) ([]models.CashBalance, decimal.Decimal) {
+	balances := []models.CashBalance{}
+	total := decimal.Zero
+	for _, currency := range ledger.Currencies(cash) {
+		amount := cash[currency]
+		if currency == "" {
+			currency = baseCurrency
+		}
+		rate, converted := s.rate(ctx, currency, baseCurrency, gaps)
+		balance := models.CashBalance{
+			Currency:      currency,
+			Amount:        amount.RoundCurrency(currency),
+			FXRate:        rate,
+			AmountBase:    amount.Mul(decimal.NewFromFloat(rate)).RoundCurrency(baseCurrency),
+			FXUnavailable: !converted,
+		}
+		balances = append(balances, balance)
+		total = total.Add(balance.AmountBase)
+	}
+	return balances, total
+}
+
+// GetConsolidated sums every portfolio a user owns in one base currency; each
+// portfolio keeps its own cost basis method
+func (s *PortfolioService) GetConsolidated(ctx context.Context, userID, baseCurrency string) (*models.ConsolidatedSummary, error) {
+	if baseCurrency == "" {
+		baseCurrency = s.baseCurrency
+	}
+	baseCurrency, _ = NormalizeCurrency(baseCurrency)
+	if len(baseCurrency) != 3 {
+		return nil, ErrInvalidCurrency
+	}
+	list, err := s.ListPortfolios(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	result := &models.ConsolidatedSummary{BaseCurrency: baseCurrency, Portfolios: []models.PortfolioSummary{}}
+	gaps := fxGaps{}
+	for _, p := range list {
+		summary, err := s.GetPortfolio(ctx, userID, p.ID, baseCurrency, "")
+		if err != nil {
+			return nil, fmt.Errorf("portfolio %q: %w", p.Name, err)
+		}
+		result.Portfolios = append(result.Portfolios, *summary)
+		result.CashValue = result.CashValue.Add(summary.CashValue)
+		result.TotalValue = result.TotalValue.Add(summary.TotalValue)
+		result.TotalCost = result.TotalCost.Add(summary.TotalCost)
+		result.TotalPnL = result.TotalPnL.Add(summary.TotalPnL)
+		result.RealizedPnL = result.RealizedPnL.Add(summary.RealizedPnL)
+		for _, c := range summary.Unconverted {
+			gaps[c] = true
+		}
+	}
+	result.Unconverted = gaps.list()
+	result.ReturnPct = percentOf(result.TotalPnL, result.TotalCost)
+	return result, nil
+}
+
+// realizedTotals sums realized gains in baseCurrency at current exchange rates;
+// gains in a currency with no rate are recorded in gaps and skipped
+func (s *PortfolioService) realizedTotals(ctx context.Context, gains []models.RealizedGain, baseCurrency string, gaps fxGaps) (shortTerm, longTerm decimal.Decimal) {
+	for _, g := range gains {
+		rate, _ := s.rate(ctx, g.Currency, baseCurrency, gaps)
+		base := g.Gain.Mul(decimal.NewFromFloat(rate)).RoundCurrency(baseCurrency)
+		if g.LongTerm {
+			longTerm = longTerm.Add(base)
+		} else {
+			shortTerm = shortTerm.Add(base)
+		}
+	}
+	return shortTerm, longTerm
+}
+
+// baseAmount converts amount from currency (empty means base) to baseCurrency at the
+// latest rate, rounded to baseCurrency's minor unit
+func (s *PortfolioService) baseAmount(ctx context.Context, amount decimal.Decimal, currency, baseCurrency string) (decimal.Decimal, error) {
+	if amount.IsZero() {
+		return decimal.Zero, nil
+	}
+	rate, err := s.fxRate(ctx, currency, baseCurrency)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return amount.Mul(decimal.NewFromFloat(rate)).RoundCurrency(baseCurrency), nil
+}
+
+// GetRealized lists gains from closed lots with short- and long-term totals in
+// baseCurrency; with wash sales enabled, deferred losses are excluded from the totals
+func (s *PortfolioService) GetRealized(ctx context.Context, userID string, portfolioID int64, baseCurrency, method string) (*models.RealizedReport, error) {
+	p, err := s.portfolio(ctx, userID, portfolioID)
+	if err != nil {
+		return nil, err
+	}
+	baseCurrency, method, err = s.resolve(p, baseCurrency, method)
+	if err != nil {
+		return nil, err
+	}
+	book, err := s.book(ctx, p, method)
+	if err != nil {
+		return nil, err
+	}
+	gaps := fxGaps{}
+	shortTerm, longTerm := s.realizedTotals(ctx, book.Realized, baseCurrency, gaps)
+	disallowed := decimal.Zero
+	for _, g := range book.Realized {
+		rate, _ := s.rate(ctx, g.Currency, baseCurrency, gaps)
+		disallowed = disallowed.Add(g.WashSaleDisallowed.Mul(decimal.NewFromFloat(rate)).RoundCurrency(baseCurrency))
+	}
+	// Gains are listed in their trading currency, rounded to its minor unit
+	gains := make([]models.RealizedGain, len(book.Realized))
+	for i, g := range book.Realized {
+		g.Proceeds = g.Proceeds.RoundCurrency(g.Currency)
+		g.Cost = g.Cost.RoundCurrency(g.Currency)
+		g.Gain = g.Gain.RoundCurrency(g.Currency)
+		g.WashSaleDisallowed = g.WashSaleDisallowed.RoundCurrency(g.Currency)
+		gains[i] = g
+	}
+	return &models.RealizedReport{
+		PortfolioID:        p.ID,
+		CostBasisMethod:    method,
+		BaseCurrency:       baseCurrency,
+		Gains:              gains,
+		ShortTerm:          shortTerm,
+		LongTerm:           longTerm,
+		Total:              shortTerm.Add(longTerm),
+		WashSales:          p.WashSales,
+		WashSaleDisallowed: disallowed,
+		Unconverted:        gaps.list(),
 	}, nil
 }
 
-// AddHolding adds a holding to user's portfolio
-func (s *PortfolioService) AddHolding(ctx context.Context, userID string, symbol string, quantity, buyPrice float64) error {
+// AddHolding records a buy dated today; it is the legacy form of RecordTransaction
+func (s *PortfolioService) AddHolding(ctx context.Context, userID string, portfolioID int64, symbol string, quantity, buyPrice decimal.Decimal, charges models.Charges) error {
 	symbol = strings.ToUpper(strings.TrimSpace(symbol))
-	if symbol == "" || quantity <= 0 || buyPrice <= 0 {
+	if symbol == "" || quantity.Sign() <= 0 || buyPrice.Sign() <= 0 {
 		return ErrInvalidSymbol
 	}
-	if _, err := s.stock.GetQuote(ctx, symbol); err != nil {
+	return s.RecordTransaction(ctx, &models.Transaction{
+		UserID:      userID,
+		PortfolioID: portfolioID,
+		Symbol:      symbol,
+		Type:        models.TxBuy,
+		Quantity:    quantity,
+		Price:       buyPrice,
+		Charges:     charges,
+	})
+}
+
+// RemovePosition deletes every transaction for symbol in a portfolio, closing the
+// position; it returns ErrPositionNotFound when the portfolio has none
+func (s *PortfolioService) RemovePosition(ctx context.Context, userID string, portfolioID int64, symbol string) error {
+	p, err := s.portfolio(ctx, userID, portfolioID)
+	if err != nil {
+		return err
+	}
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	txs, err := s.txs.ListTransactions(ctx, userID, p.ID, symbol)
+	if err != nil {
+		return err
+	}
+	if len(txs) == 0 {
+		return ErrPositionNotFound
+	}
+	if err := s.txs.DeleteTransactionsBySymbol(ctx, userID, p.ID, symbol); err != nil {
+		return err
+	}
+	return s.snapshots.DeleteSnapshots(ctx, p.ID, "")
+}
+
+// RecordTransaction validates and stores a ledger entry. The portfolio defaults to the
+// user's default portfolio, the currency to the quote currency and the trade date to
+// today, and the portfolio's ledger must still replay cleanly (no selling more than
+// was held at the time). A trade naming a fee schedule without an explicit
+// commission is charged the schedule's commission.
+func (s *PortfolioService) RecordTransaction(ctx context.Context, tx *models.Transaction) error {
+	p, err := s.portfolio(ctx, tx.UserID, tx.PortfolioID)
+	if err != nil {
+		return err
+	}
+	if err := s.prepareTransaction(ctx, p, tx); err != nil {
+		return err
+	}
+
+	existing, err := s.txs.ListTransactions(ctx, tx.UserID, tx.PortfolioID, "")
+	if err != nil {
+		return err
+	}
+	if err := checkLedger(p, append(existing, *tx), tx.Symbol); err != nil {
+		return err
+	}
+	if err := s.txs.AddTransaction(ctx, tx); err != nil {
+		return err
+	}
+	// Snapshots from the trade date on no longer match the ledger
+	return s.snapshots.DeleteSnapshots(ctx, p.ID, tx.TradeDate)
+}
+
+// prepareTransaction normalizes and validates a ledger entry for p, applying its fee
+// schedule and defaulting its currency and trade date
+func (s *PortfolioService) prepareTransaction(ctx context.Context, p *models.Portfolio, tx *models.Transaction) error {
+	var err error
+	tx.PortfolioID = p.ID
+	tx.Symbol = strings.ToUpper(strings.TrimSpace(tx.Symbol))
+	tx.Type = strings.ToLower(strings.TrimSpace(tx.Type))
+	tx.Note = strings.TrimSpace(tx.Note)
+	if tx.TradeDate == "" {
+		tx.TradeDate = time.Now().UTC().Format("2006-01-02")
+	}
+	if err := ledger.Validate(*tx); err != nil {
+		return err
+	}
+	switch {
+	case tx.Currency != "":
+	case tx.Symbol != "":
+		quote, err := s.stock.GetQuote(ctx, tx.Symbol)
+		if err != nil {
+			return fmt.Errorf("%w: no quote for %s to take its currency from", ErrInvalidSymbol, tx.Symbol)
+		}
+		tx.Currency = quote.Currency
+	default:
+		if tx.Currency, _, err = s.resolve(p, "", ""); err != nil {
+			return err
+		}
+	}
+	tx.Currency, _ = NormalizeCurrency(tx.Currency)
+	if len(tx.Currency) != 3 {
+		return ErrInvalidCurrency
+	}
+	return s.applyFeeSchedule(ctx, tx)
+}
+
+// applyFeeSchedule fills a trade's commission from its fee schedule, rounded to the
+// trade currency
+func (s *PortfolioService) applyFeeSchedule(ctx context.Context, tx *models.Transaction) error {
+	if tx.FeeScheduleID == 0 {
+		return nil
+	}
+	schedule, err := s.fees.GetFeeSchedule(ctx, tx.UserID, tx.FeeScheduleID)
+	if err != nil {
 		return err
 	}
-	return s.repo.AddHolding(ctx, userID, symbol, quantity, buyPrice)
+	if schedule == nil {
+		return ErrFeeScheduleNotFound
+	}
+	if tx.Commission.IsZero() && (tx.Type == models.TxBuy || tx.Type == models.TxSell) {
+		tx.Commission = ComputeFee(*schedule, tx.Quantity.Mul(tx.Price)).RoundCurrency(tx.Currency)
+	}
+	return nil
+}
+
+// checkLedger verifies a portfolio's ledger still replays after a change to symbol:
+// no disposal exceeds the shares held and, in strict cash mode, no outflow
+// overdraws cash
+func checkLedger(p *models.Portfolio, txs []models.Transaction, symbol string) error {
+	if symbol != "" {
+		var forSymbol []models.Transaction
+		for _, t := range txs {
+			if t.Symbol == symbol {
+				forSymbol = append(forSymbol, t)
+			}
+		}
+		if _, err := ledger.Holdings(forSymbol); err != nil {
+			return err
+		}
+	}
+	if p.StrictCash {
+		return ledger.CheckCash(txs)
+	}
+	return nil
 }
 
-// RemoveHolding removes a holding
-func (s *PortfolioService) RemoveHolding(ctx context.Context, userID string, id int64) error {
-	return s.repo.RemoveHolding(ctx, userID, id)
+// ListTransactions returns a user's ledger, optionally for one portfolio and symbol;
+// portfolioID 0 lists every portfolio
+func (s *PortfolioService) ListTransactions(ctx context.Context, userID string, portfolioID int64, symbol string) ([]models.Transaction, error) {
+	if portfolioID != 0 {
+		if _, err := s.portfolio(ctx, userID, portfolioID); err != nil {
+			return nil, err
+		}
+	}
+	txs, err := s.txs.ListTransactions(ctx, userID, portfolioID, strings.ToUpper(strings.TrimSpace(symbol)))
+	if txs == nil && err == nil {
+		txs = []models.Transaction{}
+	}
+	return txs, err
+}
+
+// DeleteTransaction removes a ledger entry unless later sells depend on it. A non-zero
+// portfolioID must match the transaction's portfolio.
+func (s *PortfolioService) DeleteTransaction(ctx context.Context, userID string, portfolioID, id int64) error {
+	return s.deleteTransaction(ctx, userID, portfolioID, id, false)
+}
+
+// DeleteHolding removes a buy recorded with AddHolding; it is the legacy form of
+// DeleteTransaction
+func (s *PortfolioService) DeleteHolding(ctx context.Context, userID string, portfolioID, id int64) error {
+	return s.deleteTransaction(ctx, userID, portfolioID, id, true)
+}
+
+func (s *PortfolioService) deleteTransaction(ctx context.Context, userID string, portfolioID, id int64, buyOnly bool) error {
+	tx, err := s.txs.GetTransaction(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if tx == nil || (portfolioID != 0 && tx.PortfolioID != portfolioID) || (buyOnly && tx.Type != models.TxBuy) {
+		return ErrTransactionNotFound
+	}
+	p, err := s.portfolio(ctx, userID, tx.PortfolioID)
+	if err != nil {
+		return err
+	}
+	existing, err := s.txs.ListTransactions(ctx, userID, tx.PortfolioID, "")
+	if err != nil {
+		return err
+	}
+	remaining := existing[:0]
+	for _, t := range existing {
+		if t.ID != id {
+			remaining = append(remaining, t)
+		}
+	}
+	if err := checkLedger(p, remaining, tx.Symbol); err != nil {
+		return err
+	}
+	if err := s.txs.DeleteTransaction(ctx, userID, id); err != nil {
+		return err
+	}
+	return s.snapshots.DeleteSnapshots(ctx, p.ID, tx.TradeDate)
 }
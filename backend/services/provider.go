@@ -0,0 +1,21 @@
+package services
+
+import (
+	"context"
+
+	"tinystock/backend/models"
+)
+
+// MarketDataProvider is the upstream source of quotes and price history.
+// YahooFinanceClient is the production implementation; StaticProvider is an in-memory stand-in.
+type MarketDataProvider interface {
+	GetQuoteWithContext(ctx context.Context, symbol string) (*models.Quote, error)
+	GetQuotesWithContext(ctx context.Context, symbols []string) ([]*models.Quote, error)
+	GetHistoryWithContext(ctx context.Context, symbol string, range_ string, interval string) ([]models.HistoryPoint, error)
+	SearchSymbolsWithContext(ctx context.Context, query string, limit int) ([]models.Quote, error)
+	// GetDividendsWithContext returns cash dividends with an ex-date in range_, oldest first
+	GetDividendsWithContext(ctx context.Context, symbol string, range_ string) ([]models.DividendEvent, error)
+}
+
+var _ MarketDataProvider = (*YahooFinanceClient)(nil)
+var _ MarketDataProvider = (*StaticProvider)(nil)
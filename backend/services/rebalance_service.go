@@ -0,0 +1,350 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"tinystock/backend/models"
+	"tinystock/backend/repository"
+)
+
+var (
+	ErrInvalidTarget    = errors.New("invalid target")
+	ErrInvalidRebalance = errors.New("invalid rebalance")
+)
+
+// fractionalStep is the smallest quantity suggested with fractional rounding
+const fractionalStep = 1e-6
+
+// RebalanceService stores target weights and suggests the trades that restore them
+type RebalanceService struct {
+	portfolio *PortfolioService
+	targets   repository.TargetRepository
+	stock     *StockService
+	indices   *IndexService
+}
+
+// NewRebalanceService creates a new RebalanceService
+func NewRebalanceService(portfolio *PortfolioService, targets repository.TargetRepository, stock *StockService, indices *IndexService) *RebalanceService {
+	return &RebalanceService{portfolio: portfolio, targets: targets, stock: stock, indices: indices}
+}
+
+// GetTargets returns a portfolio's targets (0 for the default portfolio)
+func (s *RebalanceService) GetTargets(ctx context.Context, userID string, portfolioID int64) ([]models.Target, error) {
+	p, err := s.portfolio.portfolio(ctx, userID, portfolioID)
+	if err != nil {
+		return nil, err
+	}
+	return s.targets.ListTargets(ctx, p.ID)
+}
+
+// SetTargets replaces a portfolio's targets. Symbols are upper-cased and sectors
+// must be one of the bundled index sectors; weights may not exceed 100 in total.
+func (s *RebalanceService) SetTargets(ctx context.Context, userID string, portfolioID int64, targets []models.Target) ([]models.Target, error) {
+	p, err := s.portfolio.portfolio(ctx, userID, portfolioID)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	total := 0.0
+	for i := range targets {
+		t := &targets[i]
+		t.Kind = strings.ToLower(strings.TrimSpace(t.Kind))
+		switch t.Kind {
+		case models.TargetSymbol:
+			t.Key = strings.ToUpper(strings.TrimSpace(t.Key))
+			if t.Key == "" {
+				return nil, fmt.Errorf("%w: symbol is required", ErrInvalidTarget)
+			}
+		case models.TargetSector:
+			sector, ok := s.indices.NormalizeSector(t.Key)
+			if !ok {
+				return nil, fmt.Errorf("%w: unknown sector %q", ErrInvalidTarget, t.Key)
+			}
+			t.Key = sector
+		default:
+			return nil, fmt.Errorf("%w: kind must be symbol or sector", ErrInvalidTarget)
+		}
+		if seen[t.Kind+":"+t.Key] {
+			return nil, fmt.Errorf("%w: duplicate %s %s", ErrInvalidTarget, t.Kind, t.Key)
+		}
+		seen[t.Kind+":"+t.Key] = true
+		if t.Weight <= 0 || t.Weight > 100 {
+			return nil, fmt.Errorf("%w: weight for %s must be above 0 and at most 100", ErrInvalidTarget, t.Key)
+		}
+		if t.Threshold < 0 || t.Threshold > 100 {
+			return nil, fmt.Errorf("%w: threshold for %s must be between 0 and 100", ErrInvalidTarget, t.Key)
+		}
+		t.PortfolioID = p.ID
+		total += t.Weight
+	}
+	if total > 100+1e-9 {
+		return nil, fmt.Errorf("%w: weights add up to %.2f%%, more than 100%%", ErrInvalidTarget, total)
+	}
+	if err := s.targets.ReplaceTargets(ctx, p.ID, targets); err != nil {
+		return nil, err
+	}
+	return s.targets.ListTargets(ctx, p.ID)
+}
+
+// RebalanceOptions controls how Rebalance turns drift into orders
+type RebalanceOptions struct {
+	Mode     string  // full or cash-only
+	Rounding string  // whole or fractional
+	MinTrade float64 // orders worth less than this in the base currency are dropped
+}
+
+// normalize fills defaults and rejects unknown modes
+func (o *RebalanceOptions) normalize() error {
+	o.Mode = strings.ToLower(strings.TrimSpace(o.Mode))
+	switch o.Mode {
+	case "":
+		o.Mode = models.RebalanceFull
+	case models.RebalanceFull, models.RebalanceCashOnly:
+	default:
+		return fmt.Errorf("%w: mode must be full or cash-only", ErrInvalidRebalance)
+	}
+	o.Rounding = strings.ToLower(strings.TrimSpace(o.Rounding))
+	switch o.Rounding {
+	case "":
+		o.Rounding = models.RoundingWhole
+	case models.RoundingWhole, models.RoundingFractional:
+	default:
+		return fmt.Errorf("%w: rounding must be whole or fractional", ErrInvalidRebalance)
+	}
+	if o.MinTrade < 0 {
+		return fmt.Errorf("%w: minTrade must not be negative", ErrInvalidRebalance)
+	}
+	return nil
+}
+
+// targetGroup is a target with the holdings it covers
+type targetGroup struct {
+	drift    models.TargetDrift
+	value    float64
+	holdings []*models.HoldingWithQuote
+	legs     []rebalanceLeg
+	delta    float64 // base-currency amount to buy (positive) or sell (negative)
+	// unconverted marks a group holding a symbol with no exchange rate; its weight is unknown
+	unconverted bool
+}
+
+// Rebalance compares a portfolio's weights with its targets and suggests orders for
+// every target that drifted past its threshold. A symbol target takes its symbol out
+// of any sector target; holdings covered by no target count as a 0% target. A target
+// holding a symbol with no exchange rate has no known weight, so it gets no orders.
+func (s *RebalanceService) Rebalance(ctx context.Context, userID string, portfolioID int64, opts RebalanceOptions) (*models.Rebalance, error) {
+	if err := opts.normalize(); err != nil {
+		return nil, err
+	}
+	summary, err := s.portfolio.GetPortfolio(ctx, userID, portfolioID, "", "")
+	if err != nil {
+		return nil, err
+	}
+	targets, err := s.targets.ListTargets(ctx, summary.PortfolioID)
+	if err != nil {
+		return nil, err
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("%w: the portfolio has no targets", ErrInvalidRebalance)
+	}
+
+	result := &models.Rebalance{
+		PortfolioID:  summary.PortfolioID,
+		BaseCurrency: summary.BaseCurrency,
+		Mode:         opts.Mode,
+		Rounding:     opts.Rounding,
+		MinTrade:     opts.MinTrade,
+		TotalValue:   summary.TotalValue.Float64(),
+		CashBefore:   summary.CashValue.Float64(),
+		Drift:        []models.TargetDrift{},
+		Orders:       []models.RebalanceOrder{},
+		Unfilled:     []string{},
+		Unconverted:  summary.Unconverted,
+	}
+
+	groups := make([]*targetGroup, 0, len(targets))
+	bySymbol := make(map[string]*targetGroup)
+	bySector := make(map[string]*targetGroup)
+	for _, t := range targets {
+		threshold := t.Threshold
+		if threshold == 0 {
+			threshold = models.DefaultDriftPercent
+		}
+		g := &targetGroup{drift: models.TargetDrift{Kind: t.Kind, Key: t.Key, TargetWeight: t.Weight, Threshold: threshold, Symbols: []string{}}}
+		groups = append(groups, g)
+		if t.Kind == models.TargetSymbol {
+			bySymbol[t.Key] = g
+		} else {
+			bySector[t.Key] = g
+		}
+	}
+	for i := range summary.Holdings {
+		h := &summary.Holdings[i]
+		g := bySymbol[h.Symbol]
+		if g == nil {
+			if sector, ok := s.indices.Sector(h.Symbol); ok {
+				g = bySector[sector]
+			}
+		}
+		if g == nil {
+			g = &targetGroup{drift: models.TargetDrift{Kind: models.TargetSymbol, Key: h.Symbol, Threshold: models.DefaultDriftPercent, Symbols: []string{}}}
+			groups = append(groups, g)
+		}
+		g.drift.Symbols = append(g.drift.Symbols, h.Symbol)
+		if h.FXUnavailable {
+			g.unconverted = true
+			continue
+		}
+		g.value += h.MarketValueBase.Float64()
+		g.holdings = append(g.holdings, h)
+	}
+
+	buys, sells := 0.0, 0.0
+	for _, g := range groups {
+		if result.TotalValue > 0 {
+			g.drift.CurrentWeight = g.value / result.TotalValue * 100
+		}
+		g.drift.Drift = g.drift.CurrentWeight - g.drift.TargetWeight
+		g.drift.Breached = math.Abs(g.drift.Drift) > g.drift.Threshold && !g.unconverted
+		result.Drift = append(result.Drift, g.drift)
+		if !g.drift.Breached {
+			continue
+		}
+		g.delta = g.drift.TargetWeight/100*result.TotalValue - g.value
+		if opts.Mode == models.RebalanceCashOnly && g.delta < 0 {
+			g.delta = 0
+		}
+		if g.delta == 0 {
+			continue
+		}
+		if g.legs, err = s.legs(ctx, g, summary.BaseCurrency); err != nil {
+			return nil, err
+		}
+		if len(g.legs) == 0 {
+			if g.delta > 0 {
+				result.Unfilled = append(result.Unfilled, g.drift.Kind+":"+g.drift.Key)
+			}
+			g.delta = 0
+		}
+		if g.delta > 0 {
+			buys += g.delta
+		} else {
+			sells -= g.delta
+		}
+	}
+	// Buys are scaled down when the cash on hand plus the sells can't fund them
+	if budget := math.Max(result.CashBefore, 0) + sells; buys > budget && buys > 0 {
+		scale := budget / buys
+		for _, g := range groups {
+			if g.delta > 0 {
+				g.delta *= scale
+			}
+		}
+	}
+
+	for _, g := range groups {
+		if g.delta != 0 {
+			result.Orders = append(result.Orders, g.orders(opts)...)
+		}
+	}
+	sort.SliceStable(result.Orders, func(i, j int) bool {
+		// Sells first, since they fund the buys
+		if result.Orders[i].Side != result.Orders[j].Side {
+			return result.Orders[i].Side == models.OrderSell
+		}
+		return result.Orders[i].Value > result.Orders[j].Value
+	})
+	result.CashAfter = result.CashBefore
+	for _, o := range result.Orders {
+		if o.Side == models.OrderBuy {
+			result.CashAfter -= o.Value
+		} else {
+			result.CashAfter += o.Value
+		}
+	}
+	return result, nil
+}
+
+// rebalanceLeg is one symbol a group's trade is split across; rate converts its
+// price to the base currency and share is its part of the group's value
+type rebalanceLeg struct {
+	symbol, currency string
+	price, rate      float64
+	held, share      float64
+}
+
+// legs lists the symbols that can carry a group's trade: its holdings, or for a symbol
+// target not yet held, the symbol at its quote. A sector with no holdings has none.
+func (s *RebalanceService) legs(ctx context.Context, g *targetGroup, baseCurrency string) ([]rebalanceLeg, error) {
+	var legs []rebalanceLeg
+	for _, h := range g.holdings {
+		if h.CurrentPrice.Sign() <= 0 || h.MarketValue.Sign() <= 0 || h.FXRate <= 0 {
+			continue
+		}
+		share := 1.0
+		if g.value > 0 {
+			share = h.MarketValueBase.Float64() / g.value
+		}
+		legs = append(legs, rebalanceLeg{h.Symbol, h.Currency, h.CurrentPrice.Float64(), h.FXRate, h.Quantity.Float64(), share})
+	}
+	if len(legs) > 0 || g.drift.Kind != models.TargetSymbol || g.delta < 0 {
+		return legs, nil
+	}
+	q, err := s.stock.GetQuote(ctx, g.drift.Key)
+	if err != nil {
+		return nil, fmt.Errorf("quote %s: %w", g.drift.Key, err)
+	}
+	if q.Price <= 0 {
+		return nil, nil
+	}
+	currency := q.Currency
+	if currency == "" {
+		currency = baseCurrency
+	}
+	// rate carries minor units such as GBp through to the base currency
+	iso, factor := NormalizeCurrency(currency)
+	rate, err := s.portfolio.fxRate(ctx, iso, baseCurrency)
+	if err != nil {
+		return nil, fmt.Errorf("convert %s: %w", g.drift.Key, err)
+	}
+	return []rebalanceLeg{{g.drift.Key, currency, q.Price, rate * factor, 0, 1}}, nil
+}
+
+// orders splits the group's delta across its legs, rounding quantities down so buys
+// never overspend and sells never exceed the shares held
+func (g *targetGroup) orders(opts RebalanceOptions) []models.RebalanceOrder {
+	side := models.OrderBuy
+	if g.delta < 0 {
+		side = models.OrderSell
+	}
+	var orders []models.RebalanceOrder
+	for _, l := range g.legs {
+		quantity := math.Abs(g.delta) * l.share / (l.price * l.rate)
+		if side == models.OrderSell {
+			quantity = math.Min(quantity, l.held)
+		}
+		if opts.Rounding == models.RoundingWhole {
+			quantity = math.Floor(quantity + 1e-9)
+		} else {
+			quantity = math.Floor(quantity/fractionalStep+1e-6) * fractionalStep
+		}
+		value := quantity * l.price * l.rate
+		if quantity <= 0 || math.IsInf(quantity, 0) || math.IsNaN(value) || value < opts.MinTrade {
+			continue
+		}
+		orders = append(orders, models.RebalanceOrder{
+			Symbol:   l.symbol,
+			Side:     side,
+			Quantity: quantity,
+			Price:    l.price,
+			Currency: l.currency,
+			Value:    value,
+		})
+	}
+	return orders
+}
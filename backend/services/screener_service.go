@@ -0,0 +1,310 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"tinystock/backend/indicators"
+	"tinystock/backend/models"
+	"tinystock/backend/repository"
+	"tinystock/backend/screener"
+)
+
+const (
+	defaultScreenPageSize = 25
+	maxScreenPageSize     = 100
+	// screenerWorkers bounds concurrent history fetches for indicator fields
+	screenerWorkers = 8
+)
+
+var (
+	ErrInvalidFilter  = errors.New("invalid filter")
+	ErrScreenNotFound = errors.New("screen not found")
+)
+
+// FilterError wraps a parse or validation problem with a user-facing message
+type FilterError struct {
+	Err error
+}
+
+func (e *FilterError) Error() string { return e.Err.Error() }
+func (e *FilterError) Unwrap() error { return ErrInvalidFilter }
+
+// ScreenerService evaluates filter expressions over a symbol universe
+type ScreenerService struct {
+	repo       repository.ScreenRepository
+	stock      *StockService
+	indicators *IndicatorService
+	indices    *IndexService
+	universe   *UniverseService
+}
+
+// NewScreenerService creates a new ScreenerService
+func NewScreenerService(repo repository.ScreenRepository, stock *StockService, ind *IndicatorService, indices *IndexService, universe *UniverseService) *ScreenerService {
+	return &ScreenerService{repo: repo, stock: stock, indicators: ind, indices: indices, universe: universe}
+}
+
+// ParseFilter parses and validates a filter expression
+func ParseFilter(filter string) (screener.Expr, error) {
+	expr, err := screener.Parse(filter)
+	if err != nil {
+		return nil, &FilterError{Err: err}
+	}
+	if err := screener.Validate(expr); err != nil {
+		return nil, &FilterError{Err: err}
+	}
+	return expr, nil
+}
+
+// Run evaluates an ad-hoc or saved screen and returns one page of matches
+func (s *ScreenerService) Run(ctx context.Context, userID string, req models.ScreenRequest) (*models.ScreenPage, error) {
+	if req.ScreenID != 0 {
+		screen, err := s.repo.GetScreen(ctx, userID, req.ScreenID)
+		if err != nil {
+			return nil, err
+		}
+		if screen == nil {
+			return nil, ErrScreenNotFound
+		}
+		req.Filter = screen.Filter
+		if req.Universe == "" {
+			req.Universe = screen.Universe
+		}
+	}
+	if req.Universe == "" {
+		req.Universe = UniverseWatched
+	}
+	req.Universe = strings.ToLower(req.Universe)
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = defaultScreenPageSize
+	} else if req.PageSize > maxScreenPageSize {
+		req.PageSize = maxScreenPageSize
+	}
+
+	expr, err := ParseFilter(req.Filter)
+	if err != nil {
+		return nil, err
+	}
+	fields := screener.Fields(expr)
+	if req.SortBy != "" {
+		if !screener.KnownField(req.SortBy) {
+			return nil, &FilterError{Err: fmt.Errorf("unknown sort field %q", req.SortBy)}
+		}
+		fields = append(fields, req.SortBy)
+	}
+	var specs []indicators.Spec
+	for _, f := range fields {
+		if spec, ok := screener.IndicatorSpec(f); ok {
+			specs = append(specs, spec)
+		}
+	}
+
+	symbols, err := s.universe.Symbols(ctx, req.Universe)
+	if err != nil {
+		return nil, err
+	}
+	quotes, err := s.stock.GetQuotesBatched(ctx, symbols, quoteBatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	matches, err := s.evaluate(ctx, expr, fields, specs, quotes)
+	if err != nil {
+		return nil, err
+	}
+	sortMatches(matches, req.SortBy, req.Order)
+
+	page := &models.ScreenPage{
+		Filter:   req.Filter,
+		Universe: req.Universe,
+		Scanned:  len(quotes),
+		Total:    len(matches),
+		Page:     req.Page,
+		PageSize: req.PageSize,
+		Results:  []models.ScreenMatch{},
+	}
+	start := (req.Page - 1) * req.PageSize
+	if start < len(matches) {
+		end := start + req.PageSize
+		if end > len(matches) {
+			end = len(matches)
+		}
+		page.Results = matches[start:end]
+	}
+	return page, nil
+}
+
+// evaluate runs expr for every quote with bounded concurrency, preserving universe order
+func (s *ScreenerService) evaluate(ctx context.Context, expr screener.Expr, fields []string, specs []indicators.Spec, quotes []*models.Quote) ([]models.ScreenMatch, error) {
+	type outcome struct {
+		match *models.ScreenMatch
+		err   error
+	}
+	outcomes := make([]outcome, len(quotes))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < screenerWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				env := &screenEnv{ctx: ctx, svc: s, quote: quotes[i], specs: specs}
+				ok, err := screener.Eval(expr, env)
+				if err != nil {
+					outcomes[i].err = &FilterError{Err: err}
+					continue
+				}
+				if !ok {
+					continue
+				}
+				values := make(map[string]interface{}, len(fields))
+				for _, f := range fields {
+					if v, err := env.Lookup(f); err == nil {
+						values[f] = v.Interface()
+					}
+				}
+				outcomes[i].match = &models.ScreenMatch{Symbol: quotes[i].Symbol, Quote: quotes[i], Values: values}
+			}
+		}()
+	}
+	for i := range quotes {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	matches := []models.ScreenMatch{}
+	for _, o := range outcomes {
+		if o.err != nil {
+			return nil, o.err
+		}
+		if o.match != nil {
+			matches = append(matches, *o.match)
+		}
+	}
+	return matches, nil
+}
+
+// sortMatches orders matches by a referenced field; symbols without the field sort last
+func sortMatches(matches []models.ScreenMatch, sortBy, order string) {
+	if sortBy == "" {
+		return
+	}
+	desc := strings.EqualFold(order, "desc")
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, aOK := matches[i].Values[sortBy]
+		b, bOK := matches[j].Values[sortBy]
+		if !aOK || !bOK {
+			return aOK && !bOK
+		}
+		var less bool
+		switch av := a.(type) {
+		case float64:
+			bv, _ := b.(float64)
+			less = av < bv
+		case string:
+			bv, _ := b.(string)
+			less = strings.ToLower(av) < strings.ToLower(bv)
+		default:
+			return false
+		}
+		if desc {
+			return !less && a != b
+		}
+		return less
+	})
+}
+
+// screenEnv resolves fields for one symbol, loading indicator history only on first use
+type screenEnv struct {
+	ctx   context.Context
+	svc   *ScreenerService
+	quote *models.Quote
+	specs []indicators.Spec
+
+	loaded bool
+	latest map[string]indicators.Point
+}
+
+// Lookup implements screener.Env
+func (e *screenEnv) Lookup(field string) (screener.Value, error) {
+	if v, ok, err := screener.QuoteField(e.quote, field); ok {
+		return v, err
+	}
+	if strings.EqualFold(field, screener.FieldSector) {
+		sector, ok := e.svc.indices.Sector(e.quote.Symbol)
+		if !ok {
+			return screener.Value{}, screener.ErrNoValue
+		}
+		return screener.String(sector), nil
+	}
+	spec, ok := screener.IndicatorSpec(field)
+	if !ok {
+		return screener.Value{}, fmt.Errorf("unknown field %q", field)
+	}
+	if !e.loaded {
+		e.loaded = true
+		e.latest = e.svc.latestIndicators(e.ctx, e.quote.Symbol, e.specs)
+	}
+	p, ok := e.latest[spec.String()]
+	if !ok {
+		return screener.Value{}, screener.ErrNoValue
+	}
+	return screener.Number(p.Values[spec.Kind]), nil
+}
+
+// latestIndicators computes every indicator the filter references from a single history fetch
+func (s *ScreenerService) latestIndicators(ctx context.Context, symbol string, specs []indicators.Spec) map[string]indicators.Point {
+	range_ := "1y"
+	for _, spec := range specs {
+		// A year of daily bars cannot warm up periods much beyond 200
+		if len(spec.Params) > 0 && spec.Params[0] > 200 {
+			range_ = "2y"
+		}
+	}
+	latest, err := s.indicators.Latest(ctx, symbol, specs, range_, "1d")
+	if err != nil {
+		return nil
+	}
+	return latest
+}
+
+// CreateScreen saves a named filter for a user after validating it
+func (s *ScreenerService) CreateScreen(ctx context.Context, screen *models.Screen) error {
+	screen.Name = strings.TrimSpace(screen.Name)
+	if screen.Name == "" {
+		return &FilterError{Err: errors.New("name is required")}
+	}
+	if _, err := ParseFilter(screen.Filter); err != nil {
+		return err
+	}
+	if screen.Universe == "" {
+		screen.Universe = UniverseWatched
+	}
+	screen.Universe = strings.ToLower(screen.Universe)
+	if _, err := s.universe.Symbols(ctx, screen.Universe); err != nil {
+		return err
+	}
+	return s.repo.CreateScreen(ctx, screen)
+}
+
+// ListScreens returns a user's saved screens
+func (s *ScreenerService) ListScreens(ctx context.Context, userID string) ([]models.Screen, error) {
+	screens, err := s.repo.ListScreens(ctx, userID)
+	if screens == nil && err == nil {
+		screens = []models.Screen{}
+	}
+	return screens, err
+}
+
+// DeleteScreen removes a saved screen
+func (s *ScreenerService) DeleteScreen(ctx context.Context, userID string, id int64) error {
+	return s.repo.DeleteScreen(ctx, userID, id)
+}
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"tinystock/backend/ledger"
+	"tinystock/backend/models"
+	"tinystock/backend/repository"
+)
+
+// SnapshotService persists each portfolio's end-of-day valuation so the equity
+// curve can be served without replaying the ledger against history every time
+type SnapshotService struct {
+	portfolio *PortfolioService
+	snapshots repository.SnapshotRepository
+}
+
+// NewSnapshotService creates a new SnapshotService
+func NewSnapshotService(portfolio *PortfolioService, snapshots repository.SnapshotRepository) *SnapshotService {
+	return &SnapshotService{portfolio: portfolio, snapshots: snapshots}
+}
+
+// Start catches every portfolio up now and then daily at hour:00 UTC, until ctx is
+// cancelled. A negative hour disables the job.
+func (s *SnapshotService) Start(ctx context.Context, hour int) {
+	if hour < 0 {
+		return
+	}
+	go func() {
+		s.RunAll(ctx)
+		for {
+			now := time.Now().UTC()
+			next := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, time.UTC)
+			if !next.After(now) {
+				next = next.AddDate(0, 0, 1)
+			}
+			timer := time.NewTimer(next.Sub(now))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+				s.RunAll(ctx)
+			}
+		}
+	}()
+}
+
+// RunAll snapshots every portfolio through today, filling any gaps since its last
+// snapshot. Failures are logged so one bad portfolio doesn't stop the rest.
+func (s *SnapshotService) RunAll(ctx context.Context) {
+	portfolios, err := s.portfolio.portfolios.ListAllPortfolios(ctx)
+	if err != nil {
+		log.Printf("snapshots: list portfolios: %v", err)
+		return
+	}
+	for i := range portfolios {
+		if _, err := s.catchUp(ctx, &portfolios[i]); err != nil {
+			log.Printf("snapshots: portfolio %d: %v", portfolios[i].ID, err)
+		}
+	}
+}
+
+// catchUp re-values p from its latest snapshot (which may have been taken before
+// that day's close) through today. Snapshots in another currency, e.g. after the
+// server default changed, are rebuilt from inception.
+func (s *SnapshotService) catchUp(ctx context.Context, p *models.Portfolio) (int, error) {
+	baseCurrency, _, err := s.portfolio.resolve(p, "", "")
+	if err != nil {
+		return 0, err
+	}
+	latest, err := s.snapshots.LatestSnapshot(ctx, p.ID)
+	if err != nil {
+		return 0, err
+	}
+	from := ""
+	if latest != nil {
+		if latest.BaseCurrency == baseCurrency {
+			from = latest.Date
+		} else if err := s.snapshots.DeleteSnapshots(ctx, p.ID, ""); err != nil {
+			return 0, err
+		}
+	}
+	return s.capture(ctx, p, from)
+}
+
+// capture values p on every trading day from from (empty for inception) through
+// today and saves the snapshots
+func (s *SnapshotService) capture(ctx context.Context, p *models.Portfolio, from string) (int, error) {
+	baseCurrency, method, err := s.portfolio.resolve(p, "", "")
+	if err != nil {
+		return 0, err
+	}
+	txs, err := s.portfolio.txs.ListTransactions(ctx, p.UserID, p.ID, "")
+	if err != nil {
+		return 0, err
+	}
+	today := time.Now().UTC().Format("2006-01-02")
+	if len(txs) == 0 {
+		return 0, nil
+	}
+	ledger.Sort(txs)
+	if inception := txs[0].TradeDate; from < inception {
+		from = inception
+	}
+	if from > today {
+		return 0, nil
+	}
+
+	v, err := s.portfolio.value(ctx, txs, baseCurrency, ledger.Options{Method: method, WashSales: p.WashSales}, from, today)
+	if err != nil {
+		return 0, err
+	}
+	// Point 0 is the close before from, which is either already stored or precedes inception
+	snapshots := make([]models.PortfolioSnapshot, 0, v.Len()-1)
+	for i := 1; i < v.Len(); i++ {
+		snapshots = append(snapshots, models.PortfolioSnapshot{
+			PortfolioID:  p.ID,
+			Date:         v.Dates[i],
+			BaseCurrency: baseCurrency,
+			TotalValue:   v.Values[i],
+			TotalCost:    v.Cost[i],
+			Cash:         v.Cash[i],
+			PnL:          v.Values[i] - v.Cash[i] - v.Cost[i],
+			NetFlow:      v.Flows[i],
+		})
+	}
+	if err := s.snapshots.SaveSnapshots(ctx, snapshots); err != nil {
+		return 0, err
+	}
+	return len(snapshots), nil
+}
+
+// Backfill rebuilds a portfolio's snapshots from the ledger, from from
+// (YYYY-MM-DD, empty for inception) onwards, and returns how many were written
+func (s *SnapshotService) Backfill(ctx context.Context, userID string, portfolioID int64, from string) (int, error) {
+	p, err := s.portfolio.portfolio(ctx, userID, portfolioID)
+	if err != nil {
+		return 0, err
+	}
+	if from != "" {
+		if _, err := time.Parse("2006-01-02", from); err != nil {
+			return 0, fmt.Errorf("%w: from must be YYYY-MM-DD", ErrInvalidPeriod)
+		}
+	}
+	if err := s.snapshots.DeleteSnapshots(ctx, p.ID, from); err != nil {
+		return 0, err
+	}
+	// Resume from the last snapshot kept, if any, so the curve stays contiguous
+	return s.catchUp(ctx, p)
+}
+
+// History returns a portfolio's daily snapshots over range_ (1m, 3m, 6m, ytd, 1y,
+// 3y, 5y or all), first bringing them up to date
+func (s *SnapshotService) History(ctx context.Context, userID string, portfolioID int64, range_ string) (*models.PortfolioHistory, error) {
+	p, err := s.portfolio.portfolio(ctx, userID, portfolioID)
+	if err != nil {
+		return nil, err
+	}
+	baseCurrency, _, err := s.portfolio.resolve(p, "", "")
+	if err != nil {
+		return nil, err
+	}
+	if range_ == "" {
+		range_ = "1y"
+	}
+	from, err := periodStart(range_, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	latest, err := s.snapshots.LatestSnapshot(ctx, p.ID)
+	if err != nil {
+		return nil, err
+	}
+	if latest == nil || latest.Date < time.Now().UTC().Format("2006-01-02") || latest.BaseCurrency != baseCurrency {
+		if _, err := s.catchUp(ctx, p); err != nil {
+			return nil, err
+		}
+	}
+
+	snapshots, err := s.snapshots.ListSnapshots(ctx, p.ID, from)
+	if err != nil {
+		return nil, err
+	}
+	return &models.PortfolioHistory{PortfolioID: p.ID, BaseCurrency: baseCurrency, Range: range_, Snapshots: snapshots}, nil
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"tinystock/backend/models"
+)
+
+// StaticProvider serves fixed quotes and history from memory.
+// It stands in for Yahoo Finance in tests and offline development.
+type StaticProvider struct {
+	mu        sync.RWMutex
+	quotes    map[string]models.Quote
+	history   map[string][]models.HistoryPoint
+	dividends map[string][]models.DividendEvent
+}
+
+// NewStaticProvider creates an empty StaticProvider
+func NewStaticProvider() *StaticProvider {
+	return &StaticProvider{
+		quotes:    make(map[string]models.Quote),
+		history:   make(map[string][]models.HistoryPoint),
+		dividends: make(map[string][]models.DividendEvent),
+	}
+}
+
+// SetQuote stores the quote returned for q.Symbol
+func (p *StaticProvider) SetQuote(q models.Quote) {
+	p.mu.Lock()
+	p.quotes[strings.ToUpper(q.Symbol)] = q
+	p.mu.Unlock()
+}
+
+// SetHistory stores the history returned for symbol regardless of range and interval
+func (p *StaticProvider) SetHistory(symbol string, points []models.HistoryPoint) {
+	p.mu.Lock()
+	p.history[strings.ToUpper(symbol)] = points
+	p.mu.Unlock()
+}
+
+// SetDividends stores the dividends returned for symbol regardless of range
+func (p *StaticProvider) SetDividends(symbol string, events []models.DividendEvent) {
+	p.mu.Lock()
+	p.dividends[strings.ToUpper(symbol)] = events
+	p.mu.Unlock()
+}
+
+// GetQuoteWithContext implements MarketDataProvider
+func (p *StaticProvider) GetQuoteWithContext(ctx context.Context, symbol string) (*models.Quote, error) {
+	p.mu.RLock()
+	q, ok := p.quotes[strings.ToUpper(symbol)]
+	p.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("symbol not found: %s", symbol)
+	}
+	return &q, nil
+}
+
+// GetQuotesWithContext implements MarketDataProvider; unknown symbols are skipped
+func (p *StaticProvider) GetQuotesWithContext(ctx context.Context, symbols []string) ([]*models.Quote, error) {
+	quotes := make([]*models.Quote, 0, len(symbols))
+	for _, s := range symbols {
+		if q, err := p.GetQuoteWithContext(ctx, s); err == nil {
+			quotes = append(quotes, q)
+		}
+	}
+	return quotes, nil
+}
+
+// GetHistoryWithContext implements MarketDataProvider
+func (p *StaticProvider) GetHistoryWithContext(ctx context.Context, symbol string, range_ string, interval string) ([]models.HistoryPoint, error) {
+	p.mu.RLock()
+	points, ok := p.history[strings.ToUpper(symbol)]
+	p.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("no history for symbol: %s", symbol)
+	}
+	out := make([]models.HistoryPoint, len(points))
+	copy(out, points)
+	return out, nil
+}
+
+// GetDividendsWithContext implements MarketDataProvider; a symbol without dividends has none
+func (p *StaticProvider) GetDividendsWithContext(ctx context.Context, symbol string, range_ string) ([]models.DividendEvent, error) {
+	p.mu.RLock()
+	events := p.dividends[strings.ToUpper(symbol)]
+	p.mu.RUnlock()
+	out := make([]models.DividendEvent, len(events))
+	copy(out, events)
+	return out, nil
+}
+
+// SearchSymbolsWithContext implements MarketDataProvider with a case-insensitive substring match
+func (p *StaticProvider) SearchSymbolsWithContext(ctx context.Context, query string, limit int) ([]models.Quote, error) {
+	query = strings.ToUpper(query)
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	symbols := make([]string, 0, len(p.quotes))
+	for sym := range p.quotes {
+		symbols = append(symbols, sym)
+	}
+	sort.Strings(symbols)
+
+	var results []models.Quote
+	for _, sym := range symbols {
+		if len(results) >= limit {
+			break
+		}
+		q := p.quotes[sym]
+		if strings.Contains(sym, query) || strings.Contains(strings.ToUpper(q.Name), query) {
+			results = append(results, q)
+		}
+	}
+	return results, nil
+}
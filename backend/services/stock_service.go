@@ -2,24 +2,35 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
+	"tinystock/backend/analytics"
 	"tinystock/backend/models"
 )
 
+// closedMarketQuoteTTL is how long quotes are cached while their market is closed
+const closedMarketQuoteTTL = 10 * time.Minute
+
 // StockService provides stock data with caching and context timeout
 type StockService struct {
-	yahoo *YahooFinanceClient
-	cache *MemoryCache
+	provider MarketDataProvider
+	cache    *MemoryCache
 }
 
-// NewStockService creates a new StockService
+// NewStockService creates a new StockService backed by Yahoo Finance
 func NewStockService() *StockService {
+	return NewStockServiceWithProvider(NewYahooFinanceClient())
+}
+
+// NewStockServiceWithProvider creates a StockService over any market data provider
+func NewStockServiceWithProvider(provider MarketDataProvider) *StockService {
 	return &StockService{
-		yahoo: NewYahooFinanceClient(),
-		cache: NewMemoryCache(2 * time.Minute), // 2 min cache for quotes
+		provider: provider,
+		cache:    NewMemoryCache(2 * time.Minute), // 2 min cache for quotes
 	}
 }
 
@@ -39,11 +50,12 @@ func (s *StockService) GetQuote(ctx context.Context, symbol string) (*models.Quo
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
-	quote, err := s.yahoo.GetQuoteWithContext(ctx, symbol)
+	quote, err := s.provider.GetQuoteWithContext(ctx, symbol)
 	if err != nil {
 		return nil, err
 	}
-	s.cache.Set(cacheKey, quote)
+	s.decorateQuote(ctx, quote)
+	s.cacheQuote(quote)
 	return quote, nil
 }
 
@@ -68,7 +80,7 @@ func (s *StockService) GetHistory(ctx context.Context, symbol string, range_, in
 	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
 	defer cancel()
 
-	history, err := s.yahoo.GetHistoryWithContext(ctx, symbol, range_, interval)
+	history, err := s.provider.GetHistoryWithContext(ctx, symbol, range_, interval)
 	if err != nil {
 		return nil, err
 	}
@@ -76,6 +88,57 @@ func (s *StockService) GetHistory(ctx context.Context, symbol string, range_, in
 	return history, nil
 }
 
+// GetDividends fetches a symbol's cash dividends over range_ (default 5y) with cache
+func (s *StockService) GetDividends(ctx context.Context, symbol, range_ string) ([]models.DividendEvent, error) {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	if symbol == "" {
+		return nil, fmt.Errorf("symbol is required")
+	}
+	if range_ == "" {
+		range_ = "5y"
+	}
+
+	cacheKey := fmt.Sprintf("dividends:%s:%s", symbol, range_)
+	if v, ok := s.cache.Get(cacheKey); ok {
+		return v.([]models.DividendEvent), nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	events, err := s.provider.GetDividendsWithContext(ctx, symbol, range_)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.Set(cacheKey, events)
+	return events, nil
+}
+
+// GetCloses loads daily closes for symbols concurrently. Symbols that fail are
+// left out of the map and their errors joined into err.
+func (s *StockService) GetCloses(ctx context.Context, symbols []string, range_ string) (map[string]analytics.Series, error) {
+	series := make(map[string]analytics.Series, len(symbols))
+	errs := make([]error, len(symbols))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i, sym := range symbols {
+		wg.Add(1)
+		go func(i int, sym string) {
+			defer wg.Done()
+			history, err := s.GetHistory(ctx, sym, range_, "1d")
+			if err != nil {
+				errs[i] = fmt.Errorf("%s: %w", sym, err)
+				return
+			}
+			mu.Lock()
+			series[sym] = analytics.FromHistory(history)
+			mu.Unlock()
+		}(i, sym)
+	}
+	wg.Wait()
+	return series, errors.Join(errs...)
+}
+
 // GetQuotes fetches multiple quotes (for watchlist/portfolio)
 func (s *StockService) GetQuotes(ctx context.Context, symbols []string) ([]*models.Quote, error) {
 	if len(symbols) == 0 {
@@ -96,12 +159,13 @@ func (s *StockService) GetQuotes(ctx context.Context, symbols []string) ([]*mode
 	if len(toFetch) > 0 {
 		ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
 		defer cancel()
-		quotes, err := s.yahoo.GetQuotesWithContext(ctx, toFetch)
+		quotes, err := s.provider.GetQuotesWithContext(ctx, toFetch)
 		if err != nil {
 			return nil, err
 		}
 		for _, q := range quotes {
-			s.cache.Set("quote:"+q.Symbol, q)
+			s.decorateQuote(ctx, q)
+			s.cacheQuote(q)
 			quoteMap[q.Symbol] = q
 		}
 	}
@@ -116,6 +180,39 @@ func (s *StockService) GetQuotes(ctx context.Context, symbols []string) ([]*mode
 	return result, nil
 }
 
+// quoteBatchSize caps how many symbols are requested from the provider at once
+const quoteBatchSize = 50
+
+// GetQuotesBatched fetches quotes for large symbol lists in provider-sized batches.
+// Batches that fail are skipped so one bad symbol does not hide the rest.
+func (s *StockService) GetQuotesBatched(ctx context.Context, symbols []string, batchSize int) ([]*models.Quote, error) {
+	if batchSize <= 0 {
+		batchSize = quoteBatchSize
+	}
+	var (
+		result   []*models.Quote
+		firstErr error
+	)
+	for start := 0; start < len(symbols); start += batchSize {
+		end := start + batchSize
+		if end > len(symbols) {
+			end = len(symbols)
+		}
+		quotes, err := s.GetQuotes(ctx, symbols[start:end])
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		result = append(result, quotes...)
+	}
+	if len(result) == 0 && firstErr != nil {
+		return nil, firstErr
+	}
+	return result, nil
+}
+
 // SearchSymbols searches for stock symbols
 func (s *StockService) SearchSymbols(ctx context.Context, query string, limit int) ([]models.Quote, error) {
 	query = strings.TrimSpace(query)
@@ -129,5 +226,51 @@ func (s *StockService) SearchSymbols(ctx context.Context, query string, limit in
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
-	return s.yahoo.SearchSymbolsWithContext(ctx, query, limit)
+	return s.provider.SearchSymbolsWithContext(ctx, query, limit)
+}
+
+// cacheQuote caches a quote, keeping it longer while its market is closed
+func (s *StockService) cacheQuote(q *models.Quote) {
+	if q.MarketOpen {
+		s.cache.Set("quote:"+q.Symbol, q)
+		return
+	}
+	s.cache.SetWithTTL("quote:"+q.Symbol, q, closedMarketQuoteTTL)
+}
+
+// decorateQuote fills asset type, market state and change semantics.
+// Crypto trades 24/7, so its change is measured over a rolling 24 hours instead of since a session open.
+func (s *StockService) decorateQuote(ctx context.Context, q *models.Quote) {
+	if q.AssetType == "" && IsCrypto(q.Symbol, "") {
+		q.AssetType = models.AssetCrypto
+	}
+	q.MarketOpen = IsMarketOpen(q.Symbol, q.AssetType, time.Now())
+	q.ChangeWindow = models.ChangeWindowSession
+	if !IsCrypto(q.Symbol, q.AssetType) {
+		return
+	}
+	if ref, ok := s.priceAt(ctx, q.Symbol, time.Now().Add(-24*time.Hour)); ok && ref > 0 {
+		q.Change = q.Price - ref
+		q.ChangePct = (q.Change / ref) * 100
+		q.ChangeWindow = models.ChangeWindow24h
+	}
+}
+
+// priceAt returns the last hourly close at or before t
+func (s *StockService) priceAt(ctx context.Context, symbol string, t time.Time) (float64, bool) {
+	history, err := s.GetHistory(ctx, symbol, "5d", "1h")
+	if err != nil {
+		return 0, false
+	}
+	cutoff := t.Unix()
+	price, found := 0.0, false
+	for _, p := range history {
+		if p.Time > cutoff {
+			break
+		}
+		if p.Close > 0 {
+			price, found = p.Close, true
+		}
+	}
+	return price, found
 }
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"tinystock/backend/internal/decimal"
+	"tinystock/backend/ledger"
+	"tinystock/backend/models"
+)
+
+var ErrInvalidTaxReport = errors.New("invalid tax report")
+
+// TaxService reports realized capital gains per fiscal year under a jurisdiction's rules
+type TaxService struct {
+	portfolio *PortfolioService
+	fx        *FXService
+}
+
+// NewTaxService creates a new TaxService
+func NewTaxService(portfolio *PortfolioService, fx *FXService) *TaxService {
+	return &TaxService{portfolio: portfolio, fx: fx}
+}
+
+// Report lists the gains a portfolio realized in a fiscal year, split into short and
+// long term by the jurisdiction's holding period. An empty jurisdiction is IN for
+// INR portfolios and US otherwise; year 0 is the current fiscal year. Lots are
+// matched with method, else the jurisdiction's required method, else the
+// portfolio's. Losses deferred by wash sales are reported as adjustments.
+func (s *TaxService) Report(ctx context.Context, userID string, portfolioID int64, jurisdiction string, year int, method string) (*models.TaxReport, error) {
+	p, err := s.portfolio.portfolio(ctx, userID, portfolioID)
+	if err != nil {
+		return nil, err
+	}
+	base, _, err := s.portfolio.resolve(p, "", "")
+	if err != nil {
+		return nil, err
+	}
+	if jurisdiction == "" {
+		jurisdiction = "US"
+		if base == "INR" {
+			jurisdiction = "IN"
+		}
+	}
+	j, ok := ledger.LookupJurisdiction(jurisdiction)
+	if !ok {
+		return nil, fmt.Errorf("%w: jurisdiction must be US or IN", ErrInvalidTaxReport)
+	}
+	if method == "" {
+		method = j.Method
+	}
+	if _, method, err = s.portfolio.resolve(p, "", method); err != nil {
+		return nil, err
+	}
+	current := j.FiscalYear(time.Now().UTC())
+	if year == 0 {
+		year = current
+	}
+	if year < 1900 || year > current {
+		return nil, fmt.Errorf("%w: year must be between 1900 and %d", ErrInvalidTaxReport, current)
+	}
+	from, to := j.FiscalYearRange(year)
+
+	// Wash sales are a US rule, so other jurisdictions report the losses in full
+	rules := *p
+	rules.WashSales = p.WashSales && j.WashSales
+	book, err := s.portfolio.book(ctx, &rules, method)
+	if err != nil {
+		return nil, err
+	}
+	report := &models.TaxReport{
+		PortfolioID:      p.ID,
+		PortfolioName:    p.Name,
+		Jurisdiction:     j.Code,
+		JurisdictionName: j.Name,
+		FiscalYear:       year,
+		FiscalYearLabel:  j.FiscalYearLabel(year),
+		From:             from,
+		To:               to,
+		Currency:         j.Currency,
+		CostBasisMethod:  method,
+		LongTermMonths:   j.LongTermMonths,
+		WashSales:        rules.WashSales,
+		Lots:             []models.TaxLot{},
+	}
+	for _, g := range book.Realized {
+		if g.CloseDate < from || g.CloseDate > to {
+			continue
+		}
+		cost, err := s.convertOn(ctx, g.Cost, g.Currency, j.Currency, g.OpenDate)
+		if err != nil {
+			return nil, fmt.Errorf("convert %s cost: %w", g.Symbol, err)
+		}
+		proceeds, err := s.convertOn(ctx, g.Proceeds, g.Currency, j.Currency, g.CloseDate)
+		if err != nil {
+			return nil, fmt.Errorf("convert %s proceeds: %w", g.Symbol, err)
+		}
+		adjustment, err := s.convertOn(ctx, g.WashSaleDisallowed, g.Currency, j.Currency, g.CloseDate)
+		if err != nil {
+			return nil, fmt.Errorf("convert %s adjustment: %w", g.Symbol, err)
+		}
+		lot := models.TaxLot{
+			Symbol:      g.Symbol,
+			Quantity:    g.Quantity,
+			OpenDate:    g.OpenDate,
+			CloseDate:   g.CloseDate,
+			HoldingDays: int(daysBetween(g.OpenDate, g.CloseDate)),
+			LongTerm:    j.IsLongTerm(g.OpenDate, g.CloseDate),
+			Currency:    g.Currency,
+			Proceeds:    proceeds,
+			Cost:        cost,
+			Adjustment:  adjustment,
+			Gain:        proceeds.Sub(cost).Add(adjustment),
+		}
+		if !adjustment.IsZero() {
+			lot.Code = "W"
+		}
+		report.Lots = append(report.Lots, lot)
+		if lot.LongTerm {
+			addTaxLot(&report.LongTerm, lot)
+		} else {
+			addTaxLot(&report.ShortTerm, lot)
+		}
+		addTaxLot(&report.Total, lot)
+	}
+	return report, nil
+}
+
+// convertOn converts amount at the rate on date (YYYY-MM-DD), rounded to the
+// minor unit of to
+func (s *TaxService) convertOn(ctx context.Context, amount decimal.Decimal, from, to, date string) (decimal.Decimal, error) {
+	if from == "" || from == to || amount.IsZero() {
+		return amount.RoundCurrency(to), nil
+	}
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	rate, err := s.fx.GetRateOn(ctx, from, to, day)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return amount.Mul(decimal.NewFromFloat(rate.Rate)).RoundCurrency(to), nil
+}
+
+func addTaxLot(t *models.TaxTotals, lot models.TaxLot) {
+	t.Proceeds = t.Proceeds.Add(lot.Proceeds)
+	t.Cost = t.Cost.Add(lot.Cost)
+	t.Adjustments = t.Adjustments.Add(lot.Adjustment)
+	if lot.Gain.Sign() >= 0 {
+		t.Gains = t.Gains.Add(lot.Gain)
+	} else {
+		t.Losses = t.Losses.Add(lot.Gain)
+	}
+	t.Net = t.Net.Add(lot.Gain)
+}
@@ -0,0 +1,271 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"tinystock/backend/internal/decimal"
+	"tinystock/backend/ledger"
+	"tinystock/backend/models"
+)
+
+// TransactionPatch lists the fields of a ledger entry to change; nil fields keep their
+// value. Version is required and must match the stored version.
+type TransactionPatch struct {
+	Symbol        *string          `json:"symbol"`
+	Type          *string          `json:"type"`
+	Quantity      *decimal.Decimal `json:"quantity"`
+	Price         *decimal.Decimal `json:"price"`
+	Amount        *decimal.Decimal `json:"amount"`
+	Currency      *string          `json:"currency"`
+	TradeDate     *string          `json:"tradeDate"`
+	Note          *string          `json:"note"`
+	Fee           *decimal.Decimal `json:"fee"`
+	Commission    *decimal.Decimal `json:"commission"`
+	Tax           *decimal.Decimal `json:"tax"`
+	FeeScheduleID *int64           `json:"feeScheduleId"`
+	Version       int64            `json:"version"`
+}
+
+// validate checks each field present in the patch on its own
+func (p TransactionPatch) validate() error {
+	for _, f := range []struct {
+		name  string
+		value *decimal.Decimal
+	}{{"quantity", p.Quantity}, {"price", p.Price}, {"amount", p.Amount}, {"fee", p.Fee}, {"commission", p.Commission}, {"tax", p.Tax}} {
+		if f.value == nil {
+			continue
+		}
+		if f.value.Sign() < 0 {
+			return fmt.Errorf("%w: %s must be a non-negative number", ledger.ErrInvalidTransaction, f.name)
+		}
+	}
+	if p.Type != nil && *p.Type == "" {
+		return fmt.Errorf("%w: type must not be empty", ledger.ErrInvalidTransaction)
+	}
+	if p.TradeDate != nil {
+		if _, err := time.Parse("2006-01-02", *p.TradeDate); err != nil {
+			return fmt.Errorf("%w: tradeDate must be YYYY-MM-DD", ledger.ErrInvalidTransaction)
+		}
+	}
+	if p.Currency != nil {
+		if c, _ := NormalizeCurrency(*p.Currency); len(c) != 3 {
+			return fmt.Errorf("%w: currency must be a 3-letter code", ledger.ErrInvalidTransaction)
+		}
+	}
+	if p.FeeScheduleID != nil && *p.FeeScheduleID < 0 {
+		return fmt.Errorf("%w: feeScheduleId must not be negative", ledger.ErrInvalidTransaction)
+	}
+	if p.Version < 0 {
+		return fmt.Errorf("%w: version must not be negative", ledger.ErrInvalidTransaction)
+	}
+	return nil
+}
+
+// apply copies the patch's fields onto t
+func (p TransactionPatch) apply(t *models.Transaction) {
+	if p.Symbol != nil {
+		t.Symbol = *p.Symbol
+		if p.Currency == nil {
+			// A new symbol may trade in another currency; look it up again
+			t.Currency = ""
+		}
+	}
+	if p.Type != nil {
+		t.Type = *p.Type
+	}
+	if p.Quantity != nil {
+		t.Quantity = *p.Quantity
+	}
+	if p.Price != nil {
+		t.Price = *p.Price
+	}
+	if p.Amount != nil {
+		t.Amount = *p.Amount
+	}
+	if p.Currency != nil {
+		t.Currency = *p.Currency
+	}
+	if p.TradeDate != nil {
+		t.TradeDate = *p.TradeDate
+	}
+	if p.Note != nil {
+		t.Note = *p.Note
+	}
+	if p.Fee != nil {
+		t.Fee = *p.Fee
+	}
+	if p.Commission != nil {
+		t.Commission = *p.Commission
+	}
+	if p.Tax != nil {
+		t.Tax = *p.Tax
+	}
+	if p.FeeScheduleID != nil {
+		t.FeeScheduleID = *p.FeeScheduleID
+	}
+	if p.Commission == nil && t.FeeScheduleID != 0 &&
+		(p.Type != nil || p.Quantity != nil || p.Price != nil || p.FeeScheduleID != nil) {
+		// The scheduled commission depends on the trade value
+		t.Commission = decimal.Zero
+	}
+}
+
+// UpdateTransaction edits a ledger entry in place, keeping its ID and creation time.
+// The result is validated like a new entry and the portfolio's ledger must still
+// replay. Each edit bumps the version and is recorded with the fields it changed; a
+// patch that changes nothing returns the entry as is. A non-zero portfolioID must
+// match the transaction's portfolio.
+func (s *PortfolioService) UpdateTransaction(ctx context.Context, userID string, portfolioID, id int64, patch TransactionPatch) (*models.Transaction, error) {
+	return s.updateTransaction(ctx, userID, portfolioID, id, patch, false)
+}
+
+// UpdateHolding edits a buy recorded with AddHolding; it cannot change the type
+func (s *PortfolioService) UpdateHolding(ctx context.Context, userID string, portfolioID, id int64, patch TransactionPatch) (*models.Transaction, error) {
+	if patch.Type != nil || patch.Amount != nil {
+		return nil, fmt.Errorf("%w: a holding's type and amount cannot be changed", ledger.ErrInvalidTransaction)
+	}
+	return s.updateTransaction(ctx, userID, portfolioID, id, patch, true)
+}
+
+func (s *PortfolioService) updateTransaction(ctx context.Context, userID string, portfolioID, id int64, patch TransactionPatch, buyOnly bool) (*models.Transaction, error) {
+	if err := patch.validate(); err != nil {
+		return nil, err
+	}
+	// Without the version the client read, an edit could silently overwrite another
+	if patch.Version == 0 {
+		return nil, ErrVersionRequired
+	}
+	current, err := s.txs.GetTransaction(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil || (portfolioID != 0 && current.PortfolioID != portfolioID) || (buyOnly && current.Type != models.TxBuy) {
+		return nil, ErrTransactionNotFound
+	}
+	if patch.Version != current.Version {
+		return nil, fmt.Errorf("%w: version is %d, not %d", ErrVersionConflict, current.Version, patch.Version)
+	}
+	p, err := s.portfolio(ctx, userID, current.PortfolioID)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := *current
+	patch.apply(&updated)
+	// A fee schedule deleted since the trade was recorded leaves its commission behind
+	if patch.FeeScheduleID == nil && updated.FeeScheduleID != 0 {
+		schedule, err := s.fees.GetFeeSchedule(ctx, userID, updated.FeeScheduleID)
+		if err != nil {
+			return nil, err
+		}
+		if schedule == nil {
+			updated.FeeScheduleID = 0
+			if patch.Commission == nil {
+				updated.Commission = current.Commission
+			}
+		}
+	}
+	if err := s.prepareTransaction(ctx, p, &updated); err != nil {
+		return nil, err
+	}
+	changes := diffTransaction(*current, updated)
+	if len(changes) == 0 {
+		return current, nil
+	}
+
+	existing, err := s.txs.ListTransactions(ctx, userID, p.ID, "")
+	if err != nil {
+		return nil, err
+	}
+	for i := range existing {
+		if existing[i].ID == id {
+			existing[i] = updated
+		}
+	}
+	if err := checkLedger(p, existing, updated.Symbol); err != nil {
+		return nil, err
+	}
+	if current.Symbol != updated.Symbol {
+		if err := checkLedger(p, existing, current.Symbol); err != nil {
+			return nil, err
+		}
+	}
+	update := s.txs.UpdateTransaction
+	if buyOnly {
+		update = s.portfolios.UpdateHolding
+	}
+	ok, err := update(ctx, &updated, &models.TransactionChange{Changes: changes})
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrVersionConflict
+	}
+	// Snapshots from the earlier of the old and new trade dates no longer match
+	from := current.TradeDate
+	if updated.TradeDate < from {
+		from = updated.TradeDate
+	}
+	if err := s.snapshots.DeleteSnapshots(ctx, p.ID, from); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// diffTransaction lists the editable fields that differ between before and after
+func diffTransaction(before, after models.Transaction) []models.FieldChange {
+	var changes []models.FieldChange
+	add := func(field string, from, to interface{}) {
+		if from != to {
+			changes = append(changes, models.FieldChange{Field: field, From: from, To: to})
+		}
+	}
+	// Decimals hold pointers, so they are compared by value rather than with !=
+	addNumber := func(field string, from, to decimal.Decimal) {
+		if from.Cmp(to) != 0 {
+			changes = append(changes, models.FieldChange{Field: field, From: from, To: to})
+		}
+	}
+	add("symbol", before.Symbol, after.Symbol)
+	add("type", before.Type, after.Type)
+	addNumber("quantity", before.Quantity, after.Quantity)
+	addNumber("price", before.Price, after.Price)
+	addNumber("amount", before.Amount, after.Amount)
+	add("currency", before.Currency, after.Currency)
+	add("tradeDate", before.TradeDate, after.TradeDate)
+	add("note", before.Note, after.Note)
+	addNumber("fee", before.Fee, after.Fee)
+	addNumber("commission", before.Commission, after.Commission)
+	addNumber("tax", before.Tax, after.Tax)
+	add("feeScheduleId", before.FeeScheduleID, after.FeeScheduleID)
+	return changes
+}
+
+// TransactionHistory returns the audited edits of a ledger entry, oldest first. A
+// non-zero portfolioID must match the transaction's portfolio.
+func (s *PortfolioService) TransactionHistory(ctx context.Context, userID string, portfolioID, id int64) ([]models.TransactionChange, error) {
+	tx, err := s.GetTransaction(ctx, userID, portfolioID, id)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := s.txs.ListTransactionChanges(ctx, userID, tx.ID)
+	if changes == nil && err == nil {
+		changes = []models.TransactionChange{}
+	}
+	return changes, err
+}
+
+// GetTransaction returns one ledger entry. A non-zero portfolioID must match the
+// transaction's portfolio.
+func (s *PortfolioService) GetTransaction(ctx context.Context, userID string, portfolioID, id int64) (*models.Transaction, error) {
+	tx, err := s.txs.GetTransaction(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if tx == nil || (portfolioID != 0 && tx.PortfolioID != portfolioID) {
+		return nil, ErrTransactionNotFound
+	}
+	return tx, nil
+}
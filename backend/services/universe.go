@@ -0,0 +1,57 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"tinystock/backend/repository"
+)
+
+// UniverseWatched names the universe of every symbol users watch or hold
+const UniverseWatched = "watched"
+
+var ErrInvalidUniverse = errors.New("invalid universe")
+
+// UniverseService resolves named symbol universes: "watched" (every symbol in any
+// watchlist or portfolio plus configured extras) or a benchmark id such as "sp500"
+type UniverseService struct {
+	repo    repository.SymbolRepository
+	indices *IndexService
+	extra   []string
+}
+
+// NewUniverseService creates a UniverseService; extra symbols are added to the watched universe
+func NewUniverseService(repo repository.SymbolRepository, indices *IndexService, extra []string) *UniverseService {
+	return &UniverseService{repo: repo, indices: indices, extra: extra}
+}
+
+// Symbols returns the de-duplicated, upper-cased symbols of a universe
+func (u *UniverseService) Symbols(ctx context.Context, universe string) ([]string, error) {
+	var symbols []string
+	if universe == "" || strings.EqualFold(universe, UniverseWatched) {
+		tracked, err := u.repo.ListTrackedSymbols(ctx)
+		if err != nil {
+			return nil, err
+		}
+		symbols = append(tracked, u.extra...)
+	} else {
+		indexSymbols, err := u.indices.Symbols(universe)
+		if err != nil {
+			return nil, ErrInvalidUniverse
+		}
+		symbols = indexSymbols
+	}
+
+	seen := make(map[string]bool, len(symbols))
+	unique := symbols[:0:0]
+	for _, sym := range symbols {
+		sym = strings.ToUpper(strings.TrimSpace(sym))
+		if sym == "" || seen[sym] {
+			continue
+		}
+		seen[sym] = true
+		unique = append(unique, sym)
+	}
+	return unique, nil
+}
@@ -0,0 +1,211 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"tinystock/backend/analytics"
+	"tinystock/backend/ledger"
+	"tinystock/backend/models"
+)
+
+// valuation is a portfolio's end-of-day value in a base currency. Point 0 is the
+// close before the window opens (zero when the ledger starts inside the window);
+// Flows[i] is the external money that came in (+) or went out (-) on Dates[i].
+type valuation struct {
+	Dates  []string
+	Values []float64 // holdings plus cash
+	Cash   []float64
+	Cost   []float64 // cost basis of open lots
+	Flows  []float64
+}
+
+// Len returns the number of points including the opening one
+func (v *valuation) Len() int { return len(v.Dates) }
+
+// valuer prices a ledger on past dates from daily closes and FX history
+type valuer struct {
+	base      string
+	prices    map[string]analytics.Series
+	fx        map[string]analytics.Series // per non-base currency, rate into base
+	lastTrade map[string]float64          // fallback when a symbol has no close yet
+}
+
+// price returns symbol's close on date in its trading currency
+func (v *valuer) price(symbol, date string) float64 {
+	if p, ok := v.prices[symbol].ValueOn(date); ok {
+		return p
+	}
+	return v.lastTrade[symbol]
+}
+
+// rate converts one unit of currency into the base currency on date; an empty
+// currency (ledgers that predate currency tracking) is taken as base. value only
+// builds a valuer once every ledger currency has a non-empty series.
+func (v *valuer) rate(currency, date string) float64 {
+	code, factor := NormalizeCurrency(currency)
+	if code == "" || code == v.base {
+		return factor
+	}
+	series := v.fx[currency]
+	if r, ok := series.ValueOn(date); ok {
+		return r
+	}
+	if series.Len() > 0 {
+		return series.Values[0]
+	}
+	return 0
+}
+
+// value is p's worth in the base currency at the close on date
+func (v *valuer) value(p *ledger.Position, date string) (total, cash float64) {
+	for symbol, qty := range p.Shares {
+		total += qty.Float64() * v.price(symbol, date) * v.rate(p.Currency[symbol], date)
+	}
+	for currency, amount := range p.Cash {
+		cash += amount.Float64() * v.rate(currency, date)
+	}
+	return total + cash, cash
+}
+
+// flow converts an external flow into the base currency, valuing in-kind
+// transfers at the day's close
+func (v *valuer) flow(f ledger.Flow) float64 {
+	amount := f.Amount.Float64()
+	if f.Symbol != "" {
+		amount = f.Quantity.Float64() * v.price(f.Symbol, f.Date)
+	}
+	return amount * v.rate(f.Currency, f.Date)
+}
+
+// cost is the cost basis of a book's open lots in the base currency on date
+func (v *valuer) cost(b *ledger.Book, date string) float64 {
+	total := 0.0
+	for _, lots := range b.Lots {
+		for _, l := range lots {
+			total += l.Quantity.Mul(l.CostPerShare).Float64() * v.rate(l.Currency, date)
+		}
+	}
+	return total
+}
+
+// value replays txs and values the portfolio at each trading day's close from
+// start to end (inclusive, YYYY-MM-DD) in baseCurrency, with lots matched per opts
+func (s *PortfolioService) value(ctx context.Context, txs []models.Transaction, baseCurrency string, opts ledger.Options, start, end string) (*valuation, error) {
+	ordered := make([]models.Transaction, len(txs))
+	copy(ordered, txs)
+	ledger.Sort(ordered)
+
+	startDay, err := time.Parse("2006-01-02", start)
+	if err != nil {
+		return nil, err
+	}
+	opening := startDay.AddDate(0, 0, -1).Format("2006-01-02")
+	range_ := historyRangeFor(opening)
+
+	v := &valuer{base: baseCurrency, fx: make(map[string]analytics.Series), lastTrade: make(map[string]float64)}
+	currencies := make(map[string]bool)
+	symbolSet := make(map[string]bool)
+	for _, t := range ordered {
+		if t.TradeDate > end {
+			break
+		}
+		if t.Currency != "" {
+			currencies[t.Currency] = true
+		}
+		if t.Symbol != "" {
+			symbolSet[strings.ToUpper(t.Symbol)] = true
+		}
+	}
+	symbols := make([]string, 0, len(symbolSet))
+	for sym := range symbolSet {
+		symbols = append(symbols, sym)
+	}
+	sort.Strings(symbols)
+
+	// A symbol without history (e.g. delisted) is valued at its last trade price
+	v.prices, _ = s.stock.GetCloses(ctx, symbols, range_)
+	for currency := range currencies {
+		if code, _ := NormalizeCurrency(currency); code == baseCurrency {
+			continue
+		}
+		// Valuing the currency at 0 would show a false loss, so the curve is not drawn
+		series, err := s.fx.RateSeries(ctx, currency, baseCurrency, range_)
+		if errors.Is(err, ErrInvalidCurrency) {
+			return nil, err
+		}
+		if err != nil || series.Len() == 0 {
+			return nil, fmt.Errorf("%w: no %s to %s history", ErrFXUnavailable, currency, baseCurrency)
+		}
+		v.fx[currency] = series
+	}
+
+	// The date axis is every trading day of any held symbol plus every trade date
+	dateSet := make(map[string]bool)
+	for _, series := range v.prices {
+		for _, d := range series.Since(start).Dates {
+			if d <= end {
+				dateSet[d] = true
+			}
+		}
+	}
+	for _, t := range ordered {
+		if t.TradeDate >= start && t.TradeDate <= end {
+			dateSet[t.TradeDate] = true
+		}
+	}
+	if len(dateSet) == 0 {
+		dateSet[end] = true
+	}
+	dates := make([]string, 0, len(dateSet)+1)
+	for d := range dateSet {
+		dates = append(dates, d)
+	}
+	sort.Strings(dates)
+
+	p := ledger.NewPosition()
+	book := &ledger.Book{}
+	i := 0
+	apply := func(through string) []ledger.Flow {
+		var flows []ledger.Flow
+		applied := i
+		for ; i < len(ordered) && ordered[i].TradeDate <= through; i++ {
+			t := ordered[i]
+			if t.Symbol != "" && t.Price.Sign() > 0 && (t.Type == models.TxBuy || t.Type == models.TxSell) {
+				v.lastTrade[strings.ToUpper(t.Symbol)] = t.Price.Float64()
+			}
+			flows = append(flows, p.Apply(t)...)
+		}
+		if i > applied {
+			// Lots depend on the whole history, so replay only when it grows
+			if b, err := ledger.ReplayWith(ordered[:i], opts); err == nil {
+				book = b
+			}
+		}
+		return flows
+	}
+
+	result := &valuation{}
+	add := func(date string, flow float64) {
+		value, cash := v.value(p, date)
+		result.Dates = append(result.Dates, date)
+		result.Values = append(result.Values, value)
+		result.Cash = append(result.Cash, cash)
+		result.Cost = append(result.Cost, v.cost(book, date))
+		result.Flows = append(result.Flows, flow)
+	}
+	apply(opening)
+	add(opening, 0)
+	for _, d := range dates {
+		flow := 0.0
+		for _, f := range apply(d) {
+			flow += v.flow(f)
+		}
+		add(d, flow)
+	}
+	return result, nil
+}
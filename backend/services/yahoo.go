@@ -7,7 +7,9 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"tinystock/backend/models"
@@ -57,6 +59,12 @@ type yahooQuoteResponse struct {
 			RegularMarketDayHigh float64 `json:"regularMarketDayHigh"`
 			RegularMarketDayLow  float64 `json:"regularMarketDayLow"`
 			RegularMarketVolume  int64   `json:"regularMarketVolume"`
+			Currency             string  `json:"currency"`
+			QuoteType            string  `json:"quoteType"`
+			FullExchangeName     string  `json:"fullExchangeName"`
+			MarketCap            int64   `json:"marketCap"`
+			TrailingPE           float64 `json:"trailingPE"`
+			DividendYield        float64 `json:"dividendYield"`
 		} `json:"result"`
 	} `json:"quoteResponse"`
 }
@@ -75,6 +83,10 @@ type yahooChartResponse struct {
 				RegularMarketVolume        int64   `json:"regularMarketVolume"`
 				RegularMarketPreviousClose float64 `json:"regularMarketPreviousClose"`
 				ChartPreviousClose         float64 `json:"chartPreviousClose"`
+				Currency                   string  `json:"currency"`
+				InstrumentType             string  `json:"instrumentType"`
+				FullExchangeName           string  `json:"fullExchangeName"`
+				ExchangeName               string  `json:"exchangeName"`
 			} `json:"meta"`
 			Indicators struct {
 				Quote []struct {
@@ -86,6 +98,12 @@ type yahooChartResponse struct {
 				} `json:"quote"`
 			} `json:"indicators"`
 			Timestamp []int64 `json:"timestamp"`
+			Events    struct {
+				Dividends map[string]struct {
+					Amount float64 `json:"amount"`
+					Date   int64   `json:"date"`
+				} `json:"dividends"`
+			} `json:"events"`
 		} `json:"result"`
 	} `json:"chart"`
 }
@@ -125,14 +143,20 @@ func (c *YahooFinanceClient) GetQuoteWithContext(ctx context.Context, symbol str
 	}
 
 	return &models.Quote{
-		Symbol:    r.Symbol,
-		Name:      r.ShortName,
-		Price:     r.RegularMarketPrice,
-		Change:    r.RegularMarketChange,
-		ChangePct: changePct,
-		Volume:    r.RegularMarketVolume,
-		High:      r.RegularMarketDayHigh,
-		Low:       r.RegularMarketDayLow,
+		Symbol:        r.Symbol,
+		Name:          r.ShortName,
+		Price:         r.RegularMarketPrice,
+		Change:        r.RegularMarketChange,
+		ChangePct:     changePct,
+		Volume:        r.RegularMarketVolume,
+		High:          r.RegularMarketDayHigh,
+		Low:           r.RegularMarketDayLow,
+		Currency:      r.Currency,
+		AssetType:     r.QuoteType,
+		Exchange:      r.FullExchangeName,
+		MarketCap:     r.MarketCap,
+		PE:            r.TrailingPE,
+		DividendYield: r.DividendYield,
 	}, nil
 }
 
@@ -206,6 +230,11 @@ func (c *YahooFinanceClient) getQuoteFromChartWithContext(ctx context.Context, s
 		changePct = (change / open) * 100
 	}
 
+	exchange := meta.FullExchangeName
+	if exchange == "" {
+		exchange = meta.ExchangeName
+	}
+
 	return &models.Quote{
 		Symbol:    meta.Symbol,
 		Name:      name,
@@ -215,6 +244,9 @@ func (c *YahooFinanceClient) getQuoteFromChartWithContext(ctx context.Context, s
 		Volume:    volume,
 		High:      high,
 		Low:       low,
+		Currency:  meta.Currency,
+		AssetType: meta.InstrumentType,
+		Exchange:  exchange,
 	}, nil
 }
 
@@ -255,6 +287,9 @@ func (c *YahooFinanceClient) GetHistoryWithContext(ctx context.Context, symbol s
 	}
 
 	closes := quotes[0].Close
+	opens := quotes[0].Open
+	highs := quotes[0].High
+	lows := quotes[0].Low
 	volumes := quotes[0].Volume
 	timestamps := result.Timestamp
 
@@ -269,6 +304,10 @@ func (c *YahooFinanceClient) GetHistoryWithContext(ctx context.Context, symbol s
 		}
 		points = append(points, models.HistoryPoint{
 			Date:   time.Unix(ts, 0).Format("2006-01-02"),
+			Time:   ts,
+			Open:   valueAt(opens, i),
+			High:   valueAt(highs, i),
+			Low:    valueAt(lows, i),
 			Close:  closes[i],
 			Volume: vol,
 		})
@@ -277,6 +316,56 @@ func (c *YahooFinanceClient) GetHistoryWithContext(ctx context.Context, symbol s
 	return points, nil
 }
 
+// GetDividendsWithContext fetches cash dividends from the chart endpoint's div events.
+// A monthly interval keeps the payload small; events are reported regardless.
+func (c *YahooFinanceClient) GetDividendsWithContext(ctx context.Context, symbol string, range_ string) ([]models.DividendEvent, error) {
+	u := fmt.Sprintf("https://query1.finance.yahoo.com/v8/finance/chart/%s?range=%s&interval=1mo&events=div",
+		url.PathEscape(symbol), url.QueryEscape(range_))
+
+	resp, err := c.doRequestWithContext(ctx, u)
+	if err != nil {
+		return nil, fmt.Errorf("fetch dividends: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+
+	var data yahooChartResponse
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, fmt.Errorf("parse response: %w", err)
+	}
+	if len(data.Chart.Result) == 0 {
+		return nil, fmt.Errorf("no dividends for symbol: %s", symbol)
+	}
+
+	result := data.Chart.Result[0]
+	events := make([]models.DividendEvent, 0, len(result.Events.Dividends))
+	for _, d := range result.Events.Dividends {
+		if d.Amount <= 0 {
+			continue
+		}
+		events = append(events, models.DividendEvent{
+			Symbol:   strings.ToUpper(symbol),
+			ExDate:   time.Unix(d.Date, 0).UTC().Format("2006-01-02"),
+			Amount:   d.Amount,
+			Currency: result.Meta.Currency,
+		})
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].ExDate < events[j].ExDate })
+	return events, nil
+}
+
+// valueAt returns values[i], or 0 when the series is shorter than the timestamps
+func valueAt(values []float64, i int) float64 {
+	if i < len(values) {
+		return values[i]
+	}
+	return 0
+}
+
 // GetQuotes fetches quotes for multiple symbols
 func (c *YahooFinanceClient) GetQuotes(symbols []string) ([]*models.Quote, error) {
 	return c.GetQuotesWithContext(context.Background(), symbols)
@@ -312,14 +401,20 @@ func (c *YahooFinanceClient) GetQuotesWithContext(ctx context.Context, symbols [
 						changePct = ((r.RegularMarketPrice - r.RegularMarketOpen) / r.RegularMarketOpen) * 100
 					}
 					quotes = append(quotes, &models.Quote{
-						Symbol:    r.Symbol,
-						Name:      r.ShortName,
-						Price:     r.RegularMarketPrice,
-						Change:    r.RegularMarketChange,
-						ChangePct: changePct,
-						Volume:    r.RegularMarketVolume,
-						High:      r.RegularMarketDayHigh,
-						Low:       r.RegularMarketDayLow,
+						Symbol:        r.Symbol,
+						Name:          r.ShortName,
+						Price:         r.RegularMarketPrice,
+						Change:        r.RegularMarketChange,
+						ChangePct:     changePct,
+						Volume:        r.RegularMarketVolume,
+						High:          r.RegularMarketDayHigh,
+						Low:           r.RegularMarketDayLow,
+						Currency:      r.Currency,
+						AssetType:     r.QuoteType,
+						Exchange:      r.FullExchangeName,
+						MarketCap:     r.MarketCap,
+						PE:            r.TrailingPE,
+						DividendYield: r.DividendYield,
 					})
 				}
 				return quotes, nil
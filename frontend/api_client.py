@@ -179,12 +179,25 @@ def get_portfolio(token: str) -> dict[str, Any]:
         return {"holdings": [], "totalValue": 0, "totalCost": 0, "totalPnL": 0, "returnPct": 0}
 
 
-def add_holding(token: str, symbol: str, quantity: float, buy_price: float) -> tuple[bool, str]:
-    """Add holding to portfolio."""
+def get_allocation(token: str, by: str = "sector") -> dict[str, Any] | None:
+    """Get portfolio value grouped by sector, assetType, currency, exchange or symbol."""
+    try:
+        r = requests.get(_url("/api/portfolio/allocation"), params={"by": by}, headers=_headers(token), timeout=15)
+        r.raise_for_status()
+        data = _get_data(r)
+        return data if isinstance(data, dict) else None
+    except requests.RequestException:
+        return None
+
+
+def add_holding(
+    token: str, symbol: str, quantity: float, buy_price: float, commission: float = 0.0, tax: float = 0.0
+) -> tuple[bool, str]:
+    """Add holding to portfolio; commission and tax are added to its cost basis."""
     try:
         r = requests.post(
             _url("/api/portfolio"),
-            json={"symbol": symbol, "quantity": quantity, "buyPrice": buy_price},
+            json={"symbol": symbol, "quantity": quantity, "buyPrice": buy_price, "commission": commission, "tax": tax},
             headers=_headers(token),
             timeout=10,
         )
@@ -195,11 +208,11 @@ def add_holding(token: str, symbol: str, quantity: float, buy_price: float) -> t
         return False, str(e)
 
 
-def remove_holding(token: str, holding_id: int) -> tuple[bool, str]:
-    """Remove holding from portfolio."""
+def remove_holding(token: str, symbol: str) -> tuple[bool, str]:
+    """Remove a holding (all of its transactions) from portfolio."""
     try:
         r = requests.delete(
-            _url(f"/api/portfolio/{holding_id}"),
+            _url(f"/api/portfolio/positions/{symbol}"),
             headers=_headers(token),
             timeout=10,
         )
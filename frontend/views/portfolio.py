@@ -2,7 +2,15 @@
 
 import streamlit as st
 
-from api_client import get_portfolio, add_holding, remove_holding
+from api_client import get_portfolio, get_allocation, add_holding, remove_holding
+
+ALLOCATION_GROUPS = {
+    "Sector": "sector",
+    "Asset type": "assetType",
+    "Currency": "currency",
+    "Exchange": "exchange",
+    "Symbol": "symbol",
+}
 
 
 def render(token: str):
@@ -14,13 +22,18 @@ def render(token: str):
         with col1:
             symbol = st.text_input("Symbol", placeholder="e.g. AAPL", key="portfolio_symbol")
         with col2:
-            quantity = st.number_input("Quantity", min_value=0.01, value=1.0, step=0.01, key="portfolio_qty")
+            quantity = st.number_input("Quantity", min_value=0.00000001, value=1.0, step=0.01, format="%.8f", key="portfolio_qty")
         with col3:
             buy_price = st.number_input("Buy Price ($)", min_value=0.01, value=100.0, step=0.01, key="portfolio_price")
+        col4, col5 = st.columns(2)
+        with col4:
+            commission = st.number_input("Commission", min_value=0.0, value=0.0, step=0.01, key="portfolio_commission")
+        with col5:
+            tax = st.number_input("Taxes (stamp duty etc.)", min_value=0.0, value=0.0, step=0.01, key="portfolio_tax")
 
         if st.button("Add Holding"):
             if symbol:
-                success, msg = add_holding(token, symbol.strip().upper(), quantity, buy_price)
+                success, msg = add_holding(token, symbol.strip().upper(), quantity, buy_price, commission, tax)
                 if success:
                     st.success(msg)
                     st.rerun()
@@ -48,9 +61,23 @@ def render(token: str):
         with col3:
             st.metric("Total P&L", f"${total_pnl:,.2f}", delta=f"{pnl_pct:+.2f}%")
 
+        st.subheader("Allocation")
+        group = st.selectbox("Group by", list(ALLOCATION_GROUPS), key="allocation_by")
+        allocation = get_allocation(token, ALLOCATION_GROUPS[group])
+        buckets = (allocation or {}).get("buckets", [])
+        if buckets:
+            import pandas as pd
+            import plotly.express as px
+
+            df = pd.DataFrame(buckets)
+            fig = px.pie(df, names="key", values="value", hole=0.4)
+            fig.update_layout(height=400, margin=dict(l=0, r=0))
+            st.plotly_chart(fig, use_container_width=True)
+        else:
+            st.info("Allocation unavailable")
+
         st.subheader("Holdings")
         for h in holdings:
-            hid = h.get("id")
             symbol = h.get("symbol", "")
             quantity = h.get("quantity", 0)
             buy_price = h.get("buyPrice", 0)
@@ -73,8 +100,8 @@ def render(token: str):
                 with col5:
                     st.write(f"${pnl:,.2f} ({pnl_pct:+.2f}%)")
                 with col6:
-                    if st.button("Remove", key=f"rm_holding_{hid}"):
-                        remove_holding(token, hid)
+                    if st.button("Remove", key=f"rm_holding_{symbol}"):
+                        remove_holding(token, symbol)
                         st.rerun()
                 st.divider()
     else: